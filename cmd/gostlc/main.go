@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -9,15 +10,28 @@ import (
 	"os"
 	"strings"
 
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/builtin"
+	"github.com/shota3506/gostlc/internal/diagnostics"
 	"github.com/shota3506/gostlc/internal/eval"
+	"github.com/shota3506/gostlc/internal/lsp"
 	"github.com/shota3506/gostlc/internal/parser"
+	"github.com/shota3506/gostlc/internal/printer"
 	"github.com/shota3506/gostlc/internal/types"
 	"github.com/shota3506/gostlc/internal/values"
 )
 
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
 var (
-	command = flag.String("c", "", "Execute STLC code from command line")
-	help    = flag.Bool("h", false, "Show help")
+	command       = flag.String("c", "", "Execute STLC code from command line")
+	bidirectional = flag.Bool("b", false, "Use bidirectional type checking")
+	format        = flag.String("format", formatText, "Output format: text or json")
+	intMode       = flag.String("int", builtin.IntWrap.String(), "Integer semantics: wrap, checked (fail on overflow) or big (arbitrary precision)")
+	help          = flag.Bool("h", false, "Show help")
 )
 
 func main() {
@@ -29,21 +43,46 @@ func main() {
 	}
 
 	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if *format == formatJSON {
+			writeJSON(&jsonOutput{Errors: reports(err)})
+		} else {
+			printErrors(err)
+		}
 		os.Exit(1)
 	}
 }
 
 func run() error {
+	if *format != formatText && *format != formatJSON {
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+
+	mode, err := builtin.ParseIntMode(*intMode)
+	if err != nil {
+		return err
+	}
+	evalConfig.IntMode = mode
+
 	if *command != "" {
-		return runCode(*command)
+		return runCode("", *command)
 	}
 
 	args := flag.Args()
 
+	if len(args) > 0 && args[0] == "lsp" {
+		if len(args) > 1 {
+			return errors.New("too many arguments")
+		}
+		return lsp.NewServer().Serve(os.Stdin, os.Stdout)
+	}
+
+	if len(args) > 0 && args[0] == "fmt" {
+		return runFmt(args[1:])
+	}
+
 	switch len(args) {
 	case 0:
-		if isInteractive() {
+		if isTerminal(os.Stdin) {
 			return startREPL()
 		} else {
 			return runStdin()
@@ -62,6 +101,8 @@ func run() error {
 func usage() {
 	command := "gostlc"
 	fmt.Fprintf(os.Stderr, "Usage: %s [options] [file]\n", command)
+	fmt.Fprintf(os.Stderr, "       %s fmt [-w] [-unicode] [file...]\n", command)
+	fmt.Fprintf(os.Stderr, "       %s lsp\n", command)
 	fmt.Fprintf(os.Stderr, "\nOptions:\n")
 	flag.PrintDefaults()
 	fmt.Fprintf(os.Stderr, "\nExamples:\n")
@@ -69,61 +110,196 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "  %s file.stlc          # Run file\n", command)
 	fmt.Fprintf(os.Stderr, "  %s -c \"(\\x:Int.x) 42\" # Execute code\n", command)
 	fmt.Fprintf(os.Stderr, "  echo \"code\" | %s -    # Read from stdin\n", command)
+	fmt.Fprintf(os.Stderr, "  %s -b file.stlc       # Run file with bidirectional type checking\n", command)
+	fmt.Fprintf(os.Stderr, "  %s --format=json file.stlc # Print the result or errors as JSON\n", command)
+	fmt.Fprintf(os.Stderr, "  %s -int=big file.stlc  # Run file with arbitrary-precision integers\n", command)
+	fmt.Fprintf(os.Stderr, "  %s fmt -w file.stlc   # Format file in place\n", command)
+	fmt.Fprintf(os.Stderr, "  %s lsp                # Start the language server on stdio\n", command)
 }
 
-func isInteractive() bool {
-	fi, err := os.Stdin.Stat()
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
 	if err != nil {
 		return false
 	}
 	return (fi.Mode() & os.ModeCharDevice) != 0
 }
 
-func evaluate(code string) (values.Value, error) {
-	expr, err := parser.Parse(code)
-	if err != nil {
-		return nil, err
+// evalConfig is the configuration of the evaluation, whose integer mode is set by -int.
+var evalConfig = eval.DefaultConfig
+
+// evaluate runs the program read from r and returns its value and type, with the warnings found in it.
+// The positions of its errors carry the source name.
+func evaluate(r io.Reader, name string) (values.Value, ast.Type, []error, error) {
+	// A program recovered from syntax errors is still type checked to report its type errors as well
+	prog, parseErr := parser.ParseProgramReader(r, name)
+	if prog == nil {
+		return nil, nil, nil, parseErr
 	}
 
-	typedExpr, err := types.Check(expr)
-	if err != nil {
-		return nil, err
+	checkConfig := types.Config{IntMode: evalConfig.IntMode, Bidirectional: *bidirectional}
+	typedProg, checkErr := checkConfig.CheckProgram(prog)
+	if err := errors.Join(parseErr, checkErr); err != nil {
+		return nil, nil, nil, err
 	}
 
-	value, err := eval.Eval(typedExpr)
+	value, err := evalConfig.EvalProgram(typedProg)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	return value, nil
+	return value, typedProg.Main.Type(), typedProg.Warnings, nil
 }
 
 func runFile(filename string) error {
-	data, err := os.ReadFile(filename)
+	f, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
-	return runCode(string(data))
+	defer f.Close()
+	return runReader(filename, f, func() string {
+		// The file is read again for the lines that diagnostics refer to
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	})
 }
 
 func runStdin() error {
-	data, err := io.ReadAll(os.Stdin)
+	// Standard input cannot be read again, so a copy of it is kept for the diagnostics
+	var src strings.Builder
+	return runReader("<stdin>", io.TeeReader(os.Stdin, &src), src.String)
+}
+
+// runReader runs the program streamed from r.
+// The source is only obtained from source when there are diagnostics to show the lines of.
+func runReader(name string, r io.Reader, source func() string) error {
+	resp, typ, warnings, err := evaluate(r, name)
 	if err != nil {
+		return &sourceError{name: name, code: source(), err: err}
+	}
+	var code string
+	if len(warnings) > 0 {
+		code = source()
+	}
+	printResult(name, code, resp, typ, warnings)
+	return nil
+}
+
+// runFmt formats the given files, or stdin if none is given,
+// and prints the result or, with -w, writes it back to the files.
+func runFmt(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := flags.Bool("w", false, "Write the result to the file instead of stdout")
+	unicode := flags.Bool("unicode", false, "Print λ, Λ, → and ∀ instead of \\, /\\, -> and forall")
+	if err := flags.Parse(args); err != nil {
 		return err
 	}
-	return runCode(string(data))
+
+	config := printer.DefaultConfig
+	config.Unicode = *unicode
+
+	if flags.NArg() == 0 {
+		if *write {
+			return errors.New("cannot use -w with standard input")
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		return formatCode(&config, "<stdin>", string(data), os.Stdout)
+	}
+
+	for _, filename := range flags.Args() {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if !*write {
+			if err := formatCode(&config, filename, string(data), os.Stdout); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var b strings.Builder
+		if err := formatCode(&config, filename, string(data), &b); err != nil {
+			return err
+		}
+		// Leave formatted files untouched
+		if b.String() == string(data) {
+			continue
+		}
+		if err := os.WriteFile(filename, []byte(b.String()), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func runCode(code string) error {
-	resp, err := evaluate(code)
+func formatCode(config *printer.Config, name, code string, w io.Writer) error {
+	formatted, err := config.Format(code)
 	if err != nil {
-		return err
+		return &sourceError{name: name, code: code, err: err}
 	}
+	_, err = io.WriteString(w, formatted)
+	return err
+}
 
-	fmt.Fprintln(os.Stdout, resp.String())
+// sourceError is an error found in code read from the file name, if not empty.
+type sourceError struct {
+	name string
+	code string
+	err  error
+}
+
+func (e *sourceError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sourceError) Unwrap() error {
+	return e.err
+}
+
+func runCode(name, code string) error {
+	resp, typ, warnings, err := evaluate(strings.NewReader(code), name)
+	if err != nil {
+		return &sourceError{name: name, code: code, err: err}
+	}
+	printResult(name, code, resp, typ, warnings)
 	return nil
 }
 
+// printResult prints the value of the program in code read from the file name, after its warnings.
+func printResult(name, code string, resp values.Value, typ ast.Type, warnings []error) {
+	if *format == formatJSON {
+		var reports []diagnostics.Report
+		for _, d := range diagnostics.FromWarnings(warnings) {
+			reports = append(reports, d.Report(name))
+		}
+		writeJSON(&jsonOutput{Value: resp.String(), Type: typ.String(), Warnings: reports})
+		return
+	}
+	printWarnings(name, code, warnings)
+	fmt.Fprintln(os.Stdout, resp.String())
+}
+
+// printWarnings prints the diagnostics of the warnings found in code read from the file name,
+// with the source lines they refer to.
+func printWarnings(name, code string, warnings []error) {
+	r := &diagnostics.Renderer{Filename: name, Source: code, Color: isTerminal(os.Stderr)}
+	for _, d := range diagnostics.FromWarnings(warnings) {
+		r.Render(os.Stderr, d)
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// replConfig is the style in which the REPL prints types, switched by :ascii and :unicode.
+var replConfig = printer.DefaultConfig
+
 func startREPL() error {
 	fmt.Println("STLC REPL")
 	fmt.Println("Type :quit or :q to exit, :help for help")
@@ -157,7 +333,7 @@ func startREPL() error {
 		}
 
 		if err := evalAndPrint(line); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			printErrors(err)
 		}
 	}
 
@@ -177,6 +353,8 @@ func handleCommand(cmd string) error {
 		fmt.Println("REPL Commands:")
 		fmt.Println("  :quit, :q  - Exit the REPL")
 		fmt.Println("  :help, :h  - Show this help message")
+		fmt.Println("  :ascii     - Print types with ->, forall (default)")
+		fmt.Println("  :unicode   - Print types with →, ∀")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  42")
@@ -184,17 +362,78 @@ func handleCommand(cmd string) error {
 		fmt.Println("  (\\x:Int.x) 42")
 		fmt.Println("  (\\f:Int->Int.\\x:Int.f (f x))")
 		return nil
+	case ":ascii":
+		replConfig.Unicode = false
+		return nil
+	case ":unicode":
+		replConfig.Unicode = true
+		return nil
 	default:
 		return fmt.Errorf("unknown command: %s", cmd)
 	}
 }
 
+// printErrors prints the diagnostics of err sorted by position,
+// with the source lines they refer to if err was found in source code.
+func printErrors(err error) {
+	r := &diagnostics.Renderer{Color: isTerminal(os.Stderr)}
+	var srcErr *sourceError
+	if errors.As(err, &srcErr) {
+		r.Filename = srcErr.name
+		r.Source = srcErr.code
+		err = srcErr.err
+	}
+
+	for i, d := range diagnostics.FromError(err) {
+		if i > 0 {
+			fmt.Fprintln(os.Stderr)
+		}
+		r.Render(os.Stderr, d)
+	}
+}
+
+// jsonOutput is the result of a run in JSON format: the value and type of the program with its warnings, or its errors.
+type jsonOutput struct {
+	Value    string               `json:"value,omitempty"`
+	Type     string               `json:"type,omitempty"`
+	Warnings []diagnostics.Report `json:"warnings,omitempty"`
+	Errors   []diagnostics.Report `json:"errors,omitempty"`
+}
+
+func writeJSON(out *jsonOutput) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
+
+// reports returns the machine-readable diagnostics of err sorted by position.
+func reports(err error) []diagnostics.Report {
+	var name string
+	var srcErr *sourceError
+	if errors.As(err, &srcErr) {
+		name = srcErr.name
+		err = srcErr.err
+	}
+
+	var reports []diagnostics.Report
+	for _, d := range diagnostics.FromError(err) {
+		reports = append(reports, d.Report(name))
+	}
+	return reports
+}
+
 func evalAndPrint(code string) error {
-	resp, err := evaluate(code)
+	resp, typ, warnings, err := evaluate(strings.NewReader(code), "")
 	if err != nil {
-		return err
+		return &sourceError{code: code, err: err}
 	}
+	printWarnings("", code, warnings)
 
-	fmt.Printf("=> %s\n", resp)
+	var b strings.Builder
+	if err := replConfig.Fprint(&b, typ); err != nil {
+		return err
+	}
+	fmt.Printf("=> %s : %s\n", resp, b.String())
 	return nil
 }
@@ -1,6 +1,11 @@
 package ast
 
 import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
 	"github.com/shota3506/gostlc/internal/token"
 )
 
@@ -9,22 +14,32 @@ type Expr interface {
 	exprNode()
 
 	Position() token.Position
+	// Span returns the range of source text the expression was parsed from.
+	Span() token.Span
 }
 
 // VarExpr represents a variable expression.
+// Op is the symbol of the operator the variable was written as, such as "+" for add, and is empty for a name.
+// An operator always refers to the builtin function, even where its name is bound otherwise.
 type VarExpr struct {
 	Pos  token.Position
+	End  token.Position
 	Name string
+	Op   string
 }
 
 func (VarExpr) exprNode() {}
 func (v VarExpr) Position() token.Position {
 	return v.Pos
 }
+func (v VarExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
 
 // AbsExpr represents a lambda abstraction expression.
 type AbsExpr struct {
 	Pos       token.Position
+	End       token.Position
 	Param     string
 	ParamType Type
 	Body      Expr
@@ -34,10 +49,14 @@ func (AbsExpr) exprNode() {}
 func (v AbsExpr) Position() token.Position {
 	return v.Pos
 }
+func (v AbsExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
 
 // AppExpr represents a function application expression.
 type AppExpr struct {
 	Pos  token.Position
+	End  token.Position
 	Func Expr
 	Arg  Expr
 }
@@ -46,10 +65,14 @@ func (AppExpr) exprNode() {}
 func (v AppExpr) Position() token.Position {
 	return v.Pos
 }
+func (v AppExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
 
 // BoolExpr represents a boolean literal expression.
 type BoolExpr struct {
 	Pos   token.Position
+	End   token.Position
 	Value bool
 }
 
@@ -57,21 +80,100 @@ func (BoolExpr) exprNode() {}
 func (v BoolExpr) Position() token.Position {
 	return v.Pos
 }
+func (v BoolExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
 
 // IntExpr represents an integer literal expression.
 type IntExpr struct {
 	Pos   token.Position
+	End   token.Position
 	Value int
+	// Big is the value of a literal out of the range of Value, which is then zero, or nil.
+	Big *big.Int
 }
 
 func (IntExpr) exprNode() {}
 func (v IntExpr) Position() token.Position {
 	return v.Pos
 }
+func (v IntExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// UnitExpr represents the unit value ().
+type UnitExpr struct {
+	Pos token.Position
+	End token.Position
+}
+
+func (UnitExpr) exprNode() {}
+func (v UnitExpr) Position() token.Position {
+	return v.Pos
+}
+func (v UnitExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// StringExpr represents a string literal expression.
+type StringExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Value string
+}
+
+func (StringExpr) exprNode() {}
+func (v StringExpr) Position() token.Position {
+	return v.Pos
+}
+func (v StringExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// CharExpr represents a character literal expression.
+type CharExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Value rune
+}
+
+func (CharExpr) exprNode() {}
+func (v CharExpr) Position() token.Position {
+	return v.Pos
+}
+func (v CharExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// FloatExpr represents a floating-point literal expression.
+type FloatExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Value float64
+}
+
+func (FloatExpr) exprNode() {}
+func (v FloatExpr) Position() token.Position {
+	return v.Pos
+}
+func (v FloatExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// FormatFloat returns the float literal of f, which always has a fractional part.
+// Infinities and NaN, which have no literal, are formatted as +Inf, -Inf and NaN.
+func FormatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !math.IsInf(f, 0) && !math.IsNaN(f) && !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
 
 // IfExpr represents an if-then-else expression.
 type IfExpr struct {
 	Pos  token.Position
+	End  token.Position
 	Cond Expr
 	Then Expr
 	Else Expr
@@ -81,3 +183,352 @@ func (IfExpr) exprNode() {}
 func (v IfExpr) Position() token.Position {
 	return v.Pos
 }
+func (v IfExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// LetExpr represents a let binding expression.
+// Type is the optional type annotation of the bound name and is nil if omitted.
+type LetExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Name  string
+	Type  Type
+	Value Expr
+	Body  Expr
+}
+
+func (LetExpr) exprNode() {}
+func (v LetExpr) Position() token.Position {
+	return v.Pos
+}
+func (v LetExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// FixExpr represents a fixed point expression: fix e.
+type FixExpr struct {
+	Pos  token.Position
+	End  token.Position
+	Func Expr
+}
+
+func (FixExpr) exprNode() {}
+func (v FixExpr) Position() token.Position {
+	return v.Pos
+}
+func (v FixExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// TupleExpr represents a tuple construction expression: (e1, e2, ...).
+type TupleExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Elems []Expr
+}
+
+func (TupleExpr) exprNode() {}
+func (v TupleExpr) Position() token.Position {
+	return v.Pos
+}
+func (v TupleExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// ProjExpr represents a tuple projection expression: e.n.
+// Index is 1 based.
+type ProjExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Tuple Expr
+	Index int
+}
+
+func (ProjExpr) exprNode() {}
+func (v ProjExpr) Position() token.Position {
+	return v.Pos
+}
+func (v ProjExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// InjExpr represents an injection into a sum type: inl e as T or inr e as T.
+// Left reports whether the value is injected into the left component of Type.
+type InjExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Left  bool
+	Value Expr
+	Type  Type
+}
+
+func (InjExpr) exprNode() {}
+func (v InjExpr) Position() token.Position {
+	return v.Pos
+}
+func (v InjExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// CaseExpr represents a case analysis of a sum: case e of inl x => e1 | inr y => e2.
+type CaseExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee Expr
+	LeftVar   string
+	Left      Expr
+	RightVar  string
+	Right     Expr
+}
+
+func (CaseExpr) exprNode() {}
+func (v CaseExpr) Position() token.Position {
+	return v.Pos
+}
+func (v CaseExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// NilExpr represents the empty list of elements of type ElemType: nil[T].
+type NilExpr struct {
+	Pos      token.Position
+	End      token.Position
+	ElemType Type
+}
+
+func (NilExpr) exprNode() {}
+func (v NilExpr) Position() token.Position {
+	return v.Pos
+}
+func (v NilExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// ListExpr represents a list literal: [e1, e2, ...].
+// The element type of the empty list [] is inferred.
+type ListExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Elems []Expr
+}
+
+func (ListExpr) exprNode() {}
+func (v ListExpr) Position() token.Position {
+	return v.Pos
+}
+func (v ListExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// ListCaseExpr represents a case analysis of a list: case e of [] => e1 | h :: t => e2.
+type ListCaseExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee Expr
+	Nil       Expr
+	HeadVar   string
+	TailVar   string
+	Cons      Expr
+}
+
+func (ListCaseExpr) exprNode() {}
+func (v ListCaseExpr) Position() token.Position {
+	return v.Pos
+}
+func (v ListCaseExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// RecordExpr represents a record construction expression: {l1 = e1, l2 = e2, ...}.
+type RecordExpr struct {
+	Pos    token.Position
+	End    token.Position
+	Fields []RecordField
+}
+
+// RecordField represents a labeled field of a record construction expression.
+type RecordField struct {
+	Label string
+	Value Expr
+}
+
+func (RecordExpr) exprNode() {}
+func (v RecordExpr) Position() token.Position {
+	return v.Pos
+}
+func (v RecordExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// RecordProjExpr represents a record field projection expression: e.l.
+type RecordProjExpr struct {
+	Pos    token.Position
+	End    token.Position
+	Record Expr
+	Label  string
+}
+
+func (RecordProjExpr) exprNode() {}
+func (v RecordProjExpr) Position() token.Position {
+	return v.Pos
+}
+func (v RecordProjExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// VariantExpr represents a variant construction expression: <l = e> as T.
+type VariantExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Label string
+	Value Expr
+	Type  Type
+}
+
+func (VariantExpr) exprNode() {}
+func (v VariantExpr) Position() token.Position {
+	return v.Pos
+}
+func (v VariantExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// VariantCaseExpr represents a case analysis of a variant:
+// case e of <l1 = x1> => e1 | <l2 = x2> => e2 | ...
+type VariantCaseExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee Expr
+	Branches  []VariantBranch
+}
+
+// VariantBranch represents a branch of a variant case analysis.
+type VariantBranch struct {
+	Pos   token.Position
+	End   token.Position
+	Label string
+	Var   string
+	Body  Expr
+}
+
+func (b VariantBranch) Span() token.Span {
+	return token.Span{Start: b.Pos, End: b.End}
+}
+
+func (VariantCaseExpr) exprNode() {}
+func (v VariantCaseExpr) Position() token.Position {
+	return v.Pos
+}
+func (v VariantCaseExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// MatchExpr represents a pattern match: match e with | p1 -> e1 | p2 -> e2 | ...
+// The arms are tried in order, and the first whose pattern matches the value of the scrutinee is taken.
+type MatchExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee Expr
+	Arms      []MatchArm
+}
+
+// MatchArm represents an arm of a pattern match.
+type MatchArm struct {
+	Pos     token.Position
+	End     token.Position
+	Pattern Pattern
+	Body    Expr
+}
+
+func (a MatchArm) Span() token.Span {
+	return token.Span{Start: a.Pos, End: a.End}
+}
+
+func (MatchExpr) exprNode() {}
+func (v MatchExpr) Position() token.Position {
+	return v.Pos
+}
+func (v MatchExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// TyAbsExpr represents a type abstraction: /\A. e.
+type TyAbsExpr struct {
+	Pos     token.Position
+	End     token.Position
+	TypeVar string
+	Body    Expr
+}
+
+func (TyAbsExpr) exprNode() {}
+func (v TyAbsExpr) Position() token.Position {
+	return v.Pos
+}
+func (v TyAbsExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// TyAppExpr represents a type application: e [T].
+type TyAppExpr struct {
+	Pos     token.Position
+	End     token.Position
+	Func    Expr
+	TypeArg Type
+}
+
+func (TyAppExpr) exprNode() {}
+func (v TyAppExpr) Position() token.Position {
+	return v.Pos
+}
+func (v TyAppExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// FoldExpr represents the folding of a value into a recursive type: fold [T] e.
+// Type is the recursive type mu X. U, and the value is of type U with X replaced by Type.
+type FoldExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Type  Type
+	Value Expr
+}
+
+func (FoldExpr) exprNode() {}
+func (v FoldExpr) Position() token.Position {
+	return v.Pos
+}
+func (v FoldExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// UnfoldExpr represents the unfolding of a value of a recursive type: unfold [T] e.
+// Type is the recursive type mu X. U of the value, which is unfolded to type U with X replaced by Type.
+type UnfoldExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Type  Type
+	Value Expr
+}
+
+func (UnfoldExpr) exprNode() {}
+func (v UnfoldExpr) Position() token.Position {
+	return v.Pos
+}
+func (v UnfoldExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
+
+// ErrorExpr stands in for an expression that failed to parse, so that parsing can continue after it.
+type ErrorExpr struct {
+	Pos token.Position
+	End token.Position
+}
+
+func (ErrorExpr) exprNode() {}
+func (v ErrorExpr) Position() token.Position {
+	return v.Pos
+}
+func (v ErrorExpr) Span() token.Span {
+	return token.Span{Start: v.Pos, End: v.End}
+}
@@ -0,0 +1,53 @@
+package ast
+
+// Operator is an infix or prefix operator, which stands for the application of a builtin function.
+// An operator application is represented as the application of a VarExpr naming the builtin,
+// with the operator symbol in its Op field.
+type Operator struct {
+	Symbol  string
+	Builtin string
+	// Prec is the precedence of the operator. Operators of higher precedence bind tighter.
+	Prec  int
+	Assoc Assoc
+}
+
+// Assoc is the associativity of an infix operator.
+type Assoc int
+
+const (
+	AssocLeft  Assoc = iota // a - b - c is (a - b) - c
+	AssocRight              // a && b && c is a && (b && c)
+	AssocNone               // a == b == c is a syntax error
+)
+
+// PrefixPrec is the precedence of prefix operators, which bind tighter than infix operators
+// but looser than application, so -f x is -(f x).
+const PrefixPrec = 7
+
+// BinaryOperators are the infix operators by symbol.
+var BinaryOperators = map[string]Operator{
+	"||": {Symbol: "||", Builtin: "or", Prec: 1, Assoc: AssocRight},
+	"&&": {Symbol: "&&", Builtin: "and", Prec: 2, Assoc: AssocRight},
+	"==": {Symbol: "==", Builtin: "eq", Prec: 3, Assoc: AssocNone},
+	"!=": {Symbol: "!=", Builtin: "ne", Prec: 3, Assoc: AssocNone},
+	"<":  {Symbol: "<", Builtin: "lt", Prec: 3, Assoc: AssocNone},
+	"<=": {Symbol: "<=", Builtin: "le", Prec: 3, Assoc: AssocNone},
+	">":  {Symbol: ">", Builtin: "gt", Prec: 3, Assoc: AssocNone},
+	">=": {Symbol: ">=", Builtin: "ge", Prec: 3, Assoc: AssocNone},
+	"::": {Symbol: "::", Builtin: "cons", Prec: 4, Assoc: AssocRight},
+	"+":  {Symbol: "+", Builtin: "add", Prec: 5, Assoc: AssocLeft},
+	"-":  {Symbol: "-", Builtin: "sub", Prec: 5, Assoc: AssocLeft},
+	"*":  {Symbol: "*", Builtin: "mul", Prec: 6, Assoc: AssocLeft},
+	"/":  {Symbol: "/", Builtin: "div", Prec: 6, Assoc: AssocLeft},
+	"%":  {Symbol: "%", Builtin: "mod", Prec: 6, Assoc: AssocLeft},
+}
+
+// UnaryOperators are the prefix operators by symbol.
+var UnaryOperators = map[string]Operator{
+	"-": {Symbol: "-", Builtin: "neg", Prec: PrefixPrec},
+	"!": {Symbol: "!", Builtin: "not", Prec: PrefixPrec},
+}
+
+// SectionParam is the parameter of the abstraction that a right section such as (+ 1) stands for: \#. # + 1.
+// It is not an identifier, so that it never captures a variable of the operand.
+const SectionParam = "#"
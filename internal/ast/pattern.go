@@ -0,0 +1,111 @@
+package ast
+
+import (
+	"strconv"
+
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+// Pattern represents a pattern of a match expression, which tests the shape of a value and binds its parts.
+type Pattern interface {
+	patternNode()
+
+	Position() token.Position
+	Span() token.Span
+}
+
+// WildcardPattern represents the pattern _, which matches any value without binding it.
+type WildcardPattern struct {
+	Pos token.Position
+	End token.Position
+}
+
+func (WildcardPattern) patternNode() {}
+func (p WildcardPattern) Position() token.Position {
+	return p.Pos
+}
+func (p WildcardPattern) Span() token.Span {
+	return token.Span{Start: p.Pos, End: p.End}
+}
+
+// VarPattern represents a variable pattern, which matches any value and binds it to Name.
+type VarPattern struct {
+	Pos  token.Position
+	End  token.Position
+	Name string
+}
+
+func (VarPattern) patternNode() {}
+func (p VarPattern) Position() token.Position {
+	return p.Pos
+}
+func (p VarPattern) Span() token.Span {
+	return token.Span{Start: p.Pos, End: p.End}
+}
+
+// LiteralPattern represents a pattern matching the value of a literal,
+// which is a BoolExpr, IntExpr, UnitExpr, StringExpr or CharExpr.
+type LiteralPattern struct {
+	Value Expr
+}
+
+func (LiteralPattern) patternNode() {}
+func (p LiteralPattern) Position() token.Position {
+	return p.Value.Position()
+}
+func (p LiteralPattern) Span() token.Span {
+	return p.Value.Span()
+}
+
+// Key returns the source form of the literal, such as 42, "a" or (), which tells apart
+// the values of a type matched by literals and constructors.
+func (p LiteralPattern) Key() string {
+	switch e := p.Value.(type) {
+	case *BoolExpr:
+		return strconv.FormatBool(e.Value)
+	case *IntExpr:
+		if e.Big != nil {
+			return e.Big.String()
+		}
+		return strconv.Itoa(e.Value)
+	case *StringExpr:
+		return strconv.Quote(e.Value)
+	case *CharExpr:
+		return strconv.QuoteRune(e.Value)
+	default:
+		return "()"
+	}
+}
+
+// ConstructorPattern represents a pattern matching the values built by the constructor Name
+// whose arguments match Args: C p1 ... pn.
+type ConstructorPattern struct {
+	Pos  token.Position
+	End  token.Position
+	Name string
+	Args []Pattern
+}
+
+func (ConstructorPattern) patternNode() {}
+func (p ConstructorPattern) Position() token.Position {
+	return p.Pos
+}
+func (p ConstructorPattern) Span() token.Span {
+	return token.Span{Start: p.Pos, End: p.End}
+}
+
+// PatternVars returns the variables bound by p from left to right.
+func PatternVars(p Pattern) []*VarPattern {
+	switch p := p.(type) {
+	case *VarPattern:
+		return []*VarPattern{p}
+	case *ConstructorPattern:
+		var vars []*VarPattern
+		for _, arg := range p.Args {
+			vars = append(vars, PatternVars(arg)...)
+		}
+		return vars
+	default:
+		return nil
+	}
+}
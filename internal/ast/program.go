@@ -0,0 +1,96 @@
+package ast
+
+import (
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+// Program represents a sequence of top-level definitions followed by a main expression.
+// Types are the algebraic data type declarations among the definitions, which are visible throughout the program.
+type Program struct {
+	Types []*TypeDecl
+	Decls []*Decl
+	Main  Expr
+}
+
+// TypeDecl represents an algebraic data type declaration: type Name = C1 T ... | C2 T ... | ...
+type TypeDecl struct {
+	Pos          token.Position
+	End          token.Position
+	Name         string
+	Constructors []*Constructor
+}
+
+func (d *TypeDecl) Position() token.Position {
+	return d.Pos
+}
+
+func (d *TypeDecl) Span() token.Span {
+	return token.Span{Start: d.Pos, End: d.End}
+}
+
+// Constructor represents a constructor of an algebraic data type with the types of its arguments.
+type Constructor struct {
+	Pos    token.Position
+	End    token.Position
+	Name   string
+	Params []Type
+}
+
+func (c *Constructor) Span() token.Span {
+	return token.Span{Start: c.Pos, End: c.End}
+}
+
+// Decl represents a top-level definition: let name [: type] = expr.
+// Type is the optional type annotation of the bound name and is nil if omitted.
+type Decl struct {
+	Pos   token.Position
+	End   token.Position
+	Name  string
+	Type  Type
+	Value Expr
+}
+
+func (d *Decl) Position() token.Position {
+	return d.Pos
+}
+
+func (d *Decl) Span() token.Span {
+	return token.Span{Start: d.Pos, End: d.End}
+}
+
+// TypedProgram represents a type-checked program.
+type TypedProgram struct {
+	Types []*TypeDecl
+	Decls []*TypedDecl
+	Main  TypedExpr
+
+	// Warnings are the problems found by the type checker that do not prevent the program from running,
+	// such as redundant match arms.
+	Warnings []error
+}
+
+// Type returns the type of the main expression.
+func (p *TypedProgram) Type() Type {
+	return p.Main.Type()
+}
+
+// TypedDecl represents a type-checked top-level definition.
+type TypedDecl struct {
+	Pos   token.Position
+	End   token.Position
+	Name  string
+	Value TypedExpr
+}
+
+func (d *TypedDecl) Position() token.Position {
+	return d.Pos
+}
+
+func (d *TypedDecl) Span() token.Span {
+	return token.Span{Start: d.Pos, End: d.End}
+}
+
+// Type returns the type of the bound name.
+func (d *TypedDecl) Type() Type {
+	return d.Value.Type()
+}
@@ -1,6 +1,9 @@
 package ast
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Type represents a type in the lambda calculus with simple types.
 type Type interface {
@@ -38,6 +41,62 @@ func (i *IntType) Equal(u Type) bool {
 	return ok
 }
 
+// UnitType represents the unit type, whose only value is ().
+type UnitType struct{}
+
+func (*UnitType) typeNode() {}
+
+func (*UnitType) String() string {
+	return "Unit"
+}
+
+func (t *UnitType) Equal(u Type) bool {
+	_, ok := u.(*UnitType)
+	return ok
+}
+
+// StringType represents the string type.
+type StringType struct{}
+
+func (*StringType) typeNode() {}
+
+func (*StringType) String() string {
+	return "String"
+}
+
+func (t *StringType) Equal(u Type) bool {
+	_, ok := u.(*StringType)
+	return ok
+}
+
+// CharType represents the character type.
+type CharType struct{}
+
+func (*CharType) typeNode() {}
+
+func (*CharType) String() string {
+	return "Char"
+}
+
+func (t *CharType) Equal(u Type) bool {
+	_, ok := u.(*CharType)
+	return ok
+}
+
+// FloatType represents the floating-point number type.
+type FloatType struct{}
+
+func (*FloatType) typeNode() {}
+
+func (*FloatType) String() string {
+	return "Float"
+}
+
+func (t *FloatType) Equal(u Type) bool {
+	_, ok := u.(*FloatType)
+	return ok
+}
+
 // FuncType represents a function type from one type to another.
 type FuncType struct {
 	From Type
@@ -57,3 +116,379 @@ func (f *FuncType) Equal(u Type) bool {
 	}
 	return f.From.Equal(v.From) && f.To.Equal(v.To)
 }
+
+// ProductType represents a product type of two or more component types.
+type ProductType struct {
+	Elems []Type
+}
+
+func (*ProductType) typeNode() {}
+
+func (p *ProductType) String() string {
+	elems := make([]string, len(p.Elems))
+	for i, elem := range p.Elems {
+		elems[i] = elem.String()
+	}
+	return fmt.Sprintf("(%s)", strings.Join(elems, "*"))
+}
+
+func (p *ProductType) Equal(u Type) bool {
+	v, ok := u.(*ProductType)
+	if !ok || len(p.Elems) != len(v.Elems) {
+		return false
+	}
+	for i := range p.Elems {
+		if !p.Elems[i].Equal(v.Elems[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SumType represents a tagged sum of two types.
+type SumType struct {
+	Left  Type
+	Right Type
+}
+
+func (*SumType) typeNode() {}
+
+func (s *SumType) String() string {
+	return fmt.Sprintf("(%s+%s)", s.Left, s.Right)
+}
+
+func (s *SumType) Equal(u Type) bool {
+	v, ok := u.(*SumType)
+	if !ok {
+		return false
+	}
+	return s.Left.Equal(v.Left) && s.Right.Equal(v.Right)
+}
+
+// ListType represents the type of lists whose elements are of type Elem.
+type ListType struct {
+	Elem Type
+}
+
+func (*ListType) typeNode() {}
+
+func (l *ListType) String() string {
+	return fmt.Sprintf("(List %s)", l.Elem)
+}
+
+func (l *ListType) Equal(u Type) bool {
+	v, ok := u.(*ListType)
+	if !ok {
+		return false
+	}
+	return l.Elem.Equal(v.Elem)
+}
+
+// DataType represents an algebraic data type declared by name.
+// Two data types are equal if they have the same name.
+type DataType struct {
+	Name string
+}
+
+func (*DataType) typeNode() {}
+
+func (d *DataType) String() string {
+	return d.Name
+}
+
+func (d *DataType) Equal(u Type) bool {
+	v, ok := u.(*DataType)
+	return ok && d.Name == v.Name
+}
+
+// Field represents a labeled component of a record or variant type.
+type Field struct {
+	Label string
+	Type  Type
+}
+
+// RecordType represents a record type with labeled fields.
+// Two record types are equal if they have the same fields in any order.
+type RecordType struct {
+	Fields []Field
+}
+
+func (*RecordType) typeNode() {}
+
+func (r *RecordType) String() string {
+	return fmt.Sprintf("{%s}", fieldsString(r.Fields))
+}
+
+func (r *RecordType) Equal(u Type) bool {
+	v, ok := u.(*RecordType)
+	if !ok {
+		return false
+	}
+	return equalFields(r.Fields, v.Fields)
+}
+
+// Field returns the type of the field with the given label.
+func (r *RecordType) Field(label string) (Type, bool) {
+	return lookupField(r.Fields, label)
+}
+
+// VariantType represents a variant type with labeled alternatives.
+// Two variant types are equal if they have the same alternatives in any order.
+type VariantType struct {
+	Fields []Field
+}
+
+func (*VariantType) typeNode() {}
+
+func (v *VariantType) String() string {
+	return fmt.Sprintf("<%s>", fieldsString(v.Fields))
+}
+
+func (v *VariantType) Equal(u Type) bool {
+	w, ok := u.(*VariantType)
+	if !ok {
+		return false
+	}
+	return equalFields(v.Fields, w.Fields)
+}
+
+// Field returns the type of the alternative with the given label.
+func (v *VariantType) Field(label string) (Type, bool) {
+	return lookupField(v.Fields, label)
+}
+
+func fieldsString(fields []Field) string {
+	elems := make([]string, len(fields))
+	for i, field := range fields {
+		elems[i] = fmt.Sprintf("%s:%s", field.Label, field.Type)
+	}
+	return strings.Join(elems, ", ")
+}
+
+func equalFields(a, b []Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, field := range a {
+		typ, ok := lookupField(b, field.Label)
+		if !ok || !field.Type.Equal(typ) {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupField(fields []Field, label string) (Type, bool) {
+	for _, field := range fields {
+		if field.Label == label {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// MetaVar represents a type variable to be solved by type inference.
+type MetaVar struct {
+	ID int
+}
+
+func (*MetaVar) typeNode() {}
+
+func (m *MetaVar) String() string {
+	name := string(rune('a' + m.ID%26))
+	if m.ID >= 26 {
+		name += fmt.Sprint(m.ID / 26)
+	}
+	return "'" + name
+}
+
+func (m *MetaVar) Equal(u Type) bool {
+	v, ok := u.(*MetaVar)
+	return ok && m.ID == v.ID
+}
+
+// TypeVar represents a named type variable, such as a quantified variable of a polymorphic type.
+type TypeVar struct {
+	Name string
+}
+
+func (*TypeVar) typeNode() {}
+
+func (v *TypeVar) String() string {
+	return v.Name
+}
+
+func (v *TypeVar) Equal(u Type) bool {
+	w, ok := u.(*TypeVar)
+	return ok && v.Name == w.Name
+}
+
+// ForallType represents a universally quantified type: forall A. T.
+// Types differing only in the names of their bound type variables are equal.
+type ForallType struct {
+	Var  string
+	Body Type
+}
+
+func (*ForallType) typeNode() {}
+
+func (f *ForallType) String() string {
+	return fmt.Sprintf("(forall %s. %s)", f.Var, f.Body)
+}
+
+func (f *ForallType) Equal(u Type) bool {
+	v, ok := u.(*ForallType)
+	if !ok {
+		return false
+	}
+	if f.Var == v.Var {
+		return f.Body.Equal(v.Body)
+	}
+
+	// Rename both bound variables to a name occurring in neither body
+	fresh := &TypeVar{Name: FreshTypeVar(f.Var, f.Body, v.Body)}
+	return renameTypeVar(f.Body, f.Var, fresh).Equal(renameTypeVar(v.Body, v.Var, fresh))
+}
+
+// RecType represents an iso-recursive type: mu X. T, whose values are those of T with X standing for mu X. T.
+// A value of T[X := mu X. T] becomes one of mu X. T by fold, and unfold takes it back.
+// Types differing only in the names of their bound type variables are equal.
+type RecType struct {
+	Var  string
+	Body Type
+}
+
+func (*RecType) typeNode() {}
+
+func (r *RecType) String() string {
+	return fmt.Sprintf("(mu %s. %s)", r.Var, r.Body)
+}
+
+func (r *RecType) Equal(u Type) bool {
+	v, ok := u.(*RecType)
+	if !ok {
+		return false
+	}
+	if r.Var == v.Var {
+		return r.Body.Equal(v.Body)
+	}
+
+	// Rename both bound variables to a name occurring in neither body
+	fresh := &TypeVar{Name: FreshTypeVar(r.Var, r.Body, v.Body)}
+	return renameTypeVar(r.Body, r.Var, fresh).Equal(renameTypeVar(v.Body, v.Var, fresh))
+}
+
+// FreshTypeVar returns name, primed as many times as needed
+// so that it differs from every type variable, free or bound, occurring in the given types.
+func FreshTypeVar(name string, types ...Type) string {
+	used := map[string]bool{}
+	for _, t := range types {
+		typeVarNames(t, used)
+	}
+	for used[name] {
+		name += "'"
+	}
+	return name
+}
+
+func typeVarNames(t Type, used map[string]bool) {
+	switch t := t.(type) {
+	case *TypeVar:
+		used[t.Name] = true
+	case *ForallType:
+		used[t.Var] = true
+		typeVarNames(t.Body, used)
+	case *RecType:
+		used[t.Var] = true
+		typeVarNames(t.Body, used)
+	case *FuncType:
+		typeVarNames(t.From, used)
+		typeVarNames(t.To, used)
+	case *ProductType:
+		for _, elem := range t.Elems {
+			typeVarNames(elem, used)
+		}
+	case *SumType:
+		typeVarNames(t.Left, used)
+		typeVarNames(t.Right, used)
+	case *ListType:
+		typeVarNames(t.Elem, used)
+	case *RecordType:
+		for _, field := range t.Fields {
+			typeVarNames(field.Type, used)
+		}
+	case *VariantType:
+		for _, field := range t.Fields {
+			typeVarNames(field.Type, used)
+		}
+	}
+}
+
+// renameTypeVar replaces the free occurrences of the type variable name in t with to,
+// which must not occur in t.
+func renameTypeVar(t Type, name string, to *TypeVar) Type {
+	switch t := t.(type) {
+	case *TypeVar:
+		if t.Name == name {
+			return to
+		}
+		return t
+	case *ForallType:
+		if t.Var == name {
+			return t
+		}
+		return &ForallType{Var: t.Var, Body: renameTypeVar(t.Body, name, to)}
+	case *RecType:
+		if t.Var == name {
+			return t
+		}
+		return &RecType{Var: t.Var, Body: renameTypeVar(t.Body, name, to)}
+	case *FuncType:
+		return &FuncType{
+			From: renameTypeVar(t.From, name, to),
+			To:   renameTypeVar(t.To, name, to),
+		}
+	case *ProductType:
+		elems := make([]Type, len(t.Elems))
+		for i, elem := range t.Elems {
+			elems[i] = renameTypeVar(elem, name, to)
+		}
+		return &ProductType{Elems: elems}
+	case *SumType:
+		return &SumType{
+			Left:  renameTypeVar(t.Left, name, to),
+			Right: renameTypeVar(t.Right, name, to),
+		}
+	case *ListType:
+		return &ListType{Elem: renameTypeVar(t.Elem, name, to)}
+	case *RecordType:
+		return &RecordType{Fields: renameFieldTypeVar(t.Fields, name, to)}
+	case *VariantType:
+		return &VariantType{Fields: renameFieldTypeVar(t.Fields, name, to)}
+	default:
+		return t
+	}
+}
+
+func renameFieldTypeVar(fields []Field, name string, to *TypeVar) []Field {
+	renamed := make([]Field, len(fields))
+	for i, field := range fields {
+		renamed[i] = Field{Label: field.Label, Type: renameTypeVar(field.Type, name, to)}
+	}
+	return renamed
+}
+
+// ErrorType is the type of an expression that failed to type check.
+// It unifies with every type so that a single error is reported only once.
+type ErrorType struct{}
+
+func (*ErrorType) typeNode() {}
+
+func (*ErrorType) String() string {
+	return "<error>"
+}
+
+func (e *ErrorType) Equal(u Type) bool {
+	_, ok := u.(*ErrorType)
+	return ok
+}
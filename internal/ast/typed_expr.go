@@ -8,6 +8,7 @@ type TypedExpr interface {
 	typedExprNode()
 
 	Position() token.Position
+	Span() token.Span
 	Type() Type
 }
 
@@ -26,10 +27,12 @@ func NewTypedVarExpr(typ Type, expr *VarExpr) *TypedVarExpr {
 
 func (TypedVarExpr) typedExprNode()              {}
 func (e *TypedVarExpr) Position() token.Position { return e.Pos }
+func (e *TypedVarExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
 func (e *TypedVarExpr) Type() Type               { return e.typ }
 
 type TypedAbsExpr struct {
 	Pos       token.Position
+	End       token.Position
 	Param     string
 	ParamType Type
 	Body      TypedExpr
@@ -37,9 +40,10 @@ type TypedAbsExpr struct {
 	typ Type
 }
 
-func NewTypedAbsExpr(typ Type, pos token.Position, param string, paramType Type, body TypedExpr) *TypedAbsExpr {
+func NewTypedAbsExpr(typ Type, span token.Span, param string, paramType Type, body TypedExpr) *TypedAbsExpr {
 	return &TypedAbsExpr{
-		Pos:       pos,
+		Pos:       span.Start,
+		End:       span.End,
 		Param:     param,
 		ParamType: paramType,
 		Body:      body,
@@ -49,19 +53,22 @@ func NewTypedAbsExpr(typ Type, pos token.Position, param string, paramType Type,
 
 func (TypedAbsExpr) typedExprNode()              {}
 func (e *TypedAbsExpr) Position() token.Position { return e.Pos }
+func (e *TypedAbsExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
 func (e *TypedAbsExpr) Type() Type               { return e.typ }
 
 type TypedAppExpr struct {
 	Pos  token.Position
+	End  token.Position
 	Func TypedExpr
 	Arg  TypedExpr
 
 	typ Type
 }
 
-func NewTypedAppExpr(typ Type, pos token.Position, fn, arg TypedExpr) *TypedAppExpr {
+func NewTypedAppExpr(typ Type, span token.Span, fn, arg TypedExpr) *TypedAppExpr {
 	return &TypedAppExpr{
-		Pos:  pos,
+		Pos:  span.Start,
+		End:  span.End,
 		Func: fn,
 		Arg:  arg,
 		typ:  typ,
@@ -70,6 +77,7 @@ func NewTypedAppExpr(typ Type, pos token.Position, fn, arg TypedExpr) *TypedAppE
 
 func (TypedAppExpr) typedExprNode()              {}
 func (e *TypedAppExpr) Position() token.Position { return e.Pos }
+func (e *TypedAppExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
 func (e *TypedAppExpr) Type() Type               { return e.typ }
 
 type TypedBoolExpr struct {
@@ -84,6 +92,7 @@ func NewTypedBoolExpr(expr *BoolExpr) *TypedBoolExpr {
 
 func (TypedBoolExpr) typedExprNode()              {}
 func (e *TypedBoolExpr) Position() token.Position { return e.Pos }
+func (e *TypedBoolExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
 func (e *TypedBoolExpr) Type() Type               { return &BoolType{} }
 
 type TypedIntExpr struct {
@@ -98,18 +107,81 @@ func NewTypedIntExpr(expr *IntExpr) *TypedIntExpr {
 
 func (TypedIntExpr) typedExprNode()              {}
 func (e *TypedIntExpr) Position() token.Position { return e.Pos }
+func (e *TypedIntExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
 func (e *TypedIntExpr) Type() Type               { return &IntType{} }
 
+type TypedUnitExpr struct {
+	UnitExpr
+}
+
+func NewTypedUnitExpr(expr *UnitExpr) *TypedUnitExpr {
+	return &TypedUnitExpr{
+		UnitExpr: *expr,
+	}
+}
+
+func (TypedUnitExpr) typedExprNode()              {}
+func (e *TypedUnitExpr) Position() token.Position { return e.Pos }
+func (e *TypedUnitExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedUnitExpr) Type() Type               { return &UnitType{} }
+
+type TypedStringExpr struct {
+	StringExpr
+}
+
+func NewTypedStringExpr(expr *StringExpr) *TypedStringExpr {
+	return &TypedStringExpr{
+		StringExpr: *expr,
+	}
+}
+
+func (TypedStringExpr) typedExprNode()              {}
+func (e *TypedStringExpr) Position() token.Position { return e.Pos }
+func (e *TypedStringExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedStringExpr) Type() Type               { return &StringType{} }
+
+type TypedCharExpr struct {
+	CharExpr
+}
+
+func NewTypedCharExpr(expr *CharExpr) *TypedCharExpr {
+	return &TypedCharExpr{
+		CharExpr: *expr,
+	}
+}
+
+func (TypedCharExpr) typedExprNode()              {}
+func (e *TypedCharExpr) Position() token.Position { return e.Pos }
+func (e *TypedCharExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedCharExpr) Type() Type               { return &CharType{} }
+
+type TypedFloatExpr struct {
+	FloatExpr
+}
+
+func NewTypedFloatExpr(expr *FloatExpr) *TypedFloatExpr {
+	return &TypedFloatExpr{
+		FloatExpr: *expr,
+	}
+}
+
+func (TypedFloatExpr) typedExprNode()              {}
+func (e *TypedFloatExpr) Position() token.Position { return e.Pos }
+func (e *TypedFloatExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedFloatExpr) Type() Type               { return &FloatType{} }
+
 type TypedIfExpr struct {
 	Pos  token.Position
+	End  token.Position
 	Cond TypedExpr
 	Then TypedExpr
 	Else TypedExpr
 }
 
-func NewTypedIfExpr(pos token.Position, cond, then, elseExpr TypedExpr) *TypedIfExpr {
+func NewTypedIfExpr(span token.Span, cond, then, elseExpr TypedExpr) *TypedIfExpr {
 	return &TypedIfExpr{
-		Pos:  pos,
+		Pos:  span.Start,
+		End:  span.End,
 		Cond: cond,
 		Then: then,
 		Else: elseExpr,
@@ -118,4 +190,465 @@ func NewTypedIfExpr(pos token.Position, cond, then, elseExpr TypedExpr) *TypedIf
 
 func (TypedIfExpr) typedExprNode()              {}
 func (e *TypedIfExpr) Position() token.Position { return e.Pos }
+func (e *TypedIfExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
 func (e *TypedIfExpr) Type() Type               { return e.Then.Type() }
+
+type TypedLetExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Name  string
+	Value TypedExpr
+	Body  TypedExpr
+}
+
+func NewTypedLetExpr(span token.Span, name string, value, body TypedExpr) *TypedLetExpr {
+	return &TypedLetExpr{
+		Pos:   span.Start,
+		End:   span.End,
+		Name:  name,
+		Value: value,
+		Body:  body,
+	}
+}
+
+func (TypedLetExpr) typedExprNode()              {}
+func (e *TypedLetExpr) Position() token.Position { return e.Pos }
+func (e *TypedLetExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedLetExpr) Type() Type               { return e.Body.Type() }
+
+type TypedFixExpr struct {
+	Pos  token.Position
+	End  token.Position
+	Func TypedExpr
+
+	typ Type
+}
+
+func NewTypedFixExpr(typ Type, span token.Span, fn TypedExpr) *TypedFixExpr {
+	return &TypedFixExpr{
+		Pos:  span.Start,
+		End:  span.End,
+		Func: fn,
+		typ:  typ,
+	}
+}
+
+func (TypedFixExpr) typedExprNode()              {}
+func (e *TypedFixExpr) Position() token.Position { return e.Pos }
+func (e *TypedFixExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedFixExpr) Type() Type               { return e.typ }
+
+type TypedTupleExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Elems []TypedExpr
+
+	typ Type
+}
+
+func NewTypedTupleExpr(typ Type, span token.Span, elems []TypedExpr) *TypedTupleExpr {
+	return &TypedTupleExpr{
+		Pos:   span.Start,
+		End:   span.End,
+		Elems: elems,
+		typ:   typ,
+	}
+}
+
+func (TypedTupleExpr) typedExprNode()              {}
+func (e *TypedTupleExpr) Position() token.Position { return e.Pos }
+func (e *TypedTupleExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedTupleExpr) Type() Type               { return e.typ }
+
+type TypedProjExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Tuple TypedExpr
+	Index int
+
+	typ Type
+}
+
+func NewTypedProjExpr(typ Type, span token.Span, tuple TypedExpr, index int) *TypedProjExpr {
+	return &TypedProjExpr{
+		Pos:   span.Start,
+		End:   span.End,
+		Tuple: tuple,
+		Index: index,
+		typ:   typ,
+	}
+}
+
+func (TypedProjExpr) typedExprNode()              {}
+func (e *TypedProjExpr) Position() token.Position { return e.Pos }
+func (e *TypedProjExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedProjExpr) Type() Type               { return e.typ }
+
+type TypedInjExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Left  bool
+	Value TypedExpr
+
+	typ Type
+}
+
+func NewTypedInjExpr(typ Type, span token.Span, left bool, value TypedExpr) *TypedInjExpr {
+	return &TypedInjExpr{
+		Pos:   span.Start,
+		End:   span.End,
+		Left:  left,
+		Value: value,
+		typ:   typ,
+	}
+}
+
+func (TypedInjExpr) typedExprNode()              {}
+func (e *TypedInjExpr) Position() token.Position { return e.Pos }
+func (e *TypedInjExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedInjExpr) Type() Type               { return e.typ }
+
+type TypedCaseExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee TypedExpr
+	LeftVar   string
+	Left      TypedExpr
+	RightVar  string
+	Right     TypedExpr
+}
+
+func NewTypedCaseExpr(span token.Span, scrutinee TypedExpr, leftVar string, left TypedExpr, rightVar string, right TypedExpr) *TypedCaseExpr {
+	return &TypedCaseExpr{
+		Pos:       span.Start,
+		End:       span.End,
+		Scrutinee: scrutinee,
+		LeftVar:   leftVar,
+		Left:      left,
+		RightVar:  rightVar,
+		Right:     right,
+	}
+}
+
+func (TypedCaseExpr) typedExprNode()              {}
+func (e *TypedCaseExpr) Position() token.Position { return e.Pos }
+func (e *TypedCaseExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedCaseExpr) Type() Type               { return e.Left.Type() }
+
+type TypedNilExpr struct {
+	Pos token.Position
+	End token.Position
+
+	typ Type
+}
+
+func NewTypedNilExpr(typ Type, span token.Span) *TypedNilExpr {
+	return &TypedNilExpr{
+		Pos: span.Start,
+		End: span.End,
+		typ: typ,
+	}
+}
+
+func (TypedNilExpr) typedExprNode()              {}
+func (e *TypedNilExpr) Position() token.Position { return e.Pos }
+func (e *TypedNilExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedNilExpr) Type() Type               { return e.typ }
+
+type TypedListExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Elems []TypedExpr
+
+	typ Type
+}
+
+func NewTypedListExpr(typ Type, span token.Span, elems []TypedExpr) *TypedListExpr {
+	return &TypedListExpr{
+		Pos:   span.Start,
+		End:   span.End,
+		Elems: elems,
+		typ:   typ,
+	}
+}
+
+func (TypedListExpr) typedExprNode()              {}
+func (e *TypedListExpr) Position() token.Position { return e.Pos }
+func (e *TypedListExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedListExpr) Type() Type               { return e.typ }
+
+type TypedListCaseExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee TypedExpr
+	Nil       TypedExpr
+	HeadVar   string
+	TailVar   string
+	Cons      TypedExpr
+}
+
+func NewTypedListCaseExpr(span token.Span, scrutinee TypedExpr, nilBody TypedExpr, headVar, tailVar string, consBody TypedExpr) *TypedListCaseExpr {
+	return &TypedListCaseExpr{
+		Pos:       span.Start,
+		End:       span.End,
+		Scrutinee: scrutinee,
+		Nil:       nilBody,
+		HeadVar:   headVar,
+		TailVar:   tailVar,
+		Cons:      consBody,
+	}
+}
+
+func (TypedListCaseExpr) typedExprNode()              {}
+func (e *TypedListCaseExpr) Position() token.Position { return e.Pos }
+func (e *TypedListCaseExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedListCaseExpr) Type() Type               { return e.Nil.Type() }
+
+type TypedRecordExpr struct {
+	Pos    token.Position
+	End    token.Position
+	Fields []TypedRecordField
+
+	typ Type
+}
+
+type TypedRecordField struct {
+	Label string
+	Value TypedExpr
+}
+
+func NewTypedRecordExpr(typ Type, span token.Span, fields []TypedRecordField) *TypedRecordExpr {
+	return &TypedRecordExpr{
+		Pos:    span.Start,
+		End:    span.End,
+		Fields: fields,
+		typ:    typ,
+	}
+}
+
+func (TypedRecordExpr) typedExprNode()              {}
+func (e *TypedRecordExpr) Position() token.Position { return e.Pos }
+func (e *TypedRecordExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedRecordExpr) Type() Type               { return e.typ }
+
+type TypedRecordProjExpr struct {
+	Pos    token.Position
+	End    token.Position
+	Record TypedExpr
+	Label  string
+
+	typ Type
+}
+
+func NewTypedRecordProjExpr(typ Type, span token.Span, record TypedExpr, label string) *TypedRecordProjExpr {
+	return &TypedRecordProjExpr{
+		Pos:    span.Start,
+		End:    span.End,
+		Record: record,
+		Label:  label,
+		typ:    typ,
+	}
+}
+
+func (TypedRecordProjExpr) typedExprNode()              {}
+func (e *TypedRecordProjExpr) Position() token.Position { return e.Pos }
+func (e *TypedRecordProjExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedRecordProjExpr) Type() Type               { return e.typ }
+
+type TypedVariantExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Label string
+	Value TypedExpr
+
+	typ Type
+}
+
+func NewTypedVariantExpr(typ Type, span token.Span, label string, value TypedExpr) *TypedVariantExpr {
+	return &TypedVariantExpr{
+		Pos:   span.Start,
+		End:   span.End,
+		Label: label,
+		Value: value,
+		typ:   typ,
+	}
+}
+
+func (TypedVariantExpr) typedExprNode()              {}
+func (e *TypedVariantExpr) Position() token.Position { return e.Pos }
+func (e *TypedVariantExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedVariantExpr) Type() Type               { return e.typ }
+
+type TypedVariantCaseExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee TypedExpr
+	Branches  []TypedVariantBranch
+
+	typ Type
+}
+
+type TypedVariantBranch struct {
+	Pos   token.Position
+	End   token.Position
+	Label string
+	Var   string
+	Body  TypedExpr
+}
+
+func NewTypedVariantCaseExpr(typ Type, span token.Span, scrutinee TypedExpr, branches []TypedVariantBranch) *TypedVariantCaseExpr {
+	return &TypedVariantCaseExpr{
+		Pos:       span.Start,
+		End:       span.End,
+		Scrutinee: scrutinee,
+		Branches:  branches,
+		typ:       typ,
+	}
+}
+
+func (TypedVariantCaseExpr) typedExprNode()              {}
+func (e *TypedVariantCaseExpr) Position() token.Position { return e.Pos }
+func (e *TypedVariantCaseExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedVariantCaseExpr) Type() Type               { return e.typ }
+
+type TypedMatchExpr struct {
+	Pos       token.Position
+	End       token.Position
+	Scrutinee TypedExpr
+	Arms      []TypedMatchArm
+
+	typ Type
+}
+
+type TypedMatchArm struct {
+	Pos     token.Position
+	End     token.Position
+	Pattern Pattern
+	Body    TypedExpr
+}
+
+func NewTypedMatchExpr(typ Type, span token.Span, scrutinee TypedExpr, arms []TypedMatchArm) *TypedMatchExpr {
+	return &TypedMatchExpr{
+		Pos:       span.Start,
+		End:       span.End,
+		Scrutinee: scrutinee,
+		Arms:      arms,
+		typ:       typ,
+	}
+}
+
+func (TypedMatchExpr) typedExprNode()              {}
+func (e *TypedMatchExpr) Position() token.Position { return e.Pos }
+func (e *TypedMatchExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedMatchExpr) Type() Type               { return e.typ }
+
+type TypedTyAbsExpr struct {
+	Pos     token.Position
+	End     token.Position
+	TypeVar string
+	Body    TypedExpr
+
+	typ Type
+}
+
+func NewTypedTyAbsExpr(typ Type, span token.Span, typeVar string, body TypedExpr) *TypedTyAbsExpr {
+	return &TypedTyAbsExpr{
+		Pos:     span.Start,
+		End:     span.End,
+		TypeVar: typeVar,
+		Body:    body,
+		typ:     typ,
+	}
+}
+
+func (TypedTyAbsExpr) typedExprNode()              {}
+func (e *TypedTyAbsExpr) Position() token.Position { return e.Pos }
+func (e *TypedTyAbsExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedTyAbsExpr) Type() Type               { return e.typ }
+
+type TypedTyAppExpr struct {
+	Pos     token.Position
+	End     token.Position
+	Func    TypedExpr
+	TypeArg Type
+
+	typ Type
+}
+
+func NewTypedTyAppExpr(typ Type, span token.Span, fn TypedExpr, typeArg Type) *TypedTyAppExpr {
+	return &TypedTyAppExpr{
+		Pos:     span.Start,
+		End:     span.End,
+		Func:    fn,
+		TypeArg: typeArg,
+		typ:     typ,
+	}
+}
+
+func (TypedTyAppExpr) typedExprNode()              {}
+func (e *TypedTyAppExpr) Position() token.Position { return e.Pos }
+func (e *TypedTyAppExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedTyAppExpr) Type() Type               { return e.typ }
+
+// TypedFoldExpr is a fold [T] e, whose type is the recursive type T.
+type TypedFoldExpr struct {
+	Pos   token.Position
+	End   token.Position
+	Value TypedExpr
+
+	typ Type
+}
+
+func NewTypedFoldExpr(typ Type, span token.Span, value TypedExpr) *TypedFoldExpr {
+	return &TypedFoldExpr{
+		Pos:   span.Start,
+		End:   span.End,
+		Value: value,
+		typ:   typ,
+	}
+}
+
+func (TypedFoldExpr) typedExprNode()              {}
+func (e *TypedFoldExpr) Position() token.Position { return e.Pos }
+func (e *TypedFoldExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedFoldExpr) Type() Type               { return e.typ }
+
+// TypedUnfoldExpr is an unfold [T] e, whose type is the unfolding of the recursive type RecType of the value.
+type TypedUnfoldExpr struct {
+	Pos     token.Position
+	End     token.Position
+	RecType Type
+	Value   TypedExpr
+
+	typ Type
+}
+
+func NewTypedUnfoldExpr(typ Type, span token.Span, recType Type, value TypedExpr) *TypedUnfoldExpr {
+	return &TypedUnfoldExpr{
+		Pos:     span.Start,
+		End:     span.End,
+		RecType: recType,
+		Value:   value,
+		typ:     typ,
+	}
+}
+
+func (TypedUnfoldExpr) typedExprNode()              {}
+func (e *TypedUnfoldExpr) Position() token.Position { return e.Pos }
+func (e *TypedUnfoldExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedUnfoldExpr) Type() Type               { return e.typ }
+
+// TypedErrorExpr stands in for an expression that failed to type check. Its type is ErrorType.
+type TypedErrorExpr struct {
+	Pos token.Position
+	End token.Position
+}
+
+func NewTypedErrorExpr(span token.Span) *TypedErrorExpr {
+	return &TypedErrorExpr{Pos: span.Start, End: span.End}
+}
+
+func (TypedErrorExpr) typedExprNode()              {}
+func (e *TypedErrorExpr) Position() token.Position { return e.Pos }
+func (e *TypedErrorExpr) Span() token.Span         { return token.Span{Start: e.Pos, End: e.End} }
+func (e *TypedErrorExpr) Type() Type               { return &ErrorType{} }
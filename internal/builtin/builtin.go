@@ -3,233 +3,632 @@ package builtin
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/shota3506/gostlc/internal/ast"
 	"github.com/shota3506/gostlc/internal/values"
 )
 
-func binaryOp[T, U values.Value](f func(a, b T) U) func(arg1 values.Value) (values.Value, error) {
+var (
+	// ErrDivisionByZero is returned by div and mod for a zero divisor.
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrNegativeExponent is returned by pow for a negative exponent.
+	ErrNegativeExponent = errors.New("negative exponent")
+	// ErrNegativeShift is returned by shl and shr for a negative shift count.
+	ErrNegativeShift = errors.New("negative shift count")
+	// ErrEmptyList is returned by head and tail for the empty list.
+	ErrEmptyList = errors.New("empty list")
+)
+
+// binary is the implementation of an operation on two arguments that does not depend on the integer mode.
+// It is given the name and the type of the builtin function, which its partial applications carry.
+type binary func(name string, typ *ast.FuncType) func(arg1 values.Value) (values.Value, error)
+
+func (f binary) fn(name string, typ *ast.FuncType, _ IntMode) func(arg values.Value) (values.Value, error) {
+	return f(name, typ)
+}
+
+func binaryOp[T, U values.Value](f func(a, b T) U) binary {
+	return partialBinaryOp(func(a, b T) (U, error) {
+		return f(a, b), nil
+	})
+}
+
+// partialBinaryOp is like binaryOp for operations that fail for some arguments.
+func partialBinaryOp[T, U values.Value](f func(a, b T) (U, error)) binary {
+	return func(name string, typ *ast.FuncType) func(arg1 values.Value) (values.Value, error) {
+		rest := typ.To.(*ast.FuncType)
+		return func(arg1 values.Value) (values.Value, error) {
+			tArg1, ok := arg1.(T)
+			if !ok {
+				return nil, fmt.Errorf("type mismatch")
+			}
+			return &values.PartialBuiltinFunc{
+				Name:       name,
+				ParamType:  rest.From,
+				ReturnType: rest.To,
+				Fn: func(arg2 values.Value) (values.Value, error) {
+					tArg2, ok := arg2.(T)
+					if !ok {
+						return nil, errors.New("type mismatch")
+					}
+					return f(tArg1, tArg2)
+				},
+			}, nil
+		}
+	}
+}
+
+// shortCircuitOp returns a boolean operation whose second argument is lazy:
+// it is evaluated only if the first argument is not short, which is otherwise the result.
+func shortCircuitOp(name string, short bool) func(arg1 values.Value) (values.Value, error) {
 	return func(arg1 values.Value) (values.Value, error) {
-		tArg1, ok := arg1.(T)
+		a, ok := arg1.(*values.BoolValue)
 		if !ok {
-			return nil, fmt.Errorf("type mismatch")
+			return nil, errors.New("type mismatch: expected Bool")
 		}
 		return &values.PartialBuiltinFunc{
-			Name:       "and",
+			Name:       name,
 			ParamType:  &ast.BoolType{},
 			ReturnType: &ast.BoolType{},
+			Lazy:       true,
 			Fn: func(arg2 values.Value) (values.Value, error) {
-				tArg2, ok := arg2.(T)
-				if !ok {
-					return nil, errors.New("type mismatch")
+				if a.Value == short {
+					return a, nil
+				}
+				b, err := values.Force(arg2)
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := b.(*values.BoolValue); !ok {
+					return nil, errors.New("type mismatch: expected Bool")
 				}
-				return f(tArg1, tArg2), nil
+				return b, nil
 			},
 		}, nil
 	}
 }
 
-var FunctionTypes = map[string]ast.Type{
+// builtin is an entry of the table of builtin functions.
+type builtin struct {
+	name string
+	typ  *ast.FuncType
+	impl implementation
+}
+
+// funcType returns the curried type of a function from the types of its parameters and result.
+func funcType(types ...ast.Type) *ast.FuncType {
+	t := types[len(types)-1]
+	for i := len(types) - 2; i >= 0; i-- {
+		t = &ast.FuncType{From: types[i], To: t}
+	}
+	return t.(*ast.FuncType)
+}
+
+func intOpType() *ast.FuncType {
+	return funcType(&ast.IntType{}, &ast.IntType{}, &ast.IntType{})
+}
+
+func intUnaryOpType() *ast.FuncType {
+	return funcType(&ast.IntType{}, &ast.IntType{})
+}
+
+func comparisonType() *ast.FuncType {
+	return funcType(&ast.IntType{}, &ast.IntType{}, &ast.BoolType{})
+}
+
+func boolOpType() *ast.FuncType {
+	return funcType(&ast.BoolType{}, &ast.BoolType{}, &ast.BoolType{})
+}
+
+func floatOpType() *ast.FuncType {
+	return funcType(&ast.FloatType{}, &ast.FloatType{}, &ast.FloatType{})
+}
+
+func floatComparisonType() *ast.FuncType {
+	return funcType(&ast.FloatType{}, &ast.FloatType{}, &ast.BoolType{})
+}
+
+func typeVar(name string) *ast.TypeVar {
+	return &ast.TypeVar{Name: name}
+}
+
+func listType(elem ast.Type) *ast.ListType {
+	return &ast.ListType{Elem: elem}
+}
+
+// listArg returns a list argument.
+func listArg(arg values.Value) (*values.ListValue, error) {
+	list, ok := arg.(*values.ListValue)
+	if !ok {
+		return nil, errors.New("type mismatch: expected List")
+	}
+	return list, nil
+}
+
+// nonEmpty returns a list argument of the function name, failing with ErrEmptyList if it is empty.
+func nonEmpty(name string, arg values.Value) (*values.ListValue, error) {
+	list, err := listArg(arg)
+	if err != nil {
+		return nil, err
+	}
+	if list.Empty() {
+		return nil, fmt.Errorf("%s of %w", name, ErrEmptyList)
+	}
+	return list, nil
+}
+
+// fold returns the fold of a list from the right, foldr f z [x1, ..., xn] = f x1 (... (f xn z)),
+// or from the left, foldl f z [x1, ..., xn] = f (... (f z x1)) xn.
+// The applications of f are left to the evaluator as a values.Call.
+func fold(name string, right bool) func(f values.Value) (values.Value, error) {
+	a, b := typeVar("a"), typeVar("b")
+	return func(f values.Value) (values.Value, error) {
+		return &values.PartialBuiltinFunc{
+			Name:       name,
+			ParamType:  b,
+			ReturnType: funcType(listType(a), b),
+			Fn: func(z values.Value) (values.Value, error) {
+				return &values.PartialBuiltinFunc{
+					Name:       name,
+					ParamType:  listType(a),
+					ReturnType: b,
+					Fn: func(arg values.Value) (values.Value, error) {
+						list, err := listArg(arg)
+						if err != nil {
+							return nil, err
+						}
+						elems := list.Elems()
+						acc := z
+						if right {
+							for i := len(elems) - 1; i >= 0; i-- {
+								acc = &values.Call{Fn: f, Args: []values.Value{elems[i], acc}}
+							}
+						} else {
+							for _, elem := range elems {
+								acc = &values.Call{Fn: f, Args: []values.Value{acc, elem}}
+							}
+						}
+						return acc, nil
+					},
+				}, nil
+			},
+		}, nil
+	}
+}
+
+// floatOp returns an operation on two floats.
+func floatOp(f func(a, b float64) float64) binary {
+	return binaryOp(func(a, b *values.FloatValue) *values.FloatValue {
+		return &values.FloatValue{Value: f(a.Value, b.Value)}
+	})
+}
+
+// floatComparison returns a comparison of two floats.
+func floatComparison(f func(a, b float64) bool) binary {
+	return binaryOp(func(a, b *values.FloatValue) *values.BoolValue {
+		return &values.BoolValue{Value: f(a.Value, b.Value)}
+	})
+}
+
+// substr returns the n characters of s from the index start, counted in characters from zero.
+func substr(s string, start, n int) (string, error) {
+	runes := []rune(s)
+	if start < 0 || n < 0 || start > len(runes) || n > len(runes)-start {
+		return "", fmt.Errorf("substring out of range: %d characters from %d in a string of length %d", n, start, len(runes))
+	}
+	return string(runes[start : start+n]), nil
+}
+
+// builtins is the table from which FunctionTypes and Functions are generated.
+var builtins = []builtin{
 	// Arithmetic operations
-	"add": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.IntType{},
-		},
-	},
-	"sub": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.IntType{},
-		},
-	},
-	// Boolean operations
-	"and": &ast.FuncType{
-		From: &ast.BoolType{},
-		To: &ast.FuncType{
-			From: &ast.BoolType{},
-			To:   &ast.BoolType{},
-		},
-	},
-	"or": &ast.FuncType{
-		From: &ast.BoolType{},
-		To: &ast.FuncType{
-			From: &ast.BoolType{},
-			To:   &ast.BoolType{},
-		},
-	},
-	"not": &ast.FuncType{
-		From: &ast.BoolType{},
-		To:   &ast.BoolType{},
-	},
-	// Comparison operations
-	"eq": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-	},
-	"ne": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-	},
-	"lt": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-	},
-	"le": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-	},
-	"gt": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-	},
-	"ge": &ast.FuncType{
-		From: &ast.IntType{},
-		To: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-	},
-}
-
-var Functions = map[string]values.Value{
-	"add": &values.BuiltinFunc{
-		Name:      "add",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.IntType{},
-		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.IntValue {
-			return &values.IntValue{Value: a.Value + b.Value}
-		}),
-	},
-	"sub": &values.BuiltinFunc{
-		Name:      "sub",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.IntType{},
-		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.IntValue {
-			return &values.IntValue{Value: a.Value - b.Value}
-		}),
-	},
-	"and": &values.BuiltinFunc{
-		Name:      "and",
-		ParamType: &ast.BoolType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.BoolType{},
-			To:   &ast.BoolType{},
-		},
-		Fn: binaryOp(func(a, b *values.BoolValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value && b.Value}
-		}),
-	},
-	"or": &values.BuiltinFunc{
-		Name:      "or",
-		ParamType: &ast.BoolType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.BoolType{},
-			To:   &ast.BoolType{},
-		},
-		Fn: binaryOp(func(a, b *values.BoolValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value || b.Value}
-		}),
-	},
-	"not": &values.BuiltinFunc{
-		Name:       "not",
-		ParamType:  &ast.BoolType{},
-		ReturnType: &ast.BoolType{},
-		Fn: func(arg values.Value) (values.Value, error) {
-			boolArg, ok := arg.(*values.BoolValue)
-			if !ok {
-				return nil, errors.New("type mismatch: expected Bool")
+	{"add", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			c, overflow := addOverflow(a, b)
+			return c, overflow, nil
+		},
+		big: exactBig((*big.Int).Add),
+	}},
+	{"sub", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			c, overflow := subOverflow(a, b)
+			return c, overflow, nil
+		},
+		big: exactBig((*big.Int).Sub),
+	}},
+	{"mul", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			c, overflow := mulOverflow(a, b)
+			return c, overflow, nil
+		},
+		big: exactBig((*big.Int).Mul),
+	}},
+	{"div", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			if b == 0 {
+				return 0, false, ErrDivisionByZero
 			}
-			return &values.BoolValue{Value: !boolArg.Value}, nil
+			return a / b, a == math.MinInt && b == -1, nil
 		},
-	},
-	"eq": &values.BuiltinFunc{
-		Name:      "eq",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
+		big: func(a, b *big.Int) (*big.Int, error) {
+			if b.Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			// Quo truncates toward zero like the division of 64-bit integers
+			return new(big.Int).Quo(a, b), nil
 		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value == b.Value}
-		}),
-	},
-	"ne": &values.BuiltinFunc{
-		Name:      "ne",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value != b.Value}
+	}},
+	{"mod", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			if b == 0 {
+				return 0, false, ErrDivisionByZero
+			}
+			return a % b, false, nil
+		},
+		big: func(a, b *big.Int) (*big.Int, error) {
+			if b.Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			return new(big.Int).Rem(a, b), nil
+		},
+	}},
+	{"neg", intUnaryOpType(), intUnary{
+		fixed: func(a int) (int, bool) {
+			return -a, a == math.MinInt
+		},
+		big: func(a *big.Int) *big.Int {
+			return new(big.Int).Neg(a)
+		},
+	}},
+	{"abs", intUnaryOpType(), intUnary{
+		fixed: func(a int) (int, bool) {
+			if a < 0 {
+				return -a, a == math.MinInt
+			}
+			return a, false
+		},
+		big: func(a *big.Int) *big.Int {
+			return new(big.Int).Abs(a)
+		},
+	}},
+	{"min", intOpType(), intBinary{
+		fixed: exact(func(a, b int) int {
+			return min(a, b)
 		}),
-	},
-	"lt": &values.BuiltinFunc{
-		Name:      "lt",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value < b.Value}
+		big: func(a, b *big.Int) (*big.Int, error) {
+			if a.Cmp(b) <= 0 {
+				return a, nil
+			}
+			return b, nil
+		},
+	}},
+	{"max", intOpType(), intBinary{
+		fixed: exact(func(a, b int) int {
+			return max(a, b)
 		}),
-	},
-	"le": &values.BuiltinFunc{
-		Name:      "le",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value <= b.Value}
+		big: func(a, b *big.Int) (*big.Int, error) {
+			if a.Cmp(b) >= 0 {
+				return a, nil
+			}
+			return b, nil
+		},
+	}},
+	{"pow", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			if b < 0 {
+				return 0, false, ErrNegativeExponent
+			}
+			c, overflow := powOverflow(a, b)
+			return c, overflow, nil
+		},
+		big: func(a, b *big.Int) (*big.Int, error) {
+			if b.Sign() < 0 {
+				return nil, ErrNegativeExponent
+			}
+			return new(big.Int).Exp(a, b, nil), nil
+		},
+	}},
+	// Bitwise operations
+	{"band", intOpType(), intBinary{
+		fixed: exact(func(a, b int) int {
+			return a & b
 		}),
-	},
-	"gt": &values.BuiltinFunc{
-		Name:      "gt",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value > b.Value}
+		big: exactBig((*big.Int).And),
+	}},
+	{"bor", intOpType(), intBinary{
+		fixed: exact(func(a, b int) int {
+			return a | b
 		}),
-	},
-	"ge": &values.BuiltinFunc{
-		Name:      "ge",
-		ParamType: &ast.IntType{},
-		ReturnType: &ast.FuncType{
-			From: &ast.IntType{},
-			To:   &ast.BoolType{},
-		},
-		Fn: binaryOp(func(a, b *values.IntValue) *values.BoolValue {
-			return &values.BoolValue{Value: a.Value >= b.Value}
+		big: exactBig((*big.Int).Or),
+	}},
+	{"bxor", intOpType(), intBinary{
+		fixed: exact(func(a, b int) int {
+			return a ^ b
 		}),
-	},
+		big: exactBig((*big.Int).Xor),
+	}},
+	{"bnot", intUnaryOpType(), intUnary{
+		fixed: func(a int) (int, bool) {
+			return ^a, false
+		},
+		big: func(a *big.Int) *big.Int {
+			return new(big.Int).Not(a)
+		},
+	}},
+	{"shl", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			if b < 0 {
+				return 0, false, ErrNegativeShift
+			}
+			c := a << b
+			return c, c>>b != a, nil
+		},
+		big: func(a, b *big.Int) (*big.Int, error) {
+			n, err := bigShift(b)
+			if err != nil {
+				return nil, err
+			}
+			return new(big.Int).Lsh(a, n), nil
+		},
+	}},
+	{"shr", intOpType(), intBinary{
+		fixed: func(a, b int) (int, bool, error) {
+			if b < 0 {
+				return 0, false, ErrNegativeShift
+			}
+			// Arithmetic shift: the sign is kept
+			return a >> b, false, nil
+		},
+		big: func(a, b *big.Int) (*big.Int, error) {
+			if b.Sign() < 0 {
+				return nil, ErrNegativeShift
+			}
+			// Shifting by more than the length of a leaves only its sign
+			if b.Cmp(big.NewInt(int64(a.BitLen()))) > 0 {
+				return big.NewInt(int64(min(a.Sign(), 0))), nil
+			}
+			return new(big.Int).Rsh(a, uint(b.Uint64())), nil
+		},
+	}},
+	// Boolean operations
+	{"and", boolOpType(), anyMode(shortCircuitOp("and", false))},
+	{"or", boolOpType(), anyMode(shortCircuitOp("or", true))},
+	{"not", funcType(&ast.BoolType{}, &ast.BoolType{}), anyMode(func(arg values.Value) (values.Value, error) {
+		boolArg, ok := arg.(*values.BoolValue)
+		if !ok {
+			return nil, errors.New("type mismatch: expected Bool")
+		}
+		return &values.BoolValue{Value: !boolArg.Value}, nil
+	})},
+	// Comparison operations
+	{"eq", comparisonType(), intComparison(func(c int) bool { return c == 0 })},
+	{"ne", comparisonType(), intComparison(func(c int) bool { return c != 0 })},
+	{"lt", comparisonType(), intComparison(func(c int) bool { return c < 0 })},
+	{"le", comparisonType(), intComparison(func(c int) bool { return c <= 0 })},
+	{"gt", comparisonType(), intComparison(func(c int) bool { return c > 0 })},
+	{"ge", comparisonType(), intComparison(func(c int) bool { return c >= 0 })},
+	// String operations
+	{"concat", funcType(&ast.StringType{}, &ast.StringType{}, &ast.StringType{}), binaryOp(func(a, b *values.StringValue) *values.StringValue {
+		return &values.StringValue{Value: a.Value + b.Value}
+	})},
+	{"length", funcType(&ast.StringType{}, &ast.IntType{}), modeDependent(func(mode IntMode) func(values.Value) (values.Value, error) {
+		return func(arg values.Value) (values.Value, error) {
+			s, ok := arg.(*values.StringValue)
+			if !ok {
+				return nil, errors.New("type mismatch: expected String")
+			}
+			return newInt(mode, utf8.RuneCountInString(s.Value)), nil
+		}
+	})},
+	{"substr", funcType(&ast.StringType{}, &ast.IntType{}, &ast.IntType{}, &ast.StringType{}), anyMode(func(arg values.Value) (values.Value, error) {
+		s, ok := arg.(*values.StringValue)
+		if !ok {
+			return nil, errors.New("type mismatch: expected String")
+		}
+		return &values.PartialBuiltinFunc{
+			Name:       "substr",
+			ParamType:  &ast.IntType{},
+			ReturnType: funcType(&ast.IntType{}, &ast.StringType{}),
+			Fn: func(startArg values.Value) (values.Value, error) {
+				return &values.PartialBuiltinFunc{
+					Name:       "substr",
+					ParamType:  &ast.IntType{},
+					ReturnType: &ast.StringType{},
+					Fn: func(nArg values.Value) (values.Value, error) {
+						start, err := intArg(startArg)
+						if err != nil {
+							return nil, err
+						}
+						n, err := intArg(nArg)
+						if err != nil {
+							return nil, err
+						}
+						sub, err := substr(s.Value, start, n)
+						if err != nil {
+							return nil, err
+						}
+						return &values.StringValue{Value: sub}, nil
+					},
+				}, nil
+			},
+		}, nil
+	})},
+	{"streq", funcType(&ast.StringType{}, &ast.StringType{}, &ast.BoolType{}), binaryOp(func(a, b *values.StringValue) *values.BoolValue {
+		return &values.BoolValue{Value: a.Value == b.Value}
+	})},
+	// Character operations
+	{"ord", funcType(&ast.CharType{}, &ast.IntType{}), modeDependent(func(mode IntMode) func(values.Value) (values.Value, error) {
+		return func(arg values.Value) (values.Value, error) {
+			c, ok := arg.(*values.CharValue)
+			if !ok {
+				return nil, errors.New("type mismatch: expected Char")
+			}
+			return newInt(mode, int(c.Value)), nil
+		}
+	})},
+	{"chr", funcType(&ast.IntType{}, &ast.CharType{}), anyMode(func(arg values.Value) (values.Value, error) {
+		code, err := intArg(arg)
+		if err != nil && !errors.Is(err, ErrOverflow) {
+			return nil, err
+		}
+		if err != nil || code > unicode.MaxRune || !utf8.ValidRune(rune(code)) {
+			return nil, fmt.Errorf("invalid character code: %v", arg)
+		}
+		return &values.CharValue{Value: rune(code)}, nil
+	})},
+	// Float operations
+	{"fadd", floatOpType(), floatOp(func(a, b float64) float64 { return a + b })},
+	{"fsub", floatOpType(), floatOp(func(a, b float64) float64 { return a - b })},
+	{"fmul", floatOpType(), floatOp(func(a, b float64) float64 { return a * b })},
+	{"fdiv", floatOpType(), floatOp(func(a, b float64) float64 { return a / b })},
+	{"fneg", funcType(&ast.FloatType{}, &ast.FloatType{}), anyMode(func(arg values.Value) (values.Value, error) {
+		f, ok := arg.(*values.FloatValue)
+		if !ok {
+			return nil, errors.New("type mismatch: expected Float")
+		}
+		return &values.FloatValue{Value: -f.Value}, nil
+	})},
+	{"feq", floatComparisonType(), floatComparison(func(a, b float64) bool { return a == b })},
+	{"flt", floatComparisonType(), floatComparison(func(a, b float64) bool { return a < b })},
+	{"fle", floatComparisonType(), floatComparison(func(a, b float64) bool { return a <= b })},
+	{"itof", funcType(&ast.IntType{}, &ast.FloatType{}), anyMode(func(arg values.Value) (values.Value, error) {
+		switch arg := arg.(type) {
+		case *values.IntValue:
+			return &values.FloatValue{Value: float64(arg.Value)}, nil
+		case *values.BigIntValue:
+			f, _ := new(big.Float).SetInt(arg.Value).Float64()
+			return &values.FloatValue{Value: f}, nil
+		default:
+			return nil, errors.New("type mismatch: expected Int")
+		}
+	})},
+	{"ftoi", funcType(&ast.FloatType{}, &ast.IntType{}), modeDependent(func(mode IntMode) func(values.Value) (values.Value, error) {
+		return func(arg values.Value) (values.Value, error) {
+			f, ok := arg.(*values.FloatValue)
+			if !ok {
+				return nil, errors.New("type mismatch: expected Float")
+			}
+			// The conversion truncates toward zero and fails for infinities and NaN,
+			// and for floats out of the range of 64-bit integers unless integers are arbitrary-precision
+			if math.IsInf(f.Value, 0) || math.IsNaN(f.Value) {
+				return nil, ErrOverflow
+			}
+			truncated := math.Trunc(f.Value)
+			if mode == IntBig {
+				n, _ := new(big.Float).SetFloat64(truncated).Int(nil)
+				return &values.BigIntValue{Value: n}, nil
+			}
+			if truncated < math.MinInt64 || truncated >= math.MaxInt64 {
+				return nil, ErrOverflow
+			}
+			return &values.IntValue{Value: int(truncated)}, nil
+		}
+	})},
+	// List operations
+	// Named type variables are implicitly quantified for each use.
+	{"cons", funcType(typeVar("a"), listType(typeVar("a")), listType(typeVar("a"))), anyMode(func(head values.Value) (values.Value, error) {
+		return &values.PartialBuiltinFunc{
+			Name:       "cons",
+			ParamType:  listType(typeVar("a")),
+			ReturnType: listType(typeVar("a")),
+			Fn: func(arg values.Value) (values.Value, error) {
+				tail, err := listArg(arg)
+				if err != nil {
+					return nil, err
+				}
+				return values.Cons(head, tail), nil
+			},
+		}, nil
+	})},
+	{"isnil", funcType(listType(typeVar("a")), &ast.BoolType{}), anyMode(func(arg values.Value) (values.Value, error) {
+		list, err := listArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &values.BoolValue{Value: list.Empty()}, nil
+	})},
+	{"head", funcType(listType(typeVar("a")), typeVar("a")), anyMode(func(arg values.Value) (values.Value, error) {
+		list, err := nonEmpty("head", arg)
+		if err != nil {
+			return nil, err
+		}
+		return list.Head, nil
+	})},
+	{"tail", funcType(listType(typeVar("a")), listType(typeVar("a"))), anyMode(func(arg values.Value) (values.Value, error) {
+		list, err := nonEmpty("tail", arg)
+		if err != nil {
+			return nil, err
+		}
+		return list.Tail, nil
+	})},
+	{"foldr", funcType(funcType(typeVar("a"), typeVar("b"), typeVar("b")), typeVar("b"), listType(typeVar("a")), typeVar("b")), anyMode(fold("foldr", true))},
+	{"foldl", funcType(funcType(typeVar("b"), typeVar("a"), typeVar("b")), typeVar("b"), listType(typeVar("a")), typeVar("b")), anyMode(fold("foldl", false))},
+	// Polymorphic operations
+	{"choose", funcType(&ast.BoolType{}, &ast.TypeVar{Name: "a"}, &ast.TypeVar{Name: "a"}, &ast.TypeVar{Name: "a"}), anyMode(func(arg values.Value) (values.Value, error) {
+		cond, ok := arg.(*values.BoolValue)
+		if !ok {
+			return nil, errors.New("type mismatch: expected Bool")
+		}
+		return &values.PartialBuiltinFunc{
+			Name:      "choose",
+			ParamType: &ast.TypeVar{Name: "a"},
+			ReturnType: &ast.FuncType{
+				From: &ast.TypeVar{Name: "a"},
+				To:   &ast.TypeVar{Name: "a"},
+			},
+			Fn: func(x values.Value) (values.Value, error) {
+				return &values.PartialBuiltinFunc{
+					Name:       "choose",
+					ParamType:  &ast.TypeVar{Name: "a"},
+					ReturnType: &ast.TypeVar{Name: "a"},
+					Fn: func(y values.Value) (values.Value, error) {
+						if cond.Value {
+							return x, nil
+						}
+						return y, nil
+					},
+				}, nil
+			},
+		}, nil
+	})},
+	{"show", funcType(&ast.TypeVar{Name: "a"}, &ast.StringType{}), anyMode(func(arg values.Value) (values.Value, error) {
+		return &values.StringValue{Value: arg.String()}, nil
+	})},
+}
+
+// FunctionTypes maps the name of each builtin function to its type.
+var FunctionTypes = make(map[string]ast.Type, len(builtins))
+
+// modeFunctions maps the name of each builtin function to its value in each integer mode.
+var modeFunctions = make([]map[string]values.Value, len(intModeNames))
+
+// Functions maps the name of each builtin function to its value with wrapping integers.
+var Functions map[string]values.Value
+
+func init() {
+	for _, b := range builtins {
+		FunctionTypes[b.name] = b.typ
+	}
+	for mode := range modeFunctions {
+		functions := make(map[string]values.Value, len(builtins))
+		for _, b := range builtins {
+			functions[b.name] = &values.BuiltinFunc{
+				Name:       b.name,
+				ParamType:  b.typ.From,
+				ReturnType: b.typ.To,
+				Fn:         b.impl.fn(b.name, b.typ, IntMode(mode)),
+			}
+		}
+		modeFunctions[mode] = functions
+	}
+	Functions = modeFunctions[IntWrap]
+}
+
+// FunctionsFor returns the builtin functions by name with the integers of mode.
+func FunctionsFor(mode IntMode) map[string]values.Value {
+	return modeFunctions[mode]
 }
@@ -1,6 +1,10 @@
 package builtin
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/shota3506/gostlc/internal/ast"
@@ -173,6 +177,432 @@ func TestSubFunction(t *testing.T) {
 	}
 }
 
+func TestMulFunction(t *testing.T) {
+	mulFunc := Functions["mul"].(*values.BuiltinFunc)
+
+	tests := []struct {
+		name     string
+		arg1     values.Value
+		arg2     values.Value
+		expected int
+	}{
+		{
+			name:     "positive numbers",
+			arg1:     &values.IntValue{Value: 6},
+			arg2:     &values.IntValue{Value: 7},
+			expected: 42,
+		},
+		{
+			name:     "mixed sign",
+			arg1:     &values.IntValue{Value: -4},
+			arg2:     &values.IntValue{Value: 5},
+			expected: -20,
+		},
+		{
+			name:     "zero",
+			arg1:     &values.IntValue{Value: 0},
+			arg2:     &values.IntValue{Value: 42},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result1, err := mulFunc.Fn(tt.arg1)
+			if err != nil {
+				t.Fatalf("Unexpected error on first application: %v", err)
+			}
+
+			partialFunc, ok := result1.(*values.PartialBuiltinFunc)
+			if !ok {
+				t.Fatalf("First application did not return PartialBuiltinFunc")
+			}
+
+			result2, err := partialFunc.Fn(tt.arg2)
+			if err != nil {
+				t.Fatalf("Unexpected error on second application: %v", err)
+			}
+
+			intResult, ok := result2.(*values.IntValue)
+			if !ok {
+				t.Fatalf("Result is not IntValue")
+			}
+
+			if intResult.Value != tt.expected {
+				t.Errorf("mul(%d, %d) = %d, expected %d",
+					tt.arg1.(*values.IntValue).Value,
+					tt.arg2.(*values.IntValue).Value,
+					intResult.Value,
+					tt.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestDivModFunctions(t *testing.T) {
+	tests := []struct {
+		name          string
+		function      string
+		arg1          int
+		arg2          int
+		expected      int
+		expectedError string
+	}{
+		{
+			name:     "div truncates toward zero",
+			function: "div",
+			arg1:     -7,
+			arg2:     2,
+			expected: -3,
+		},
+		{
+			name:     "mod has the sign of the dividend",
+			function: "mod",
+			arg1:     -7,
+			arg2:     2,
+			expected: -1,
+		},
+		{
+			name:          "div by zero",
+			function:      "div",
+			arg1:          1,
+			arg2:          0,
+			expectedError: "division by zero",
+		},
+		{
+			name:          "mod by zero",
+			function:      "mod",
+			arg1:          1,
+			arg2:          0,
+			expectedError: "division by zero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := Functions[tt.function].(*values.BuiltinFunc)
+			result1, err := fn.Fn(&values.IntValue{Value: tt.arg1})
+			if err != nil {
+				t.Fatalf("Unexpected error on first application: %v", err)
+			}
+
+			result2, err := result1.(*values.PartialBuiltinFunc).Fn(&values.IntValue{Value: tt.arg2})
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error on second application: %v", err)
+			}
+
+			if got := result2.(*values.IntValue).Value; got != tt.expected {
+				t.Errorf("%s(%d, %d) = %d, expected %d", tt.function, tt.arg1, tt.arg2, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNegFunction(t *testing.T) {
+	negFunc := Functions["neg"].(*values.BuiltinFunc)
+
+	for _, n := range []int{0, 5, -5} {
+		result, err := negFunc.Fn(&values.IntValue{Value: n})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := result.(*values.IntValue).Value; got != -n {
+			t.Errorf("neg(%d) = %d, expected %d", n, got, -n)
+		}
+	}
+}
+
+func TestIntUnaryFunctions(t *testing.T) {
+	tests := []struct {
+		function string
+		arg      int
+		expected int
+	}{
+		{"abs", -5, 5},
+		{"abs", 5, 5},
+		{"abs", 0, 0},
+		{"bnot", 0, -1},
+		{"bnot", 5, -6},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s(%d)", tt.function, tt.arg), func(t *testing.T) {
+			fn := Functions[tt.function].(*values.BuiltinFunc)
+			result, err := fn.Fn(&values.IntValue{Value: tt.arg})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.(*values.IntValue).Value; got != tt.expected {
+				t.Errorf("%s(%d) = %d, expected %d", tt.function, tt.arg, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIntBinaryFunctions(t *testing.T) {
+	tests := []struct {
+		function      string
+		arg1          int
+		arg2          int
+		expected      int
+		expectedError error
+	}{
+		{function: "min", arg1: 3, arg2: -2, expected: -2},
+		{function: "max", arg1: 3, arg2: -2, expected: 3},
+		{function: "pow", arg1: 2, arg2: 10, expected: 1024},
+		{function: "pow", arg1: -3, arg2: 3, expected: -27},
+		{function: "pow", arg1: 7, arg2: 0, expected: 1},
+		{function: "pow", arg1: 2, arg2: -1, expectedError: ErrNegativeExponent},
+		{function: "band", arg1: 12, arg2: 10, expected: 8},
+		{function: "bor", arg1: 12, arg2: 10, expected: 14},
+		{function: "bxor", arg1: 12, arg2: 10, expected: 6},
+		{function: "shl", arg1: 1, arg2: 4, expected: 16},
+		{function: "shr", arg1: 16, arg2: 2, expected: 4},
+		{function: "shr", arg1: -16, arg2: 2, expected: -4},
+		{function: "shl", arg1: 1, arg2: -1, expectedError: ErrNegativeShift},
+		{function: "shr", arg1: 1, arg2: -1, expectedError: ErrNegativeShift},
+		{function: "div", arg1: 1, arg2: 0, expectedError: ErrDivisionByZero},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s(%d, %d)", tt.function, tt.arg1, tt.arg2), func(t *testing.T) {
+			fn := Functions[tt.function].(*values.BuiltinFunc)
+			result1, err := fn.Fn(&values.IntValue{Value: tt.arg1})
+			if err != nil {
+				t.Fatalf("Unexpected error on first application: %v", err)
+			}
+
+			result2, err := result1.(*values.PartialBuiltinFunc).Fn(&values.IntValue{Value: tt.arg2})
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error on second application: %v", err)
+			}
+
+			if got := result2.(*values.IntValue).Value; got != tt.expected {
+				t.Errorf("%s(%d, %d) = %d, expected %d", tt.function, tt.arg1, tt.arg2, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPartialApplication(t *testing.T) {
+	tests := []struct {
+		function string
+		arg      values.Value
+		expected string
+	}{
+		{function: "add", arg: &values.IntValue{Value: 1}, expected: "<builtin:add[partial]:Int->Int>"},
+		{function: "pow", arg: &values.IntValue{Value: 2}, expected: "<builtin:pow[partial]:Int->Int>"},
+		{function: "eq", arg: &values.IntValue{Value: 1}, expected: "<builtin:eq[partial]:Int->Bool>"},
+		{function: "fadd", arg: &values.FloatValue{Value: 1}, expected: "<builtin:fadd[partial]:Float->Float>"},
+		{function: "concat", arg: &values.StringValue{Value: "a"}, expected: "<builtin:concat[partial]:String->String>"},
+		{function: "streq", arg: &values.StringValue{Value: "a"}, expected: "<builtin:streq[partial]:String->Bool>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			result, err := apply(tt.function, tt.arg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.String(); got != tt.expected {
+				t.Errorf("%s %s = %s, expected %s", tt.function, tt.arg, got, tt.expected)
+			}
+		})
+	}
+
+	// The partial applications of integer operations are named in every mode
+	fn := FunctionsFor(IntBig)["add"].(*values.BuiltinFunc)
+	result, err := fn.Fn(&values.BigIntValue{Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, expected := result.String(), "<builtin:add[partial]:Int->Int>"; got != expected {
+		t.Errorf("add 1 = %s, expected %s", got, expected)
+	}
+}
+
+// apply applies the builtin function name to args in turn.
+func apply(name string, args ...values.Value) (values.Value, error) {
+	var fn values.Value = Functions[name]
+	for _, arg := range args {
+		var err error
+		switch f := fn.(type) {
+		case *values.BuiltinFunc:
+			fn, err = f.Fn(arg)
+		case *values.PartialBuiltinFunc:
+			fn, err = f.Fn(arg)
+		default:
+			return nil, fmt.Errorf("%s applied to too many arguments", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fn, nil
+}
+
+func TestTextAndFloatFunctions(t *testing.T) {
+	str := func(s string) values.Value { return &values.StringValue{Value: s} }
+	integer := func(n int) values.Value { return &values.IntValue{Value: n} }
+	float := func(f float64) values.Value { return &values.FloatValue{Value: f} }
+
+	tests := []struct {
+		function      string
+		args          []values.Value
+		expected      string
+		expectedError string
+	}{
+		{function: "concat", args: []values.Value{str("ab"), str("cd")}, expected: `"abcd"`},
+		{function: "length", args: []values.Value{str("λx")}, expected: "2"},
+		{function: "substr", args: []values.Value{str("λxyz"), integer(1), integer(2)}, expected: `"xy"`},
+		{function: "substr", args: []values.Value{str("abc"), integer(3), integer(0)}, expected: `""`},
+		{function: "substr", args: []values.Value{str("abc"), integer(-1), integer(2)}, expectedError: "substring out of range: 2 characters from -1 in a string of length 3"},
+		{function: "streq", args: []values.Value{str("a"), str("b")}, expected: "false"},
+		{function: "ord", args: []values.Value{&values.CharValue{Value: 'λ'}}, expected: "955"},
+		{function: "chr", args: []values.Value{integer(10)}, expected: `'\n'`},
+		{function: "chr", args: []values.Value{integer(0xD800)}, expectedError: "invalid character code: 55296"},
+		{function: "fadd", args: []values.Value{float(0.5), float(0.25)}, expected: "0.75"},
+		{function: "fsub", args: []values.Value{float(0.5), float(0.25)}, expected: "0.25"},
+		{function: "fmul", args: []values.Value{float(0.5), float(4)}, expected: "2.0"},
+		{function: "fdiv", args: []values.Value{float(1), float(0)}, expected: "+Inf"},
+		{function: "feq", args: []values.Value{float(0.5), float(0.5)}, expected: "true"},
+		{function: "fle", args: []values.Value{float(1), float(0.5)}, expected: "false"},
+		{function: "itof", args: []values.Value{integer(-3)}, expected: "-3.0"},
+		{function: "ftoi", args: []values.Value{float(-3.75)}, expected: "-3"},
+		{function: "ftoi", args: []values.Value{float(1e19)}, expectedError: "integer overflow"},
+		{function: "show", args: []values.Value{&values.TupleValue{Elems: []values.Value{str("a"), &values.UnitValue{}}}}, expected: `"(\"a\", ())"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s%v", tt.function, tt.args), func(t *testing.T) {
+			result, err := apply(tt.function, tt.args...)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("%s%v = %s, expected %s", tt.function, tt.args, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListFunctions(t *testing.T) {
+	integer := func(n int) values.Value { return &values.IntValue{Value: n} }
+	list := func(ns ...int) values.Value {
+		elems := make([]values.Value, len(ns))
+		for i, n := range ns {
+			elems[i] = integer(n)
+		}
+		return values.NewList(elems...)
+	}
+
+	tests := []struct {
+		function      string
+		args          []values.Value
+		expected      string
+		expectedError string
+	}{
+		{function: "cons", args: []values.Value{integer(1), list(2, 3)}, expected: "[1, 2, 3]"},
+		{function: "cons", args: []values.Value{integer(1), list()}, expected: "[1]"},
+		{function: "isnil", args: []values.Value{list()}, expected: "true"},
+		{function: "isnil", args: []values.Value{list(1)}, expected: "false"},
+		{function: "head", args: []values.Value{list(1, 2)}, expected: "1"},
+		{function: "head", args: []values.Value{list()}, expectedError: "head of empty list"},
+		{function: "tail", args: []values.Value{list(1, 2)}, expected: "[2]"},
+		{function: "tail", args: []values.Value{list()}, expectedError: "tail of empty list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s%v", tt.function, tt.args), func(t *testing.T) {
+			result, err := apply(tt.function, tt.args...)
+			if tt.expectedError != "" {
+				if !errors.Is(err, ErrEmptyList) || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("%s%v = %s, expected %s", tt.function, tt.args, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFoldFunctions(t *testing.T) {
+	f := Functions["sub"]
+	z := &values.IntValue{Value: 0}
+	xs := values.NewList(&values.IntValue{Value: 1}, &values.IntValue{Value: 2})
+
+	tests := []struct {
+		function string
+		expected string
+	}{
+		// foldr f z [1, 2] = f 1 (f 2 z)
+		{function: "foldr", expected: "sub(1, sub(2, 0))"},
+		// foldl f z [1, 2] = f (f z 1) 2
+		{function: "foldl", expected: "sub(sub(0, 1), 2)"},
+	}
+
+	// format writes a call as the application of the name of its builtin function to its arguments.
+	var format func(v values.Value) string
+	format = func(v values.Value) string {
+		call, ok := v.(*values.Call)
+		if !ok {
+			return v.String()
+		}
+		args := make([]string, len(call.Args))
+		for i, arg := range call.Args {
+			args[i] = format(arg)
+		}
+		return fmt.Sprintf("%s(%s)", call.Fn.(*values.BuiltinFunc).Name, strings.Join(args, ", "))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			result, err := apply(tt.function, f, z, xs)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, ok := result.(*values.Call); !ok {
+				t.Fatalf("Result is not Call")
+			}
+			if actual := format(result); actual != tt.expected {
+				t.Errorf("%s sub 0 [1, 2] = %s, expected %s", tt.function, actual, tt.expected)
+			}
+		})
+	}
+
+	result, err := apply("foldr", f, z, values.EmptyList)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != z {
+		t.Errorf("foldr sub 0 [] = %s, expected 0", result)
+	}
+}
+
 func TestAndFunction(t *testing.T) {
 	andFunc := Functions["and"].(*values.BuiltinFunc)
 
@@ -311,6 +741,60 @@ func TestOrFunction(t *testing.T) {
 	}
 }
 
+func TestShortCircuitFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg1     bool
+		arg2     bool
+		forced   bool
+		expected bool
+	}{
+		{name: "and", arg1: false, arg2: true, forced: false, expected: false},
+		{name: "and", arg1: true, arg2: false, forced: true, expected: false},
+		{name: "or", arg1: true, arg2: false, forced: false, expected: true},
+		{name: "or", arg1: false, arg2: true, forced: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s(%v, %v)", tt.name, tt.arg1, tt.arg2), func(t *testing.T) {
+			fn := Functions[tt.name].(*values.BuiltinFunc)
+			result1, err := fn.Fn(&values.BoolValue{Value: tt.arg1})
+			if err != nil {
+				t.Fatalf("Unexpected error on first application: %v", err)
+			}
+
+			partialFunc, ok := result1.(*values.PartialBuiltinFunc)
+			if !ok {
+				t.Fatalf("First application did not return PartialBuiltinFunc")
+			}
+			if !partialFunc.Lazy {
+				t.Fatalf("Second parameter is not lazy")
+			}
+
+			forced := false
+			thunk := &values.Thunk{Eval: func() (values.Value, error) {
+				forced = true
+				return &values.BoolValue{Value: tt.arg2}, nil
+			}}
+			result2, err := partialFunc.Fn(thunk)
+			if err != nil {
+				t.Fatalf("Unexpected error on second application: %v", err)
+			}
+
+			boolResult, ok := result2.(*values.BoolValue)
+			if !ok {
+				t.Fatalf("Result is not BoolValue")
+			}
+			if boolResult.Value != tt.expected {
+				t.Errorf("result = %v, expected %v", boolResult.Value, tt.expected)
+			}
+			if forced != tt.forced {
+				t.Errorf("second argument forced = %v, expected %v", forced, tt.forced)
+			}
+		})
+	}
+}
+
 func TestNotFunction(t *testing.T) {
 	notFunc := Functions["not"].(*values.BuiltinFunc)
 
@@ -863,3 +1347,64 @@ func TestGeFunction(t *testing.T) {
 		})
 	}
 }
+
+func TestChooseFunction(t *testing.T) {
+	chooseFunc := Functions["choose"].(*values.BuiltinFunc)
+
+	tests := []struct {
+		name     string
+		cond     bool
+		arg1     values.Value
+		arg2     values.Value
+		expected values.Value
+	}{
+		{
+			name:     "true chooses first",
+			cond:     true,
+			arg1:     &values.IntValue{Value: 1},
+			arg2:     &values.IntValue{Value: 2},
+			expected: &values.IntValue{Value: 1},
+		},
+		{
+			name:     "false chooses second",
+			cond:     false,
+			arg1:     &values.BoolValue{Value: true},
+			arg2:     &values.BoolValue{Value: false},
+			expected: &values.BoolValue{Value: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result1, err := chooseFunc.Fn(&values.BoolValue{Value: tt.cond})
+			if err != nil {
+				t.Fatalf("Unexpected error on first application: %v", err)
+			}
+
+			partialFunc1, ok := result1.(*values.PartialBuiltinFunc)
+			if !ok {
+				t.Fatalf("First application did not return PartialBuiltinFunc")
+			}
+
+			result2, err := partialFunc1.Fn(tt.arg1)
+			if err != nil {
+				t.Fatalf("Unexpected error on second application: %v", err)
+			}
+
+			partialFunc2, ok := result2.(*values.PartialBuiltinFunc)
+			if !ok {
+				t.Fatalf("Second application did not return PartialBuiltinFunc")
+			}
+
+			result3, err := partialFunc2.Fn(tt.arg2)
+			if err != nil {
+				t.Fatalf("Unexpected error on third application: %v", err)
+			}
+
+			if result3.String() != tt.expected.String() {
+				t.Errorf("choose(%v, %v, %v) = %v, expected %v",
+					tt.cond, tt.arg1, tt.arg2, result3, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package builtin
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/values"
+)
+
+// IntMode is the semantics of integers.
+type IntMode int
+
+const (
+	// IntWrap is 64-bit integers whose operations wrap around on overflow.
+	IntWrap IntMode = iota
+	// IntChecked is 64-bit integers whose operations fail with ErrOverflow on overflow.
+	IntChecked
+	// IntBig is arbitrary-precision integers, represented by values.BigIntValue.
+	IntBig
+)
+
+var intModeNames = []string{
+	IntWrap:    "wrap",
+	IntChecked: "checked",
+	IntBig:     "big",
+}
+
+func (m IntMode) String() string {
+	return intModeNames[m]
+}
+
+// ParseIntMode returns the integer mode named s: wrap, checked or big.
+func ParseIntMode(s string) (IntMode, error) {
+	for m, name := range intModeNames {
+		if name == s {
+			return IntMode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown integer mode: %s", s)
+}
+
+// ErrOverflow is returned by integer operations whose result is out of the range of 64-bit integers
+// in the checked mode.
+var ErrOverflow = errors.New("integer overflow")
+
+// implementation is the implementation of a builtin function in each integer mode.
+// It is given the name and the type of the builtin function.
+type implementation interface {
+	fn(name string, typ *ast.FuncType, mode IntMode) func(arg values.Value) (values.Value, error)
+}
+
+// anyMode is the implementation of a builtin function that does not depend on the integer mode.
+type anyMode func(arg values.Value) (values.Value, error)
+
+func (f anyMode) fn(string, *ast.FuncType, IntMode) func(arg values.Value) (values.Value, error) {
+	return f
+}
+
+// modeDependent is the implementation of a builtin function that takes or returns integers
+// but is not an integer operation.
+type modeDependent func(mode IntMode) func(arg values.Value) (values.Value, error)
+
+func (f modeDependent) fn(_ string, _ *ast.FuncType, mode IntMode) func(arg values.Value) (values.Value, error) {
+	return f(mode)
+}
+
+// newInt returns the integer n in mode.
+func newInt(mode IntMode, n int) values.Value {
+	if mode == IntBig {
+		return &values.BigIntValue{Value: big.NewInt(int64(n))}
+	}
+	return &values.IntValue{Value: n}
+}
+
+// intArg returns the value of an integer argument in any mode,
+// failing with ErrOverflow if it is out of the range of 64-bit integers.
+func intArg(arg values.Value) (int, error) {
+	switch arg := arg.(type) {
+	case *values.IntValue:
+		return arg.Value, nil
+	case *values.BigIntValue:
+		if !arg.Value.IsInt64() {
+			return 0, ErrOverflow
+		}
+		return int(arg.Value.Int64()), nil
+	default:
+		return 0, errors.New("type mismatch: expected Int")
+	}
+}
+
+// intUnary is an operation on an integer.
+// The 64-bit operation returns its result wrapped around and whether it overflowed.
+type intUnary struct {
+	fixed func(a int) (int, bool)
+	big   func(a *big.Int) *big.Int
+}
+
+func (op intUnary) fn(_ string, _ *ast.FuncType, mode IntMode) func(arg values.Value) (values.Value, error) {
+	if mode == IntBig {
+		return func(arg values.Value) (values.Value, error) {
+			bigArg, ok := arg.(*values.BigIntValue)
+			if !ok {
+				return nil, errors.New("type mismatch: expected Int")
+			}
+			return &values.BigIntValue{Value: op.big(bigArg.Value)}, nil
+		}
+	}
+	return func(arg values.Value) (values.Value, error) {
+		intArg, ok := arg.(*values.IntValue)
+		if !ok {
+			return nil, errors.New("type mismatch: expected Int")
+		}
+		result, overflow := op.fixed(intArg.Value)
+		if overflow && mode == IntChecked {
+			return nil, ErrOverflow
+		}
+		return &values.IntValue{Value: result}, nil
+	}
+}
+
+// intBinary is an operation on two integers, which may be undefined for some arguments.
+// The 64-bit operation returns its result wrapped around and whether it overflowed.
+type intBinary struct {
+	fixed func(a, b int) (int, bool, error)
+	big   func(a, b *big.Int) (*big.Int, error)
+}
+
+func (op intBinary) fn(name string, typ *ast.FuncType, mode IntMode) func(arg values.Value) (values.Value, error) {
+	if mode == IntBig {
+		return partialBinaryOp(func(a, b *values.BigIntValue) (*values.BigIntValue, error) {
+			result, err := op.big(a.Value, b.Value)
+			if err != nil {
+				return nil, err
+			}
+			return &values.BigIntValue{Value: result}, nil
+		})(name, typ)
+	}
+	return partialBinaryOp(func(a, b *values.IntValue) (*values.IntValue, error) {
+		result, overflow, err := op.fixed(a.Value, b.Value)
+		if err != nil {
+			return nil, err
+		}
+		if overflow && mode == IntChecked {
+			return nil, ErrOverflow
+		}
+		return &values.IntValue{Value: result}, nil
+	})(name, typ)
+}
+
+// intComparison compares two integers, holding for the results of the comparison it accepts.
+type intComparison func(c int) bool
+
+func (f intComparison) fn(name string, typ *ast.FuncType, mode IntMode) func(arg values.Value) (values.Value, error) {
+	if mode == IntBig {
+		return binaryOp(func(a, b *values.BigIntValue) *values.BoolValue {
+			return &values.BoolValue{Value: f(a.Value.Cmp(b.Value))}
+		})(name, typ)
+	}
+	return binaryOp(func(a, b *values.IntValue) *values.BoolValue {
+		return &values.BoolValue{Value: f(cmp.Compare(a.Value, b.Value))}
+	})(name, typ)
+}
+
+// exact returns the result of a 64-bit operation that never overflows.
+func exact(f func(a, b int) int) func(a, b int) (int, bool, error) {
+	return func(a, b int) (int, bool, error) {
+		return f(a, b), false, nil
+	}
+}
+
+// exactBig returns the result of an arbitrary-precision operation that is always defined.
+func exactBig(f func(z, a, b *big.Int) *big.Int) func(a, b *big.Int) (*big.Int, error) {
+	return func(a, b *big.Int) (*big.Int, error) {
+		return f(new(big.Int), a, b), nil
+	}
+}
+
+func addOverflow(a, b int) (int, bool) {
+	c := a + b
+	return c, (b > 0 && c < a) || (b < 0 && c > a)
+}
+
+func subOverflow(a, b int) (int, bool) {
+	c := a - b
+	return c, (b > 0 && c > a) || (b < 0 && c < a)
+}
+
+func mulOverflow(a, b int) (int, bool) {
+	c := a * b
+	return c, a != 0 && (c/a != b || (a == -1 && b == math.MinInt))
+}
+
+// powOverflow raises a to the non-negative power n by squaring.
+func powOverflow(a, n int) (int, bool) {
+	result, base, overflow := 1, a, false
+	for ; n > 0; n >>= 1 {
+		var o bool
+		if n&1 == 1 {
+			result, o = mulOverflow(result, base)
+			overflow = overflow || o
+		}
+		// The base is squared only if it is used again
+		if n > 1 {
+			base, o = mulOverflow(base, base)
+			overflow = overflow || o
+		}
+	}
+	return result, overflow
+}
+
+// bigShift returns the shift count b, which must fit in a uint.
+func bigShift(b *big.Int) (uint, error) {
+	if b.Sign() < 0 {
+		return 0, ErrNegativeShift
+	}
+	if !b.IsUint64() || b.Uint64() > math.MaxUint {
+		return 0, ErrOverflow
+	}
+	return uint(b.Uint64()), nil
+}
@@ -0,0 +1,137 @@
+// Package diagnostics converts the errors found in source code into diagnostics
+// and renders them with the source lines they refer to.
+package diagnostics
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/token"
+	"github.com/shota3506/gostlc/internal/types"
+)
+
+// Diagnostic is an error or warning located in the source code.
+type Diagnostic struct {
+	// Span is the primary location of the error, or the zero Span if the error has no location.
+	Span    token.Span
+	Message string
+	// Labels are secondary locations explaining the error.
+	Labels []Label
+	// Err is the error the diagnostic was created from.
+	Err error
+	// Warning reports that the diagnostic is a warning, which does not prevent the program from running.
+	Warning bool
+}
+
+// Label is a message attached to a span of the source code.
+type Label struct {
+	Span    token.Span
+	Message string
+}
+
+// FromError converts err into diagnostics sorted by position.
+// Lists of errors, such as those returned by the parser and the type checker, are flattened.
+func FromError(err error) []Diagnostic {
+	var diags []Diagnostic
+	for _, err := range flatten(err) {
+		diags = append(diags, fromError(err))
+	}
+	slices.SortStableFunc(diags, func(a, b Diagnostic) int {
+		switch {
+		case a.Span.Start.Before(b.Span.Start):
+			return -1
+		case b.Span.Start.Before(a.Span.Start):
+			return 1
+		default:
+			return 0
+		}
+	})
+	return diags
+}
+
+// FromWarnings converts warnings, such as those of a type-checked program, into diagnostics sorted by position.
+func FromWarnings(warnings []error) []Diagnostic {
+	diags := FromError(types.ErrorList(warnings))
+	for i := range diags {
+		diags[i].Warning = true
+	}
+	return diags
+}
+
+func flatten(err error) []error {
+	list, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, err := range list.Unwrap() {
+		errs = append(errs, flatten(err)...)
+	}
+	return errs
+}
+
+func fromError(err error) Diagnostic {
+	e, ok := err.(interface{ Span() token.Span })
+	if !ok {
+		return Diagnostic{Message: err.Error(), Err: err}
+	}
+
+	// The location is rendered separately from the message
+	span := e.Span()
+	prefix := fmt.Sprintf("%d:%d: ", span.Start.Line, span.Start.Column)
+	return Diagnostic{
+		Span:    span,
+		Message: strings.TrimPrefix(err.Error(), prefix),
+		Labels:  labels(err),
+		Err:     err,
+	}
+}
+
+func labels(err error) []Label {
+	switch e := err.(type) {
+	case *types.TypeMismatchError:
+		return mismatchLabels(e.Context, e.ExpectedSpan, e.Expected, e.ActualSpan, e.Actual)
+	case *types.UnificationError:
+		return mismatchLabels(e.Context, e.ExpectedSpan, e.Expected, e.ActualSpan, e.Actual)
+	default:
+		return nil
+	}
+}
+
+// mismatchRoles describes the two sides of a type mismatch in each context, the expected side first.
+var mismatchRoles = map[string][2]string{
+	"application":         {"function expects %s here", "argument is %s here"},
+	"if-else branches":    {"then branch is %s here", "else branch is %s here"},
+	"case branches":       {"first branch is %s here", "this branch is %s here"},
+	"let binding":         {"annotation expects %s here", "bound expression is %s here"},
+	"injection":           {"sum type expects %s here", "injected value is %s here"},
+	"variant":             {"variant type expects %s here", "variant value is %s here"},
+	"fold":                {"recursive type unfolds to %s here", "folded value is %s here"},
+	"unfold":              {"recursive type is %s here", "unfolded value is %s here"},
+	"match arms":          {"first arm is %s here", "this arm is %s here"},
+	"pattern":             {"scrutinee is %s here", "pattern is %s here"},
+	"constructor pattern": {"constructor expects %s here", "argument pattern is %s here"},
+}
+
+func mismatchLabels(context string, expectedSpan token.Span, expected ast.Type, actualSpan token.Span, actual ast.Type) []Label {
+	roles, ok := mismatchRoles[context]
+	if !ok {
+		roles = [2]string{"expected %s here", "found %s here"}
+	}
+
+	var labels []Label
+	if isValid(expectedSpan) {
+		labels = append(labels, Label{Span: expectedSpan, Message: fmt.Sprintf(roles[0], expected)})
+	}
+	if isValid(actualSpan) {
+		labels = append(labels, Label{Span: actualSpan, Message: fmt.Sprintf(roles[1], actual)})
+	}
+	return labels
+}
+
+// isValid reports whether span refers to a location in the source code.
+func isValid(span token.Span) bool {
+	return span.Start.Line > 0
+}
@@ -0,0 +1,279 @@
+package diagnostics_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shota3506/gostlc/internal/diagnostics"
+	"github.com/shota3506/gostlc/internal/parser"
+	"github.com/shota3506/gostlc/internal/token"
+	"github.com/shota3506/gostlc/internal/types"
+)
+
+func check(input string) error {
+	prog, parseErr := parser.ParseProgram(input)
+	if prog == nil {
+		return parseErr
+	}
+	_, checkErr := types.CheckProgram(prog)
+	return errors.Join(parseErr, checkErr)
+}
+
+func TestFromError(t *testing.T) {
+	// span returns the span between two positions, each given by its line, column and byte offset.
+	span := func(startLine, startColumn, startOffset, endLine, endColumn, endOffset int) token.Span {
+		return token.Span{
+			Start: token.Position{Offset: startOffset, Line: startLine, Column: startColumn},
+			End:   token.Position{Offset: endOffset, Line: endLine, Column: endColumn},
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected []diagnostics.Diagnostic
+	}{
+		{
+			name:  "Undefined variable",
+			input: `x`,
+			expected: []diagnostics.Diagnostic{
+				{Span: span(1, 1, 0, 1, 2, 1), Message: "undefined variable: x"},
+			},
+		},
+		{
+			name:  "Argument type mismatch",
+			input: "let f = \\x:Int. x\nf true",
+			expected: []diagnostics.Diagnostic{
+				{
+					Span:    span(2, 1, 18, 2, 7, 24),
+					Message: "type mismatch in application: expected Int, got Bool",
+					Labels: []diagnostics.Label{
+						{Span: span(2, 1, 18, 2, 2, 19), Message: "function expects Int here"},
+						{Span: span(2, 3, 20, 2, 7, 24), Message: "argument is Bool here"},
+					},
+				},
+			},
+		},
+		{
+			name:  "Branch type mismatch",
+			input: `if true then 1 else false`,
+			expected: []diagnostics.Diagnostic{
+				{
+					Span:    span(1, 1, 0, 1, 26, 25),
+					Message: "type mismatch in if-else branches: expected Int, got Bool",
+					Labels: []diagnostics.Label{
+						{Span: span(1, 14, 13, 1, 15, 14), Message: "then branch is Int here"},
+						{Span: span(1, 21, 20, 1, 26, 25), Message: "else branch is Bool here"},
+					},
+				},
+			},
+		},
+		{
+			name:  "Pattern type mismatch",
+			input: "type T = A | B Int\nmatch 1 with A -> 0 | _ -> 1",
+			expected: []diagnostics.Diagnostic{
+				{
+					Span:    span(2, 14, 32, 2, 15, 33),
+					Message: "type mismatch in pattern: expected Int, got T",
+					Labels: []diagnostics.Label{
+						{Span: span(2, 7, 25, 2, 8, 26), Message: "scrutinee is Int here"},
+						{Span: span(2, 14, 32, 2, 15, 33), Message: "pattern is T here"},
+					},
+				},
+			},
+		},
+		{
+			name:  "Syntax and type errors are sorted by position",
+			input: "let x = y\nlet z = )\nx",
+			expected: []diagnostics.Diagnostic{
+				{Span: span(1, 9, 8, 1, 10, 9), Message: "undefined variable: y"},
+				{Span: span(2, 9, 18, 2, 10, 19), Message: "unexpected token: RParen"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := diagnostics.FromError(check(tt.input))
+			if len(diags) != len(tt.expected) {
+				t.Fatalf("FromError() got %d diagnostics, want %d: %v", len(diags), len(tt.expected), diags)
+			}
+			for i, d := range diags {
+				want := tt.expected[i]
+				if d.Span != want.Span || d.Message != want.Message {
+					t.Errorf("FromError()[%d] = %v %q, want %v %q", i, d.Span, d.Message, want.Span, want.Message)
+				}
+				if len(d.Labels) != len(want.Labels) {
+					t.Fatalf("FromError()[%d] got labels %v, want %v", i, d.Labels, want.Labels)
+				}
+				for j, label := range d.Labels {
+					if label != want.Labels[j] {
+						t.Errorf("FromError()[%d].Labels[%d] = %v, want %v", i, j, label, want.Labels[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFromWarnings(t *testing.T) {
+	prog, err := parser.ParseProgram("type T = A | B\nmatch A with _ -> 0 | B -> 1")
+	if err != nil {
+		t.Fatalf("ParseProgram() error = %v", err)
+	}
+	typedProg, err := types.CheckProgram(prog)
+	if err != nil {
+		t.Fatalf("CheckProgram() error = %v", err)
+	}
+
+	diags := diagnostics.FromWarnings(typedProg.Warnings)
+	if len(diags) != 1 {
+		t.Fatalf("FromWarnings() got %d diagnostics, want 1: %v", len(diags), diags)
+	}
+	d := diags[0]
+	if !d.Warning || d.Message != "redundant match arm: the arms before it match every value it matches" {
+		t.Errorf("FromWarnings()[0] = %+v", d)
+	}
+
+	var b strings.Builder
+	r := &diagnostics.Renderer{Source: "type T = A | B\nmatch A with _ -> 0 | B -> 1"}
+	r.Render(&b, d)
+	want := `warning: redundant match arm: the arms before it match every value it matches
+ --> 2:23
+  |
+2 | match A with _ -> 0 | B -> 1
+  |                       ^^^^^^
+`
+	if b.String() != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		filename string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Labels",
+			filename: "main.stlc",
+			input:    "let f = \\x:Int. x\nf true",
+			expected: `error: type mismatch in application: expected Int, got Bool
+ --> main.stlc:2:1
+  |
+2 | f true
+  | ^^^^^^
+  | - function expects Int here
+  |   ---- argument is Bool here
+`,
+		},
+		{
+			name:  "Without file name",
+			input: `if 1 then 2 else 3`,
+			expected: `error: condition must be boolean, got Int
+ --> 1:1
+  |
+1 | if 1 then 2 else 3
+  | ^^^^^^^^^^^^^^^^^^
+`,
+		},
+		{
+			name:     "Labels on several lines",
+			filename: "main.stlc",
+			input:    "if true\nthen 1\nelse false",
+			expected: `error: type mismatch in if-else branches: expected Int, got Bool
+ --> main.stlc:1:1
+  |
+1 | if true
+  | ^^^^^^^
+2 | then 1
+  |      - then branch is Int here
+3 | else false
+  |      ----- else branch is Bool here
+`,
+		},
+		{
+			name:  "Empty span",
+			input: "\t(1, 2",
+			expected: "error: expected ')': EOF\n" +
+				" --> 1:7\n" +
+				"  |\n" +
+				"1 | \t(1, 2\n" +
+				"  | \t     ^\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &diagnostics.Renderer{Filename: tt.filename, Source: tt.input}
+			var b strings.Builder
+			for _, d := range diagnostics.FromError(check(tt.input)) {
+				r.Render(&b, d)
+			}
+			if b.String() != tt.expected {
+				t.Errorf("Render() =\n%s\nwant\n%s", b.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	r := &diagnostics.Renderer{Source: "x", Color: true}
+	var b strings.Builder
+	for _, d := range diagnostics.FromError(check("x")) {
+		r.Render(&b, d)
+	}
+	if !strings.Contains(b.String(), "\x1b[1;31m^\x1b[0m") {
+		t.Errorf("Render() = %q, want colored carets", b.String())
+	}
+}
+
+func TestReport(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Undefined variable",
+			input:    `x`,
+			expected: `{"kind":"UndefinedVariableError","file":"main.stlc","line":1,"column":1,"endLine":1,"endColumn":2,"message":"undefined variable: x","fields":{"Name":"x"}}`,
+		},
+		{
+			name:     "Type mismatch",
+			input:    "let f = \\x:Int. x\nf true",
+			expected: `{"kind":"TypeMismatchError","file":"main.stlc","line":2,"column":1,"endLine":2,"endColumn":7,"message":"type mismatch in application: expected Int, got Bool","fields":{"Actual":"Bool","Context":"application","Expected":"Int"},"labels":[{"line":2,"column":1,"endLine":2,"endColumn":2,"message":"function expects Int here"},{"line":2,"column":3,"endLine":2,"endColumn":7,"message":"argument is Bool here"}]}`,
+		},
+		{
+			name:     "Not a function",
+			input:    `1 2`,
+			expected: `{"kind":"NotAFunctionError","file":"main.stlc","line":1,"column":1,"endLine":1,"endColumn":4,"message":"cannot apply non-function type: Int","fields":{"Type":"Int"}}`,
+		},
+		{
+			name:     "Syntax error",
+			input:    `(1`,
+			expected: `{"kind":"ParseError","file":"main.stlc","line":1,"column":3,"endLine":1,"endColumn":3,"message":"expected ')': EOF"}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := diagnostics.FromError(check(tt.input))
+			if len(diags) != 1 {
+				t.Fatalf("FromError() got %d diagnostics, want 1", len(diags))
+			}
+			b, err := json.Marshal(diags[0].Report("main.stlc"))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(b) != tt.expected {
+				t.Errorf("Report() =\n%s\nwant\n%s", b, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReportWithoutLocation(t *testing.T) {
+	r := diagnostics.FromError(errors.New("too many arguments"))[0].Report("")
+	if r.Kind != "Error" || r.Message != "too many arguments" || r.Line != 0 || r.Fields != nil {
+		t.Errorf("Report() = %+v", r)
+	}
+}
@@ -0,0 +1,122 @@
+package diagnostics
+
+import (
+	"fmt"
+	"reflect"
+	"unicode"
+
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+// Report is the machine-readable form of a diagnostic, encoded as a JSON object.
+type Report struct {
+	// Kind is the name of the error type, as returned by Diagnostic.Kind.
+	Kind      string `json:"kind"`
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Message   string `json:"message"`
+	// Fields are the exported fields of the error other than its location and message, such as Expected and Actual.
+	// Types and wrapped errors are encoded as strings.
+	Fields map[string]any `json:"fields,omitempty"`
+	Labels []ReportLabel  `json:"labels,omitempty"`
+	// Warning reports that the diagnostic is a warning.
+	Warning bool `json:"warning,omitempty"`
+}
+
+// ReportLabel is the machine-readable form of a label.
+type ReportLabel struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Message   string `json:"message"`
+}
+
+// Report returns the machine-readable form of d found in the named file.
+func (d Diagnostic) Report(filename string) Report {
+	r := Report{
+		Kind:      d.Kind(),
+		File:      filename,
+		Line:      d.Span.Start.Line,
+		Column:    d.Span.Start.Column,
+		EndLine:   d.Span.End.Line,
+		EndColumn: d.Span.End.Column,
+		Message:   d.Message,
+		Warning:   d.Warning,
+	}
+	if d.Err != nil {
+		r.Fields = errorFields(d.Err)
+	}
+	for _, label := range d.Labels {
+		r.Labels = append(r.Labels, ReportLabel{
+			Line:      label.Span.Start.Line,
+			Column:    label.Span.Start.Column,
+			EndLine:   label.Span.End.Line,
+			EndColumn: label.Span.End.Column,
+			Message:   label.Message,
+		})
+	}
+	return r
+}
+
+// Kind returns the name of the type of the error d was created from, such as UndefinedVariableError,
+// or Error if the type has no exported name.
+func (d Diagnostic) Kind() string {
+	if d.Err == nil {
+		return "Error"
+	}
+	t := reflect.TypeOf(d.Err)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := []rune(t.Name())
+	if len(name) == 0 || !unicode.IsUpper(name[0]) {
+		return "Error"
+	}
+	return string(name)
+}
+
+var (
+	positionType = reflect.TypeFor[token.Position]()
+	spanType     = reflect.TypeFor[token.Span]()
+	stringerType = reflect.TypeFor[fmt.Stringer]()
+	errorType    = reflect.TypeFor[error]()
+)
+
+func errorFields(err error) map[string]any {
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]any)
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		// Locations and messages are already reported by the diagnostic itself
+		if !field.IsExported() || field.Type == positionType || field.Type == spanType || field.Name == "Message" {
+			continue
+		}
+
+		value := v.Field(i)
+		switch {
+		case (value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer) && value.IsNil():
+			fields[field.Name] = nil
+		case field.Type.Implements(errorType):
+			fields[field.Name] = value.Interface().(error).Error()
+		case field.Type.Implements(stringerType):
+			fields[field.Name] = value.Interface().(fmt.Stringer).String()
+		default:
+			fields[field.Name] = value.Interface()
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
@@ -0,0 +1,124 @@
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[1;31m"
+	colorYellow = "\x1b[1;33m"
+	colorBlue   = "\x1b[1;34m"
+)
+
+// Renderer renders diagnostics with the lines of the source code they refer to.
+//
+// A diagnostic is rendered as a header with its message and location, followed by
+// each line it refers to with the primary span underlined by carets and the spans
+// of the labels underlined by dashes:
+//
+//	error: type mismatch in application: expected Int, got Bool
+//	 --> main.stlc:2:1
+//	  |
+//	2 | f true
+//	  | ^^^^^^
+//	  | - function expects Int here
+//	  |   ---- argument is Bool here
+type Renderer struct {
+	// Filename is shown in the location of each diagnostic if not empty.
+	Filename string
+	// Source is the source code the positions of diagnostics refer to.
+	Source string
+	// Color enables ANSI escape sequences for colored output.
+	Color bool
+}
+
+// Render writes the diagnostic d to w.
+func (r *Renderer) Render(w io.Writer, d Diagnostic) {
+	severity, color := "error", colorRed
+	if d.Warning {
+		severity, color = "warning", colorYellow
+	}
+	fmt.Fprintf(w, "%s: %s\n", r.paint(color, severity), r.paint(colorBold, d.Message))
+	if !isValid(d.Span) {
+		return
+	}
+
+	location := fmt.Sprintf("%d:%d", d.Span.Start.Line, d.Span.Start.Column)
+	if r.Filename != "" {
+		location = r.Filename + ":" + location
+	}
+
+	lines := strings.Split(r.Source, "\n")
+	lineNums := []int{d.Span.Start.Line}
+	for _, label := range d.Labels {
+		if isValid(label.Span) && !slices.Contains(lineNums, label.Span.Start.Line) {
+			lineNums = append(lineNums, label.Span.Start.Line)
+		}
+	}
+	slices.Sort(lineNums)
+
+	width := len(strconv.Itoa(lineNums[len(lineNums)-1]))
+	gutter := strings.Repeat(" ", width)
+
+	fmt.Fprintf(w, "%s%s %s\n", gutter, r.paint(colorBlue, "-->"), location)
+	fmt.Fprintf(w, "%s %s\n", gutter, r.paint(colorBlue, "|"))
+	for _, lineNum := range lineNums {
+		if lineNum > len(lines) {
+			continue
+		}
+		line := strings.TrimSuffix(lines[lineNum-1], "\r")
+		fmt.Fprintf(w, "%s %s %s\n", r.paint(colorBlue, fmt.Sprintf("%*d", width, lineNum)), r.paint(colorBlue, "|"), line)
+
+		if d.Span.Start.Line == lineNum {
+			fmt.Fprintf(w, "%s %s %s\n", gutter, r.paint(colorBlue, "|"), r.paint(color, underline(line, d.Span, '^')))
+		}
+		for _, label := range d.Labels {
+			if label.Span.Start.Line == lineNum {
+				marks := underline(line, label.Span, '-') + " " + label.Message
+				fmt.Fprintf(w, "%s %s %s\n", gutter, r.paint(colorBlue, "|"), r.paint(colorBlue, marks))
+			}
+		}
+	}
+}
+
+func (r *Renderer) paint(color, s string) string {
+	if !r.Color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// underline returns marks under the part of line covered by span, which starts on that line.
+// A span continuing on later lines is underlined to the end of the line, and an empty span gets a single mark.
+func underline(line string, span token.Span, mark rune) string {
+	runes := []rune(line)
+	start := min(span.Start.Column-1, len(runes))
+
+	n := 1
+	switch {
+	case span.End.Line == span.Start.Line && span.End.Column > span.Start.Column:
+		n = span.End.Column - span.Start.Column
+	case span.End.Line > span.Start.Line:
+		n = max(len(runes)-start, 1)
+	}
+
+	// Keep tabs in the padding so that the marks line up with the source line
+	var b strings.Builder
+	for _, ch := range runes[:start] {
+		if ch == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	b.WriteString(strings.Repeat(string(mark), n))
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+// RuntimeError occurs when the evaluation of an expression fails.
+type RuntimeError struct {
+	Pos     token.Position
+	End     token.Position
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+// Span returns the span of the expression being evaluated.
+func (e *RuntimeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// ArithmeticError occurs when a builtin integer operation is undefined for its arguments,
+// such as division by zero, or overflows with checked integers.
+// It is located at the application of the builtin function, or at an integer literal out of the range of Int.
+type ArithmeticError struct {
+	Pos token.Position
+	End token.Position
+	Err error
+}
+
+func (e *ArithmeticError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Err)
+}
+
+func (e *ArithmeticError) Unwrap() error {
+	return e.Err
+}
+
+// Span returns the span of the application or the literal.
+func (e *ArithmeticError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
@@ -1,33 +1,120 @@
 package eval
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"slices"
 
 	"github.com/shota3506/gostlc/internal/ast"
 	"github.com/shota3506/gostlc/internal/builtin"
+	"github.com/shota3506/gostlc/internal/token"
 	"github.com/shota3506/gostlc/internal/values"
 )
 
+// Config controls the evaluation of programs.
+type Config struct {
+	// IntMode is the semantics of integers: wrapping, checked or arbitrary-precision.
+	IntMode builtin.IntMode
+
+	// matches caches the decision trees of the matches compiled during an evaluation,
+	// so that a match is compiled once however many times it is evaluated.
+	matches map[*ast.TypedMatchExpr]decision
+}
+
+// DefaultConfig is the configuration used by Eval and EvalProgram, with wrapping integers.
+var DefaultConfig = Config{}
+
+// Eval evaluates a type-checked expression with DefaultConfig.
 func Eval(expr ast.TypedExpr) (values.Value, error) {
+	return DefaultConfig.Eval(expr)
+}
+
+// EvalProgram evaluates a type-checked program with DefaultConfig.
+func EvalProgram(prog *ast.TypedProgram) (values.Value, error) {
+	return DefaultConfig.EvalProgram(prog)
+}
+
+// Eval evaluates a type-checked expression.
+func (c *Config) Eval(expr ast.TypedExpr) (values.Value, error) {
+	ev := c.evaluation()
+	return ev.evalExpr(expr, ev.rootRho())
+}
+
+// EvalProgram evaluates the top-level definitions of a program in order and
+// returns the value of its main expression.
+// The constructors of its data types are bound before the definitions.
+func (c *Config) EvalProgram(prog *ast.TypedProgram) (values.Value, error) {
+	c = c.evaluation()
+	env := c.rootRho()
+	for _, typeDecl := range prog.Types {
+		for _, con := range typeDecl.Constructors {
+			// A constructor without arguments is a value itself
+			var val values.Value = &values.DataValue{Constructor: con.Name}
+			if len(con.Params) > 0 {
+				val = &values.ConstructorFunc{Name: con.Name, Arity: len(con.Params)}
+			}
+			env = env.Bind(con.Name, val)
+		}
+	}
+	for _, decl := range prog.Decls {
+		val, err := c.evalExpr(decl.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		env = env.Bind(decl.Name, val)
+	}
+	return c.evalExpr(prog.Main, env)
+}
+
+// evaluation returns a copy of c for a single evaluation, with its own cache of compiled matches.
+func (c *Config) evaluation() *Config {
+	ev := *c
+	ev.matches = make(map[*ast.TypedMatchExpr]decision)
+	return &ev
+}
+
+func (c *Config) rootRho() *values.Rho {
 	root := values.NewRho()
-	for ident, val := range builtin.Functions {
+	for ident, val := range builtin.FunctionsFor(c.IntMode) {
 		root = root.Bind(ident, val)
 	}
-	return evalExpr(expr, root)
+	return root
 }
 
-func evalExpr(expr ast.TypedExpr, env *values.Rho) (values.Value, error) {
+func (c *Config) evalExpr(expr ast.TypedExpr, env *values.Rho) (values.Value, error) {
 	switch e := expr.(type) {
 	case *ast.TypedIntExpr:
-		return &values.IntValue{Value: e.Value}, nil
+		return c.intLiteral(&e.IntExpr)
 
 	case *ast.TypedBoolExpr:
 		return &values.BoolValue{Value: e.Value}, nil
 
+	case *ast.TypedUnitExpr:
+		return &values.UnitValue{}, nil
+
+	case *ast.TypedStringExpr:
+		return &values.StringValue{Value: e.Value}, nil
+
+	case *ast.TypedCharExpr:
+		return &values.CharValue{Value: e.Value}, nil
+
+	case *ast.TypedFloatExpr:
+		return &values.FloatValue{Value: e.Value}, nil
+
 	case *ast.TypedVarExpr:
+		// An operator refers to its builtin even where the name is bound otherwise
+		if val, ok := builtin.FunctionsFor(c.IntMode)[e.Name]; ok && e.Op != "" {
+			return val, nil
+		}
+
 		val, ok := env.Lookup(e.Name)
 		if !ok {
-			return nil, fmt.Errorf("undefined variable: %s at line %d, col %d", e.Name, e.Pos.Line, e.Pos.Column)
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: fmt.Sprintf("undefined variable: %s", e.Name)}
+		}
+		// A recursive reference is unfolded one step each time it is used
+		if fix, ok := val.(*values.FixValue); ok {
+			return c.apply(fix.Fn, fix, e.Span())
 		}
 		return val, nil
 
@@ -40,44 +127,321 @@ func evalExpr(expr ast.TypedExpr, env *values.Rho) (values.Value, error) {
 		}, nil
 
 	case *ast.TypedAppExpr:
-		fnVal, err := evalExpr(e.Func, env)
+		fnVal, err := c.evalExpr(e.Func, env)
 		if err != nil {
 			return nil, err
 		}
 
-		argVal, err := evalExpr(e.Arg, env)
+		// The lazy parameter of a builtin is passed its argument unevaluated
+		if lazyParam(fnVal) {
+			arg := &values.Thunk{Eval: func() (values.Value, error) {
+				return c.evalExpr(e.Arg, env)
+			}}
+			return c.apply(fnVal, arg, e.Span())
+		}
+
+		argVal, err := c.evalExpr(e.Arg, env)
 		if err != nil {
 			return nil, err
 		}
 
-		switch fn := fnVal.(type) {
-		case *values.Closure:
-			return evalExpr(fn.Body, fn.Env.Bind(fn.Param, argVal))
-		case *values.BuiltinFunc:
-			return fn.Fn(argVal)
-		case *values.PartialBuiltinFunc:
-			return fn.Fn(argVal)
-		default:
-			return nil, fmt.Errorf("expected function value at line %d, col %d", e.Pos.Line, e.Pos.Column)
+		return c.apply(fnVal, argVal, e.Span())
+
+	case *ast.TypedFixExpr:
+		fnVal, err := c.evalExpr(e.Func, env)
+		if err != nil {
+			return nil, err
 		}
 
+		// fix f => f (fix f), where the inner fix f is unfolded only when referenced
+		return c.apply(fnVal, &values.FixValue{Fn: fnVal}, e.Span())
+
 	case *ast.TypedIfExpr:
-		condVal, err := evalExpr(e.Cond, env)
+		condVal, err := c.evalExpr(e.Cond, env)
 		if err != nil {
 			return nil, err
 		}
 
 		boolVal, ok := condVal.(*values.BoolValue)
 		if !ok {
-			return nil, fmt.Errorf("expected boolean value in if condition at line %d, col %d", e.Pos.Line, e.Pos.Column)
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: "expected boolean value in if condition"}
 		}
 
 		if boolVal.Value {
-			return evalExpr(e.Then, env)
+			return c.evalExpr(e.Then, env)
+		}
+		return c.evalExpr(e.Else, env)
+
+	case *ast.TypedTupleExpr:
+		elems := make([]values.Value, len(e.Elems))
+		for i, elem := range e.Elems {
+			val, err := c.evalExpr(elem, env)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = val
+		}
+		return &values.TupleValue{Elems: elems}, nil
+
+	case *ast.TypedProjExpr:
+		val, err := c.evalExpr(e.Tuple, env)
+		if err != nil {
+			return nil, err
+		}
+
+		tupleVal, ok := val.(*values.TupleValue)
+		if !ok || e.Index < 1 || e.Index > len(tupleVal.Elems) {
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: fmt.Sprintf("expected tuple value with at least %d elements", e.Index)}
+		}
+		return tupleVal.Elems[e.Index-1], nil
+
+	case *ast.TypedInjExpr:
+		val, err := c.evalExpr(e.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		return &values.SumValue{Left: e.Left, Value: val}, nil
+
+	case *ast.TypedCaseExpr:
+		val, err := c.evalExpr(e.Scrutinee, env)
+		if err != nil {
+			return nil, err
+		}
+
+		sumVal, ok := val.(*values.SumValue)
+		if !ok {
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: "expected sum value in case scrutinee"}
+		}
+
+		if sumVal.Left {
+			return c.evalExpr(e.Left, env.Bind(e.LeftVar, sumVal.Value))
+		}
+		return c.evalExpr(e.Right, env.Bind(e.RightVar, sumVal.Value))
+
+	case *ast.TypedNilExpr:
+		return values.EmptyList, nil
+
+	case *ast.TypedListExpr:
+		elems := make([]values.Value, len(e.Elems))
+		for i, elem := range e.Elems {
+			val, err := c.evalExpr(elem, env)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = val
+		}
+		return values.NewList(elems...), nil
+
+	case *ast.TypedListCaseExpr:
+		val, err := c.evalExpr(e.Scrutinee, env)
+		if err != nil {
+			return nil, err
+		}
+
+		listVal, ok := val.(*values.ListValue)
+		if !ok {
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: "expected list value in case scrutinee"}
+		}
+
+		if listVal.Empty() {
+			return c.evalExpr(e.Nil, env)
+		}
+		return c.evalExpr(e.Cons, env.Bind(e.HeadVar, listVal.Head).Bind(e.TailVar, listVal.Tail))
+
+	case *ast.TypedRecordExpr:
+		fields := make([]values.RecordField, len(e.Fields))
+		for i, field := range e.Fields {
+			val, err := c.evalExpr(field.Value, env)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = values.RecordField{Label: field.Label, Value: val}
 		}
-		return evalExpr(e.Else, env)
+		return &values.RecordValue{Fields: fields}, nil
+
+	case *ast.TypedRecordProjExpr:
+		val, err := c.evalExpr(e.Record, env)
+		if err != nil {
+			return nil, err
+		}
+
+		recordVal, ok := val.(*values.RecordValue)
+		if !ok {
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: "expected record value"}
+		}
+		fieldVal, ok := recordVal.Field(e.Label)
+		if !ok {
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: fmt.Sprintf("record has no field %s", e.Label)}
+		}
+		return fieldVal, nil
+
+	case *ast.TypedVariantExpr:
+		val, err := c.evalExpr(e.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		return &values.VariantValue{Label: e.Label, Value: val}, nil
+
+	case *ast.TypedVariantCaseExpr:
+		val, err := c.evalExpr(e.Scrutinee, env)
+		if err != nil {
+			return nil, err
+		}
+
+		variantVal, ok := val.(*values.VariantValue)
+		if !ok {
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: "expected variant value in case scrutinee"}
+		}
+		for _, branch := range e.Branches {
+			if branch.Label == variantVal.Label {
+				return c.evalExpr(branch.Body, env.Bind(branch.Var, variantVal.Value))
+			}
+		}
+		return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: fmt.Sprintf("no case branch for label %s", variantVal.Label)}
+
+	case *ast.TypedMatchExpr:
+		return c.evalMatch(e, env)
+
+	case *ast.TypedFoldExpr:
+		val, err := c.evalExpr(e.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		return &values.FoldValue{Value: val}, nil
+
+	case *ast.TypedUnfoldExpr:
+		val, err := c.evalExpr(e.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		foldVal, ok := val.(*values.FoldValue)
+		if !ok {
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: "expected folded value in unfold"}
+		}
+		return foldVal.Value, nil
+
+	case *ast.TypedLetExpr:
+		val, err := c.evalExpr(e.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		return c.evalExpr(e.Body, env.Bind(e.Name, val))
+
+	// Types are erased at runtime: a type abstraction evaluates its body,
+	// and a type application evaluates the polymorphic expression.
+	case *ast.TypedTyAbsExpr:
+		return c.evalExpr(e.Body, env)
+
+	case *ast.TypedTyAppExpr:
+		return c.evalExpr(e.Func, env)
 
 	default:
 		return nil, fmt.Errorf("unsupported expression type: %T", expr)
 	}
 }
+
+func (c *Config) apply(fnVal, argVal values.Value, span token.Span) (values.Value, error) {
+	var builtinFn func(values.Value) (values.Value, error)
+	switch fn := fnVal.(type) {
+	case *values.Closure:
+		return c.evalExpr(fn.Body, fn.Env.Bind(fn.Param, argVal))
+	case *values.ConstructorFunc:
+		args := append(slices.Clone(fn.Args), argVal)
+		if len(args) == fn.Arity {
+			return &values.DataValue{Constructor: fn.Name, Args: args}, nil
+		}
+		return &values.ConstructorFunc{Name: fn.Name, Arity: fn.Arity, Args: args}, nil
+	case *values.BuiltinFunc:
+		builtinFn = fn.Fn
+	case *values.PartialBuiltinFunc:
+		builtinFn = fn.Fn
+	default:
+		return nil, &RuntimeError{Pos: span.Start, End: span.End, Message: "expected function value"}
+	}
+
+	val, err := applyBuiltin(builtinFn, argVal, span)
+	if err != nil {
+		return nil, err
+	}
+	// A builtin function taking functions leaves their applications to the evaluator
+	if call, ok := val.(*values.Call); ok {
+		return c.perform(call, span)
+	}
+	return val, nil
+}
+
+// perform applies the function of a call left by a builtin function applied at span to its arguments in order,
+// performing an argument that is a call first, or when needed if it is passed to a lazy parameter.
+func (c *Config) perform(call *values.Call, span token.Span) (values.Value, error) {
+	fn := call.Fn
+	for _, arg := range call.Args {
+		if inner, ok := arg.(*values.Call); ok {
+			if lazyParam(fn) {
+				arg = &values.Thunk{Eval: func() (values.Value, error) {
+					return c.perform(inner, span)
+				}}
+			} else {
+				val, err := c.perform(inner, span)
+				if err != nil {
+					return nil, err
+				}
+				arg = val
+			}
+		}
+
+		val, err := c.apply(fn, arg, span)
+		if err != nil {
+			return nil, err
+		}
+		fn = val
+	}
+	return fn, nil
+}
+
+// intLiteral returns the value of an integer literal, which overflows if it is out of the range of Int
+// with 64-bit integers. The type checker reports such literals unless it checks for arbitrary-precision integers.
+func (c *Config) intLiteral(e *ast.IntExpr) (values.Value, error) {
+	if c.IntMode == builtin.IntBig {
+		if e.Big != nil {
+			return &values.BigIntValue{Value: e.Big}, nil
+		}
+		return &values.BigIntValue{Value: big.NewInt(int64(e.Value))}, nil
+	}
+	if e.Big != nil {
+		return nil, &ArithmeticError{Pos: e.Pos, End: e.Span().End, Err: builtin.ErrOverflow}
+	}
+	return &values.IntValue{Value: e.Value}, nil
+}
+
+// applyBuiltin applies a builtin function, locating its errors at the application.
+// Errors of the evaluation of a lazy argument are located already.
+func applyBuiltin(fn func(values.Value) (values.Value, error), argVal values.Value, span token.Span) (values.Value, error) {
+	val, err := fn(argVal)
+	if err != nil {
+		var rtErr *RuntimeError
+		var arithErr *ArithmeticError
+		switch {
+		case errors.As(err, &rtErr), errors.As(err, &arithErr):
+			return nil, err
+		case errors.Is(err, builtin.ErrDivisionByZero),
+			errors.Is(err, builtin.ErrNegativeExponent),
+			errors.Is(err, builtin.ErrNegativeShift),
+			errors.Is(err, builtin.ErrOverflow):
+			return nil, &ArithmeticError{Pos: span.Start, End: span.End, Err: err}
+		}
+		return nil, &RuntimeError{Pos: span.Start, End: span.End, Message: err.Error()}
+	}
+	return val, nil
+}
+
+// lazyParam reports whether fnVal is a builtin function whose parameter is lazy.
+func lazyParam(fnVal values.Value) bool {
+	switch fn := fnVal.(type) {
+	case *values.BuiltinFunc:
+		return fn.Lazy
+	case *values.PartialBuiltinFunc:
+		return fn.Lazy
+	default:
+		return false
+	}
+}
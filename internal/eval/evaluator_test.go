@@ -1,9 +1,12 @@
 package eval
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/shota3506/gostlc/internal/builtin"
 	"github.com/shota3506/gostlc/internal/parser"
+	"github.com/shota3506/gostlc/internal/token"
 	"github.com/shota3506/gostlc/internal/types"
 	"github.com/shota3506/gostlc/internal/values"
 )
@@ -40,6 +43,39 @@ func TestEvalIntegerExpressions(t *testing.T) {
 		{"builtin sub partial application", "(sub 10) 4", 6},
 		{"builtin sub in lambda", "(\\f:Int->Int. f 3) (sub 10)", 7},
 		{"builtin sub with add", "sub (add 10 5) 7", 8},
+		{"let binding", "let x = 5 in add x x", 10},
+		{"let binding with annotation", "let f : Int -> Int = add 1 in f 41", 42},
+		{"nested let shadowing", "let x = 1 in let x = 2 in x", 2},
+		{"fix countdown", "fix (\\f:Int->Int. \\n:Int. if eq n 0 then 0 else f (sub n 1)) 10", 0},
+		{"letrec sum", "letrec sum : Int -> Int = \\n:Int. if eq n 0 then 0 else add n (sum (sub n 1)) in sum 10", 55},
+		{
+			"letrec fibonacci",
+			"letrec fib : Int -> Int = \\n:Int. if lt n 2 then n else add (fib (sub n 1)) (fib (sub n 2)) in fib 10",
+			55,
+		},
+		{"fix of constant function", "fix (\\x:Int. 7)", 7},
+		{"tuple first projection", "(1, true).1", 1},
+		{"nested tuple projection", "(1, (2, 3)).2.2", 3},
+		{"case on left injection", "case inl 1 as Int+Bool of inl x => add x 1 | inr b => 0", 2},
+		{"case on right injection", "case inr true as Int+Bool of inl x => x | inr b => if b then 10 else 20", 10},
+		{"record projection", "{x = 1, y = true}.x", 1},
+		{"record with reordered type", "(\\r:{y:Bool, x:Int}. r.x) {x = 7, y = false}", 7},
+		{
+			"variant case",
+			"case (<some = 5> as <some:Int, none:Bool>) of <none = u> => 0 | <some = n> => add n 1",
+			6,
+		},
+		{"function returning pair", "((\\x:Int. (add x 1, sub x 1)) 10).2", 9},
+		{"operator precedence", "1 + 2 * 3 - 8 / 2 % 3", 6},
+		{"subtraction without spaces", "let n = 5 in n-1", 4},
+		{"negation", "-(2 + 3) * -1", 5},
+		{"operator sections", "(+ 1) ((10 -) ((* 2) 3))", 5},
+		{"operator shadowing builtin", "let add = \\x:Int. \\y:Int. 0 in 1 + 2", 3},
+		{
+			"letrec fibonacci with operators",
+			"letrec fib : Int -> Int = \\n:Int. if n < 2 then n else fib (n - 1) + fib (n - 2) in fib 10",
+			55,
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,6 +108,13 @@ func TestEvalBooleanExpressions(t *testing.T) {
 		{"const bool first false", "((\\x:Bool.\\y:Bool.x) false) true", false},
 		{"const bool second true", "((\\x:Bool.\\y:Bool.y) false) true", true},
 		{"const bool second false", "((\\x:Bool.\\y:Bool.y) true) false", false},
+		{"comparison operators", "1 < 2 && 2 <= 2 && 3 > 2 && 3 >= 3 && 1 == 1 && 1 != 2", true},
+		{"boolean operator precedence", "true || false && false", true},
+		{"not operator", "!(1 == 1) || !true", false},
+		{"short-circuit conjunction", "false && 1 / 0 == 0", false},
+		{"short-circuit disjunction", "true || 1 / 0 == 0", true},
+		{"short-circuit first-class conjunction", "(\\f:Bool->Bool->Bool. f false (1 / 0 == 0)) and", false},
+		{"short-circuit partial disjunction", "let f = or true in f (1 % 0 == 0)", true},
 		{"if in lambda true", "(\\x:Int.if true then x else x) 5", true}, // This should return int, not bool
 	}
 
@@ -124,6 +167,252 @@ func TestEvalClosures(t *testing.T) {
 	}
 }
 
+func TestEvalProgram(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"main expression only", "42", "42"},
+		{"single definition", "let x = 1\nadd x 2", "3"},
+		{
+			"definitions referring to earlier ones",
+			"let inc : Int -> Int = add 1\nlet twice = \\f:Int->Int. \\x:Int. f (f x)\ntwice inc 40",
+			"42",
+		},
+		{"definition followed by let expression", "let x = 1\nlet y = 2 in sub x y", "-1"},
+		{"left injection", "inl 1 as Int+Bool", "inl 1"},
+		{
+			"optional result",
+			"let safediv = \\n:Int. \\d:Int. if eq d 0 then inr true as Int+Bool else inl n as Int+Bool\nsafediv 1 0",
+			"inr true",
+		},
+		{"record", "let origin = {x = 0, y = 0}\norigin", "{x = 0, y = 0}"},
+		{"variant", "<none = true> as <some:Int, none:Bool>", "<none = true>"},
+		{"tuple", "let swap = \\p:Int*Bool. (p.2, p.1)\nswap (1, true)", "(true, 1)"},
+		{
+			"recursive definition",
+			"letrec even : Int -> Bool = \\n:Int.\n  if eq n 0 then true else not (even (sub n 1))\neven 10",
+			"true",
+		},
+		{"polymorphic let", "let id = \\x. x in (id 1, id true)", "(1, true)"},
+		{"polymorphic definition", "let const = \\x. \\y. x\n(const 1 true, const false 2)", "(1, false)"},
+		{"polymorphic builtin", "(choose true 1 2, choose false true false)", "(1, false)"},
+		{"base type literals", "((), \"a\\\"b\\n\", 'c', 1.50)", "((), \"a\\\"b\\n\", 'c', 1.5)"},
+		{"string builtins", "let s = concat \"héllo, \" \"world\" in (length s, substr s 1 4, streq s s)", "(12, \"éllo\", true)"},
+		{"character builtins", "(ord 'a', chr (ord 'a' + 1))", "(97, 'b')"},
+		{"float builtins", "(fadd 1.5 (itof 2), ftoi (fdiv 7.0 2.0), flt (fneg 1.0) 0.0)", "(3.5, 3, true)"},
+		{"show", "concat \"n = \" (show (1 + 2, 'x'))", "\"n = (3, 'x')\""},
+		{"list literal", "(nil[Int], [1, 2 + 1], 0 :: [1], [[true], []])", "([], [1, 3], [0, 1], [[true], []])"},
+		{
+			"list case analysis",
+			"letrec sum : List Int -> Int = \\xs:List Int. case xs of [] => 0 | h :: t => h + sum t\nsum [1, 2, 3]",
+			"6",
+		},
+		{"list builtins", "let xs = [1, 2, 3] in (isnil xs, head xs, tail xs, isnil (tail (tail (tail xs))))", "(false, 1, [2, 3], true)"},
+		{"folds", "(foldr (\\x. \\acc. x :: acc) [] [1, 2, 3], foldl (\\acc. \\x. x :: acc) [] [1, 2, 3])", "([1, 2, 3], [3, 2, 1])"},
+		{"fold with builtin", "(foldl sub 10 [1, 2], foldr sub 0 [1, 2])", "(7, -1)"},
+		{"fold with lazy builtin", "(foldr and true [true, false, true], foldr or false [false, true])", "(false, true)"},
+		{"unit argument", "let f = \\u:Unit. 42 in f ()", "42"},
+		{"type application", "let id = /\\A. \\x:A. x\nid [Int] 3", "3"},
+		{
+			"polymorphic argument",
+			"let twice = /\\A. \\f:A->A. \\x:A. f (f x)\n(\\g:forall A. (A->A)->A->A. (g [Int] (add 1) 0, g [Bool] not true)) twice",
+			"(2, true)",
+		},
+		{"constructors", "type Shape = Circle Int | Rect Int Int | Dot\n(Circle 1, Rect 2, Dot)", "(Circle 1, <constructor:Rect>, Dot)"},
+		{
+			"match on constructors",
+			"type Shape = Circle Int | Rect Int Int\nlet area = \\s. match s with\n  | Circle r -> 3 * r * r\n  | Rect w h -> w * h\n(area (Circle 1), area (Rect 2 3))",
+			"(3, 6)",
+		},
+		{
+			"recursive data type",
+			"type Tree = Leaf | Node Tree Int Tree\nletrec sum : Tree -> Int = \\t:Tree. match t with Leaf -> 0 | Node l x r -> sum l + x + sum r\nlet t = Node (Node Leaf 1 Leaf) 2 Leaf\n(sum t, t)",
+			"(3, Node (Node Leaf 1 Leaf) 2 Leaf)",
+		},
+		{
+			"nested patterns",
+			"type Opt = None | Some Int\ntype Pair = Pair Opt Opt\nlet f = \\p. match p with Pair (Some x) (Some y) -> x + y | Pair (Some x) _ -> x | Pair _ (Some 0) -> -1 | _ -> 0\n(f (Pair (Some 1) (Some 2)), f (Pair (Some 1) None), f (Pair None (Some 0)), f (Pair None (Some 5)))",
+			"(3, 1, -1, 0)",
+		},
+		{
+			"recursive type",
+			"let cons = \\x:Int. \\l:mu L. Unit + Int * L. fold [mu L. Unit + Int * L] (inr (x, l) as Unit + Int * (mu L. Unit + Int * L))\nlet empty = fold [mu L. Unit + Int * L] (inl () as Unit + Int * (mu L. Unit + Int * L))\nletrec sum : (mu L. Unit + Int * L) -> Int = \\l. case unfold [mu L. Unit + Int * L] l of inl u => 0 | inr p => p.1 + sum p.2\n(sum (cons 1 (cons 2 empty)), cons 1 empty)",
+			"(3, fold inr (1, fold inl ()))",
+		},
+		{
+			"stream",
+			"letrec from : Int -> (mu S. Unit -> Int * S) = \\n. fold [mu S. Unit -> Int * S] (\\u:Unit. (n, from (n + 1)))\nlet next = \\s:mu T. Unit -> Int * T. unfold [mu S. Unit -> Int * S] s ()\n(next (next (from 0)).2).1",
+			"1",
+		},
+		{
+			"literal patterns",
+			"let f = \\s. match s with \"zero\" -> 0 | \"one\" -> 1 | _ -> -1\nlet g = \\b. match b with true -> 1 | false -> 0\n(f \"one\", f \"two\", g false, match 'x' with 'x' -> true | _ -> false)",
+			"(1, -1, 0, true)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := parser.ParseProgram(tt.input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+
+			typedProg, err := types.CheckProgram(prog)
+			if err != nil {
+				t.Fatalf("type checker error: %v", err)
+			}
+
+			val, err := EvalProgram(typedProg)
+			if err != nil {
+				t.Fatalf("evaluator error: %v", err)
+			}
+
+			if val.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, val.String())
+			}
+		})
+	}
+}
+
+func TestEvalArithmeticErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError error
+		expectedPos   token.Position
+		expectedEnd   token.Position
+	}{
+		{"division by zero", "1 + 10 / 0", builtin.ErrDivisionByZero, token.Position{Line: 1, Column: 5}, token.Position{Line: 1, Column: 11}},
+		{"modulo by zero", "let x = 0 in mod 1 x", builtin.ErrDivisionByZero, token.Position{Line: 1, Column: 14}, token.Position{Line: 1, Column: 21}},
+		{"negative exponent", "(\\n:Int. pow 2 n) (-1)", builtin.ErrNegativeExponent, token.Position{Line: 1, Column: 10}, token.Position{Line: 1, Column: 17}},
+		{"negative shift count", "shl 1 (-1)", builtin.ErrNegativeShift, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 11}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+
+			typedExpr, err := types.Check(expr)
+			if err != nil {
+				t.Fatalf("type checker error: %v", err)
+			}
+
+			_, err = Eval(typedExpr)
+			var arithErr *ArithmeticError
+			if !errors.As(err, &arithErr) {
+				t.Fatalf("expected ArithmeticError, got %v", err)
+			}
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, arithErr.Err)
+			}
+			if arithErr.Pos.Line != tt.expectedPos.Line || arithErr.Pos.Column != tt.expectedPos.Column {
+				t.Errorf("expected error at %v, got %v", tt.expectedPos, arithErr.Pos)
+			}
+			if arithErr.End.Line != tt.expectedEnd.Line || arithErr.End.Column != tt.expectedEnd.Column {
+				t.Errorf("expected error to end at %v, got %v", tt.expectedEnd, arithErr.End)
+			}
+		})
+	}
+}
+
+func TestEvalEmptyListErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{"head of empty list", "1 + head (tail [1])", "1:5: head of empty list"},
+		{"tail of empty list", "let xs = nil[Bool] in tail xs", "1:23: tail of empty list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+
+			typedExpr, err := types.Check(expr)
+			if err != nil {
+				t.Fatalf("type checker error: %v", err)
+			}
+
+			_, err = Eval(typedExpr)
+			var rtErr *RuntimeError
+			if !errors.As(err, &rtErr) {
+				t.Fatalf("expected RuntimeError, got %v", err)
+			}
+			if err.Error() != tt.expectedError {
+				t.Errorf("expected error %q, got %q", tt.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestEvalIntModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		mode          builtin.IntMode
+		expected      string
+		expectedError error
+	}{
+		{name: "wrapping addition", input: "9223372036854775807 + 1", mode: builtin.IntWrap, expected: "-9223372036854775808"},
+		{name: "wrapping power", input: "pow 2 64", mode: builtin.IntWrap, expected: "0"},
+		{name: "checked addition", input: "9223372036854775807 + 1", mode: builtin.IntChecked, expectedError: builtin.ErrOverflow},
+		{name: "checked multiplication", input: "-1 * (-9223372036854775807 - 1)", mode: builtin.IntChecked, expectedError: builtin.ErrOverflow},
+		{name: "checked negation", input: "neg (-9223372036854775807 - 1)", mode: builtin.IntChecked, expectedError: builtin.ErrOverflow},
+		{name: "checked left shift", input: "shl 1 63", mode: builtin.IntChecked, expectedError: builtin.ErrOverflow},
+		{name: "checked in range", input: "pow 2 62 + (pow 2 62 - 1)", mode: builtin.IntChecked, expected: "9223372036854775807"},
+		{name: "checked division by zero", input: "1 / 0", mode: builtin.IntChecked, expectedError: builtin.ErrDivisionByZero},
+		{name: "big addition", input: "9223372036854775807 + 1", mode: builtin.IntBig, expected: "9223372036854775808"},
+		{name: "big literal", input: "99999999999999999999 * 2", mode: builtin.IntBig, expected: "199999999999999999998"},
+		{name: "big literal pattern", input: "match 99999999999999999999 with | 99999999999999999999 -> 1 | _ -> 0", mode: builtin.IntBig, expected: "1"},
+		{name: "big power", input: "pow 2 100", mode: builtin.IntBig, expected: "1267650600228229401496703205376"},
+		{name: "big comparison", input: "shl 1 70 > shl 1 69", mode: builtin.IntBig, expected: "true"},
+		{name: "big truncated division", input: "(-7 / 2, -7 % 2)", mode: builtin.IntBig, expected: "(-3, -1)"},
+		{name: "big division by zero", input: "pow 10 30 / 0", mode: builtin.IntBig, expectedError: builtin.ErrDivisionByZero},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+
+			checkConfig := types.Config{IntMode: tt.mode}
+			typedExpr, err := checkConfig.Check(expr)
+			if err != nil {
+				t.Fatalf("type checker error: %v", err)
+			}
+
+			config := Config{IntMode: tt.mode}
+			val, err := config.Eval(typedExpr)
+			if tt.expectedError != nil {
+				var arithErr *ArithmeticError
+				if !errors.As(err, &arithErr) || !errors.Is(err, tt.expectedError) {
+					t.Fatalf("expected ArithmeticError %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("evaluator error: %v", err)
+			}
+
+			if val.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, val.String())
+			}
+		})
+	}
+}
+
 func eval(t *testing.T, input string) values.Value {
 	t.Helper()
 
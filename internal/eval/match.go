@@ -0,0 +1,252 @@
+package eval
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/values"
+)
+
+// A match is compiled to a decision tree, which tests each part of the scrutinee at most once
+// on the way to the arm selected. The tree is built from the clause matrix of the arms
+// as described by Maranget in "Compiling pattern matching to good decision trees".
+
+// decision is a node of a decision tree.
+type decision interface {
+	decision()
+}
+
+// leaf selects an arm, binding the variables of its pattern to the parts of the scrutinee they matched.
+type leaf struct {
+	arm      int
+	bindings []binding
+}
+
+// failure is reached by the values matched by no arm.
+type failure struct{}
+
+// switchNode selects the next node by the constructor or literal of the part of the scrutinee at path.
+// The values matched by none of the cases continue with fallback.
+type switchNode struct {
+	path     occurrence
+	cases    []switchCase
+	fallback decision
+}
+
+type switchCase struct {
+	// key is the name of a constructor or the key of a literal, as returned by ast.LiteralPattern.Key.
+	key  string
+	next decision
+}
+
+func (*leaf) decision()       {}
+func (*failure) decision()    {}
+func (*switchNode) decision() {}
+
+type binding struct {
+	name string
+	path occurrence
+}
+
+// occurrence locates a part of the scrutinee by the indices of the constructor arguments leading to it.
+type occurrence []int
+
+// of returns the part of v at o.
+func (o occurrence) of(v values.Value) values.Value {
+	for _, i := range o {
+		v = v.(*values.DataValue).Args[i]
+	}
+	return v
+}
+
+// clause is a row of the clause matrix: the patterns of an arm that the parts of the scrutinee
+// in the columns are still to match, and the variables bound by the patterns already matched.
+type clause struct {
+	patterns []ast.Pattern
+	arm      int
+	bindings []binding
+}
+
+// compileMatch compiles the arms of a match to a decision tree.
+func compileMatch(arms []ast.TypedMatchArm) decision {
+	clauses := make([]clause, len(arms))
+	for i, arm := range arms {
+		clauses[i] = clause{patterns: []ast.Pattern{arm.Pattern}, arm: i}
+	}
+	return compile(clauses, []occurrence{{}})
+}
+
+// compile builds the decision tree of clauses whose columns are the parts of the scrutinee at occs.
+func compile(clauses []clause, occs []occurrence) decision {
+	if len(clauses) == 0 {
+		return &failure{}
+	}
+
+	// The first clause is selected once every pattern left in it matches any value
+	first := clauses[0]
+	col := slices.IndexFunc(first.patterns, refutable)
+	if col < 0 {
+		bindings := slices.Clone(first.bindings)
+		for i, p := range first.patterns {
+			if v, ok := p.(*ast.VarPattern); ok {
+				bindings = append(bindings, binding{name: v.Name, path: occs[i]})
+			}
+		}
+		return &leaf{arm: first.arm, bindings: bindings}
+	}
+
+	// Switch on the first column the first clause tests
+	node := &switchNode{path: occs[col]}
+	var keys []string
+	for _, cl := range clauses {
+		if key, arity, ok := head(cl.patterns[col]); ok && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+
+			subOccs := slices.Clone(occs[:col])
+			for i := range arity {
+				subOccs = append(subOccs, append(slices.Clone(occs[col]), i))
+			}
+			subOccs = append(subOccs, occs[col+1:]...)
+
+			node.cases = append(node.cases, switchCase{
+				key:  key,
+				next: compile(specialize(clauses, col, occs[col], key, arity), subOccs),
+			})
+		}
+	}
+
+	defaultOccs := slices.Delete(slices.Clone(occs), col, col+1)
+	node.fallback = compile(specialize(clauses, col, occs[col], "", 0), defaultOccs)
+	return node
+}
+
+// specialize returns the clauses matching a value at column col built by the constructor or literal key
+// with arity arguments, with the pattern at col replaced by the patterns of the arguments.
+// If key is empty, it returns the clauses matching any value at col, without the column.
+func specialize(clauses []clause, col int, occ occurrence, key string, arity int) []clause {
+	var result []clause
+	for _, cl := range clauses {
+		p := cl.patterns[col]
+		var args []ast.Pattern
+		if k, _, ok := head(p); ok {
+			if k != key {
+				continue
+			}
+			if c, ok := p.(*ast.ConstructorPattern); ok {
+				args = c.Args
+			}
+		} else {
+			for range arity {
+				args = append(args, &ast.WildcardPattern{})
+			}
+		}
+
+		bindings := cl.bindings
+		if v, ok := p.(*ast.VarPattern); ok {
+			bindings = append(slices.Clone(bindings), binding{name: v.Name, path: occ})
+		}
+
+		patterns := slices.Concat(cl.patterns[:col], args, cl.patterns[col+1:])
+		result = append(result, clause{patterns: patterns, arm: cl.arm, bindings: bindings})
+	}
+	return result
+}
+
+// refutable reports whether p may fail to match a value.
+func refutable(p ast.Pattern) bool {
+	_, _, ok := head(p)
+	return ok
+}
+
+// head returns the constructor or literal that p tests, and the number of its arguments.
+func head(p ast.Pattern) (string, int, bool) {
+	switch p := p.(type) {
+	case *ast.ConstructorPattern:
+		return p.Name, len(p.Args), true
+	case *ast.LiteralPattern:
+		return p.Key(), 0, true
+	default:
+		return "", 0, false
+	}
+}
+
+// key returns the constructor of v, or the key of the literal of v.
+func key(v values.Value) string {
+	switch v := v.(type) {
+	case *values.DataValue:
+		return v.Constructor
+	case *values.BoolValue:
+		return strconv.FormatBool(v.Value)
+	case *values.IntValue:
+		return strconv.Itoa(v.Value)
+	case *values.BigIntValue:
+		return v.Value.String()
+	case *values.StringValue:
+		return strconv.Quote(v.Value)
+	case *values.CharValue:
+		return strconv.QuoteRune(v.Value)
+	default:
+		return "()"
+	}
+}
+
+// evalMatch selects the arm of e matching the value of its scrutinee with the decision tree of its arms,
+// which is compiled on the first evaluation of e.
+func (c *Config) evalMatch(e *ast.TypedMatchExpr, env *values.Rho) (values.Value, error) {
+	val, err := c.evalExpr(e.Scrutinee, env)
+	if err != nil {
+		return nil, err
+	}
+
+	node, ok := c.matches[e]
+	if !ok {
+		for _, arm := range e.Arms {
+			if err := c.checkPattern(arm.Pattern); err != nil {
+				return nil, err
+			}
+		}
+		node = compileMatch(e.Arms)
+		c.matches[e] = node
+	}
+	for {
+		switch n := node.(type) {
+		case *leaf:
+			for _, b := range n.bindings {
+				env = env.Bind(b.name, b.path.of(val))
+			}
+			return c.evalExpr(e.Arms[n.arm].Body, env)
+		case *switchNode:
+			k := key(n.path.of(val))
+			node = n.fallback
+			for _, sc := range n.cases {
+				if sc.key == k {
+					node = sc.next
+					break
+				}
+			}
+		default:
+			return nil, &RuntimeError{Pos: e.Pos, End: e.Span().End, Message: fmt.Sprintf("no match arm for value %s", val)}
+		}
+	}
+}
+
+// checkPattern reports the integer literals of p out of the range of Int,
+// which overflow as they would as expressions.
+func (c *Config) checkPattern(p ast.Pattern) error {
+	switch p := p.(type) {
+	case *ast.LiteralPattern:
+		if lit, ok := p.Value.(*ast.IntExpr); ok {
+			_, err := c.intLiteral(lit)
+			return err
+		}
+	case *ast.ConstructorPattern:
+		for _, arg := range p.Args {
+			if err := c.checkPattern(arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -1,11 +1,13 @@
 package lexer
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/shota3506/gostlc/internal/token"
 )
@@ -23,8 +25,9 @@ func (e *LexerError) Error() string {
 	return fmt.Sprintf("%d:%d: %s", e.pos.Line, e.pos.Column, e.message)
 }
 
-func (e *LexerError) Pos() token.Position {
-	return e.pos
+// Span returns the empty span at the position of the offending character.
+func (e *LexerError) Span() token.Span {
+	return token.Span{Start: e.pos, End: e.pos}
 }
 
 func (e *LexerError) Unwrap() error {
@@ -32,24 +35,28 @@ func (e *LexerError) Unwrap() error {
 }
 
 type bufferedRuneReader struct {
-	r   io.RuneReader
-	buf *rune
+	r    io.RuneReader
+	buf  *rune
+	size int // size of the buffered rune in bytes
 
-	line   int
-	colume int
+	filename string
+	offset   int
+	line     int
+	colume   int
 }
 
-func newBufferedRuneReader(r io.RuneReader) *bufferedRuneReader {
+func newBufferedRuneReader(r io.RuneReader, filename string) *bufferedRuneReader {
 	return &bufferedRuneReader{
 		r: r,
 
-		line:   1,
-		colume: 1,
+		filename: filename,
+		line:     1,
+		colume:   1,
 	}
 }
 
 func (r *bufferedRuneReader) pos() token.Position {
-	return token.Position{Line: r.line, Column: r.colume}
+	return token.Position{Filename: r.filename, Offset: r.offset, Line: r.line, Column: r.colume}
 }
 
 func (r *bufferedRuneReader) Peek() (rune, token.Position, error) {
@@ -57,17 +64,20 @@ func (r *bufferedRuneReader) Peek() (rune, token.Position, error) {
 		ru := *r.buf
 		return ru, r.pos(), nil
 	}
-	ru, _, err := r.r.ReadRune()
+	ru, size, err := r.r.ReadRune()
 	if err != nil {
 		return 0, r.pos(), err
 	}
 	r.buf = &ru
+	r.size = size
 	return ru, r.pos(), nil
 }
 
 func (r *bufferedRuneReader) Read() (ru rune, pos token.Position, err error) {
+	var size int
 	defer func() {
 		if err == nil {
+			r.offset += size
 			if ru == '\n' {
 				r.line++
 				r.colume = 1
@@ -79,26 +89,67 @@ func (r *bufferedRuneReader) Read() (ru rune, pos token.Position, err error) {
 
 	if r.buf != nil {
 		ru = *r.buf
+		size = r.size
 		r.buf = nil
 		return ru, r.pos(), nil
 	}
-	ru, _, err = r.r.ReadRune()
+	ru, size, err = r.r.ReadRune()
 	return ru, r.pos(), err
 }
 
 // Lexer is a lexical analyzer for the lambda calculus with simple types.
 type Lexer struct {
 	reader *bufferedRuneReader
+
+	// operandEnd reports whether the last token read ends an operand and is directly followed by the next character,
+	// in which case a '-' before a digit is a subtraction rather than the sign of a negative literal.
+	operandEnd bool
+	// afterDot reports whether the last token read is '.', after which a number is a tuple index
+	// rather than a float literal, as in 'p.1.2'.
+	afterDot bool
 }
 
+// New returns a lexer reading the string s.
 func New(s string) *Lexer {
 	return &Lexer{
-		reader: newBufferedRuneReader(strings.NewReader(s)),
+		reader: newBufferedRuneReader(strings.NewReader(s), ""),
+	}
+}
+
+// NewReader returns a lexer reading r as it goes, so that the source need not be held in memory.
+// The positions of the tokens carry filename.
+func NewReader(r io.Reader, filename string) *Lexer {
+	rr, ok := r.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(r)
+	}
+	return &Lexer{
+		reader: newBufferedRuneReader(rr, filename),
 	}
 }
 
 func (l *Lexer) Next() (token.Token, error) {
-	l.skipWhitespace()
+	var trivia []token.Token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return token.Token{}, err
+		}
+		l.operandEnd = endsOperand(tok.Kind)
+		l.afterDot = tok.Kind == token.TokenKindDot
+		if tok.Kind != token.TokenKindComment {
+			tok.Trivia = trivia
+			return tok, nil
+		}
+		trivia = append(trivia, tok)
+	}
+}
+
+// next returns the next token or comment.
+func (l *Lexer) next() (token.Token, error) {
+	if err := l.skipWhitespace(); err != nil {
+		return token.Token{}, err
+	}
 
 	ch, pos, err := l.reader.Read()
 	if err != nil {
@@ -116,22 +167,50 @@ func (l *Lexer) Next() (token.Token, error) {
 	}
 
 	switch ch {
-	case '\\':
+	case '\\', 'λ':
 		return token.Token{Kind: token.TokenKindLambda, Value: string(ch), Pos: pos}, nil
+	case 'Λ':
+		return token.Token{Kind: token.TokenKindTyLambda, Value: string(ch), Pos: pos}, nil
+	case '→':
+		return token.Token{Kind: token.TokenKindArrow, Value: string(ch), Pos: pos}, nil
+	case '∀':
+		return token.Token{Kind: token.TokenKindForall, Value: string(ch), Pos: pos}, nil
 	case '.':
 		return token.Token{Kind: token.TokenKindDot, Value: string(ch), Pos: pos}, nil
 	case ':':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == ':' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindColonColon, Value: "::", Pos: pos}, nil
+		}
 		return token.Token{Kind: token.TokenKindColon, Value: string(ch), Pos: pos}, nil
 	case '(':
 		return token.Token{Kind: token.TokenKindLParen, Value: string(ch), Pos: pos}, nil
 	case ')':
 		return token.Token{Kind: token.TokenKindRParen, Value: string(ch), Pos: pos}, nil
-	case '-':
+	case '=':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '>' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindFatArrow, Value: "=>", Pos: pos}, nil
+		}
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '=' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindEqualEqual, Value: "==", Pos: pos}, nil
+		}
+		return token.Token{Kind: token.TokenKindEqual, Value: string(ch), Pos: pos}, nil
+	case '!':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '=' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindNotEqual, Value: "!=", Pos: pos}, nil
+		}
+		return token.Token{Kind: token.TokenKindBang, Value: string(ch), Pos: pos}, nil
+	case '%':
+		return token.Token{Kind: token.TokenKindPercent, Value: string(ch), Pos: pos}, nil
+	case '&':
 		nextCh, nextPos, err := l.reader.Peek()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				return token.Token{}, &LexerError{
-					message: "unexpected eof after '-'",
+					message: "unexpected eof after '&'",
 					pos:     nextPos,
 				}
 			}
@@ -141,33 +220,95 @@ func (l *Lexer) Next() (token.Token, error) {
 				err:     err,
 			}
 		}
+		if nextCh == '&' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindAndAnd, Value: "&&", Pos: pos}, nil
+		}
+		return token.Token{}, &LexerError{
+			message: fmt.Sprintf("unexpected character after '&': %q", nextCh),
+			pos:     nextPos,
+		}
+	case '*':
+		return token.Token{Kind: token.TokenKindStar, Value: string(ch), Pos: pos}, nil
+	case ',':
+		return token.Token{Kind: token.TokenKindComma, Value: string(ch), Pos: pos}, nil
+	case '+':
+		return token.Token{Kind: token.TokenKindPlus, Value: string(ch), Pos: pos}, nil
+	case '|':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '|' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindOrOr, Value: "||", Pos: pos}, nil
+		}
+		return token.Token{Kind: token.TokenKindBar, Value: string(ch), Pos: pos}, nil
+	case '{':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '-' {
+			_, _, _ = l.reader.Read()
+			return l.readBlockComment(pos)
+		}
+		return token.Token{Kind: token.TokenKindLBrace, Value: string(ch), Pos: pos}, nil
+	case '}':
+		return token.Token{Kind: token.TokenKindRBrace, Value: string(ch), Pos: pos}, nil
+	case '<':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '=' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindLessEqual, Value: "<=", Pos: pos}, nil
+		}
+		return token.Token{Kind: token.TokenKindLAngle, Value: string(ch), Pos: pos}, nil
+	case '>':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '=' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindGreaterEqual, Value: ">=", Pos: pos}, nil
+		}
+		return token.Token{Kind: token.TokenKindRAngle, Value: string(ch), Pos: pos}, nil
+	case '[':
+		return token.Token{Kind: token.TokenKindLBracket, Value: string(ch), Pos: pos}, nil
+	case ']':
+		return token.Token{Kind: token.TokenKindRBracket, Value: string(ch), Pos: pos}, nil
+	case '/':
+		if nextCh, _, err := l.reader.Peek(); err == nil && nextCh == '\\' {
+			_, _, _ = l.reader.Read()
+			return token.Token{Kind: token.TokenKindTyLambda, Value: "/\\", Pos: pos}, nil
+		}
+		return token.Token{Kind: token.TokenKindSlash, Value: string(ch), Pos: pos}, nil
+	case '-':
+		nextCh, nextPos, err := l.reader.Peek()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return token.Token{Kind: token.TokenKindMinus, Value: string(ch), Pos: pos}, nil
+			}
+			return token.Token{}, &LexerError{
+				message: "read character",
+				pos:     nextPos,
+				err:     err,
+			}
+		}
 		if nextCh == '>' {
 			_, _, _ = l.reader.Read()
 			return token.Token{Kind: token.TokenKindArrow, Value: "->", Pos: pos}, nil
 		}
-		if isDigit(nextCh) {
+		if nextCh == '-' {
 			_, _, _ = l.reader.Read()
-			return token.Token{
-				Kind:  token.TokenKindInt,
-				Value: "-" + l.readInteger(nextCh),
-				Pos:   pos,
-			}, nil
+			return token.Token{Kind: token.TokenKindComment, Value: "--" + l.readLine(), Pos: pos}, nil
 		}
-		return token.Token{}, &LexerError{
-			message: fmt.Sprintf("unexpected character after '-': %q", nextCh),
-			pos:     nextPos,
+		// A '-' directly after an operand subtracts, as in 'n-1', rather than starting a negative literal as in 'f -1'
+		if isDigit(nextCh) && !l.operandEnd {
+			_, _, _ = l.reader.Read()
+			tok, err := l.readNumber(nextCh, pos)
+			tok.Value = "-" + tok.Value
+			return tok, err
 		}
+		return token.Token{Kind: token.TokenKindMinus, Value: string(ch), Pos: pos}, nil
+	case '"':
+		return l.readQuoted(ch, pos, token.TokenKindString)
+	case '\'':
+		return l.readQuoted(ch, pos, token.TokenKindChar)
 	}
 
 	if isDigit(ch) {
-		return token.Token{
-			Kind:  token.TokenKindInt,
-			Value: l.readInteger(ch),
-			Pos:   pos,
-		}, nil
+		return l.readNumber(ch, pos)
 	}
 
-	if isAlphabetOrUnderscore(ch) {
+	if isIdentStart(ch) {
 		ident := l.readIdentifier(ch)
 		switch ident {
 		case "true":
@@ -180,10 +321,54 @@ func (l *Lexer) Next() (token.Token, error) {
 			return token.Token{Kind: token.TokenKindThen, Value: ident, Pos: pos}, nil
 		case "else":
 			return token.Token{Kind: token.TokenKindElse, Value: ident, Pos: pos}, nil
+		case "let":
+			return token.Token{Kind: token.TokenKindLet, Value: ident, Pos: pos}, nil
+		case "in":
+			return token.Token{Kind: token.TokenKindIn, Value: ident, Pos: pos}, nil
+		case "letrec":
+			return token.Token{Kind: token.TokenKindLetrec, Value: ident, Pos: pos}, nil
+		case "fix":
+			return token.Token{Kind: token.TokenKindFix, Value: ident, Pos: pos}, nil
+		case "inl":
+			return token.Token{Kind: token.TokenKindInl, Value: ident, Pos: pos}, nil
+		case "inr":
+			return token.Token{Kind: token.TokenKindInr, Value: ident, Pos: pos}, nil
+		case "as":
+			return token.Token{Kind: token.TokenKindAs, Value: ident, Pos: pos}, nil
+		case "case":
+			return token.Token{Kind: token.TokenKindCase, Value: ident, Pos: pos}, nil
+		case "of":
+			return token.Token{Kind: token.TokenKindOf, Value: ident, Pos: pos}, nil
+		case "forall":
+			return token.Token{Kind: token.TokenKindForall, Value: ident, Pos: pos}, nil
+		case "nil":
+			return token.Token{Kind: token.TokenKindNil, Value: ident, Pos: pos}, nil
+		case "type":
+			return token.Token{Kind: token.TokenKindType, Value: ident, Pos: pos}, nil
+		case "match":
+			return token.Token{Kind: token.TokenKindMatch, Value: ident, Pos: pos}, nil
+		case "with":
+			return token.Token{Kind: token.TokenKindWith, Value: ident, Pos: pos}, nil
+		case "mu":
+			return token.Token{Kind: token.TokenKindMu, Value: ident, Pos: pos}, nil
+		case "fold":
+			return token.Token{Kind: token.TokenKindFold, Value: ident, Pos: pos}, nil
+		case "unfold":
+			return token.Token{Kind: token.TokenKindUnfold, Value: ident, Pos: pos}, nil
 		case "Bool":
 			return token.Token{Kind: token.TokenKindBoolType, Value: ident, Pos: pos}, nil
 		case "Int":
 			return token.Token{Kind: token.TokenKindIntType, Value: ident, Pos: pos}, nil
+		case "Unit":
+			return token.Token{Kind: token.TokenKindUnitType, Value: ident, Pos: pos}, nil
+		case "String":
+			return token.Token{Kind: token.TokenKindStringType, Value: ident, Pos: pos}, nil
+		case "Char":
+			return token.Token{Kind: token.TokenKindCharType, Value: ident, Pos: pos}, nil
+		case "Float":
+			return token.Token{Kind: token.TokenKindFloatType, Value: ident, Pos: pos}, nil
+		case "List":
+			return token.Token{Kind: token.TokenKindListType, Value: ident, Pos: pos}, nil
 		default:
 			return token.Token{
 				Kind:  token.TokenKindIdent,
@@ -199,22 +384,84 @@ func (l *Lexer) Next() (token.Token, error) {
 	}
 }
 
-func (l *Lexer) skipWhitespace() {
+func (l *Lexer) skipWhitespace() error {
 	for {
-		ch, _, err := l.reader.Peek()
+		ch, pos, err := l.reader.Peek()
 		if err != nil {
-			if err == io.EOF {
-				return
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return &LexerError{
+				message: "read character",
+				pos:     pos,
+				err:     err,
 			}
-			log.Fatal(err)
 		}
 		if ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' {
-			return
+			return nil
 		}
 		_, _, _ = l.reader.Read() // ignore error because we already peeked
+		l.operandEnd = false
 	}
 }
 
+// readLine reads the rest of the line, leaving the line break unread.
+func (l *Lexer) readLine() string {
+	var b strings.Builder
+	for {
+		next, _, err := l.reader.Peek()
+		if err != nil || next == '\n' {
+			break
+		}
+		b.WriteRune(next)
+		_, _, _ = l.reader.Read() // ignore error because we already peeked
+	}
+
+	return strings.TrimSuffix(b.String(), "\r")
+}
+
+// readBlockComment reads a block comment after its opening '{-' at pos.
+// Block comments nest, so the comment ends at the '-}' matching its opening.
+func (l *Lexer) readBlockComment(pos token.Position) (token.Token, error) {
+	var b strings.Builder
+	b.WriteString("{-")
+	depth := 1
+	for depth > 0 {
+		ch, chPos, err := l.reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return token.Token{}, &LexerError{
+					message: "unterminated block comment",
+					pos:     pos,
+				}
+			}
+			return token.Token{}, &LexerError{
+				message: "read character",
+				pos:     chPos,
+				err:     err,
+			}
+		}
+		b.WriteRune(ch)
+
+		next, _, err := l.reader.Peek()
+		if err != nil {
+			continue
+		}
+		switch {
+		case ch == '{' && next == '-':
+			depth++
+		case ch == '-' && next == '}':
+			depth--
+		default:
+			continue
+		}
+		b.WriteRune(next)
+		_, _, _ = l.reader.Read() // ignore error because we already peeked
+	}
+
+	return token.Token{Kind: token.TokenKindComment, Value: b.String(), Pos: pos}, nil
+}
+
 func (l *Lexer) readInteger(ch rune) string {
 	var b strings.Builder
 	b.WriteRune(ch)
@@ -233,6 +480,83 @@ func (l *Lexer) readInteger(ch rune) string {
 	return b.String()
 }
 
+// readNumber reads an integer literal, or a float literal with a fractional part such as 1.5
+// unless the number is a tuple index.
+func (l *Lexer) readNumber(ch rune, pos token.Position) (token.Token, error) {
+	integer := l.readInteger(ch)
+	if next, _, err := l.reader.Peek(); err != nil || next != '.' || l.afterDot {
+		return token.Token{Kind: token.TokenKindInt, Value: integer, Pos: pos}, nil
+	}
+	_, _, _ = l.reader.Read() // ignore error because we already peeked
+
+	next, nextPos, err := l.reader.Peek()
+	if err != nil || !isDigit(next) {
+		return token.Token{}, &LexerError{
+			message: "expected digit after '.' in float literal",
+			pos:     nextPos,
+		}
+	}
+	_, _, _ = l.reader.Read() // ignore error because we already peeked
+	return token.Token{Kind: token.TokenKindFloat, Value: integer + "." + l.readInteger(next), Pos: pos}, nil
+}
+
+// readQuoted reads a string or character literal after its opening quote at pos.
+// The value of the token is the literal as written, and its escape sequences must be valid.
+func (l *Lexer) readQuoted(quote rune, pos token.Position, kind token.TokenKind) (token.Token, error) {
+	name := "string"
+	if kind == token.TokenKindChar {
+		name = "character"
+	}
+
+	var b strings.Builder
+	b.WriteRune(quote)
+	for {
+		ch, chPos, err := l.reader.Peek()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return token.Token{}, &LexerError{
+				message: "read character",
+				pos:     chPos,
+				err:     err,
+			}
+		}
+		if err != nil || ch == '\n' {
+			return token.Token{}, &LexerError{
+				message: "unterminated " + name + " literal",
+				pos:     pos,
+			}
+		}
+		_, _, _ = l.reader.Read() // ignore error because we already peeked
+		b.WriteRune(ch)
+		if ch == quote {
+			break
+		}
+		// The character after a backslash never closes the literal
+		if ch == '\\' {
+			if next, _, err := l.reader.Peek(); err == nil && next != '\n' {
+				_, _, _ = l.reader.Read()
+				b.WriteRune(next)
+			}
+		}
+	}
+
+	lit := b.String()
+	s, err := token.Unquote(lit)
+	if err != nil {
+		return token.Token{}, &LexerError{
+			message: "invalid " + name + " literal",
+			pos:     pos,
+			err:     err,
+		}
+	}
+	if kind == token.TokenKindChar && utf8.RuneCountInString(s) != 1 {
+		return token.Token{}, &LexerError{
+			message: "character literal must contain exactly one character",
+			pos:     pos,
+		}
+	}
+	return token.Token{Kind: kind, Value: lit, Pos: pos}, nil
+}
+
 func (l *Lexer) readIdentifier(ch rune) string {
 	var b strings.Builder
 	b.WriteRune(ch)
@@ -241,7 +565,7 @@ func (l *Lexer) readIdentifier(ch rune) string {
 		if err != nil {
 			break
 		}
-		if !isAlphanumericOrUnderscore(next) {
+		if !isIdentPart(next) {
 			break
 		}
 		b.WriteRune(next)
@@ -251,14 +575,30 @@ func (l *Lexer) readIdentifier(ch rune) string {
 	return b.String()
 }
 
+// endsOperand reports whether a token of kind can end an operand of an infix operator.
+func endsOperand(kind token.TokenKind) bool {
+	switch kind {
+	case token.TokenKindIdent, token.TokenKindInt, token.TokenKindTrue, token.TokenKindFalse,
+		token.TokenKindFloat, token.TokenKindString, token.TokenKindChar,
+		token.TokenKindRParen, token.TokenKindRBracket, token.TokenKindRBrace:
+		return true
+	default:
+		return false
+	}
+}
+
 func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func isAlphabetOrUnderscore(ch rune) bool {
-	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch == '_'
+// isIdentStart reports whether ch can start an identifier: a Unicode letter or an underscore.
+// λ and Λ are excluded, as they stand for abstractions.
+func isIdentStart(ch rune) bool {
+	return (unicode.IsLetter(ch) || ch == '_') && ch != 'λ' && ch != 'Λ'
 }
 
-func isAlphanumericOrUnderscore(ch rune) bool {
-	return isAlphabetOrUnderscore(ch) || ('0' <= ch && ch <= '9')
+// isIdentPart reports whether ch can continue an identifier:
+// additionally a digit, another numeric character such as a subscript, or a prime.
+func isIdentPart(ch rune) bool {
+	return isIdentStart(ch) || unicode.IsNumber(ch) || ch == '\''
 }
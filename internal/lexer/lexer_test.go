@@ -2,7 +2,11 @@ package lexer_test
 
 import (
 	"errors"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/shota3506/gostlc/internal/lexer"
 	"github.com/shota3506/gostlc/internal/token"
@@ -18,182 +22,479 @@ func TestLexer(t *testing.T) {
 			name:  "Identity function",
 			input: `(\x:Bool. x) true`,
 			expected: []token.Token{
-				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 2}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 3}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Line: 1, Column: 5}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 9}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 11}},
-				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Line: 1, Column: 12}},
-				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Line: 1, Column: 14}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 18}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
 			},
 		},
 		{
 			name:  "Identity function with integer",
 			input: `(\x:Int. x) 42`,
 			expected: []token.Token{
-				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 2}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 3}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Line: 1, Column: 5}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 8}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 10}},
-				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Line: 1, Column: 11}},
-				{Kind: token.TokenKindInt, Value: "42", Pos: token.Position{Line: 1, Column: 13}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 15}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindInt, Value: "42", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
 			},
 		},
 		{
 			name:  "Constant function",
 			input: `(\x:Int. \y:Int. x) 10 20`,
 			expected: []token.Token{
-				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 2}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 3}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Line: 1, Column: 5}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 8}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 10}},
-				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Line: 1, Column: 11}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 12}},
-				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Line: 1, Column: 13}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 16}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 18}},
-				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Line: 1, Column: 19}},
-				{Kind: token.TokenKindInt, Value: "10", Pos: token.Position{Line: 1, Column: 21}},
-				{Kind: token.TokenKindInt, Value: "20", Pos: token.Position{Line: 1, Column: 24}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 26}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindInt, Value: "10", Pos: token.Position{Offset: 20, Line: 1, Column: 21}},
+				{Kind: token.TokenKindInt, Value: "20", Pos: token.Position{Offset: 23, Line: 1, Column: 24}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 25, Line: 1, Column: 26}},
 			},
 		},
 		{
 			name:  "Boolean literals true",
 			input: `true`,
 			expected: []token.Token{
-				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 5}},
+				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
 			},
 		},
 		{
 			name:  "Boolean literals false",
 			input: `false`,
 			expected: []token.Token{
-				{Kind: token.TokenKindFalse, Value: "false", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 6}},
+				{Kind: token.TokenKindFalse, Value: "false", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
 			},
 		},
 		{
 			name:  "Simple conditional",
 			input: `if true then 1 else 0`,
 			expected: []token.Token{
-				{Kind: token.TokenKindIf, Value: "if", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindThen, Value: "then", Pos: token.Position{Line: 1, Column: 9}},
-				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Line: 1, Column: 14}},
-				{Kind: token.TokenKindElse, Value: "else", Pos: token.Position{Line: 1, Column: 16}},
-				{Kind: token.TokenKindInt, Value: "0", Pos: token.Position{Line: 1, Column: 21}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 22}},
+				{Kind: token.TokenKindIf, Value: "if", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindThen, Value: "then", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindElse, Value: "else", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindInt, Value: "0", Pos: token.Position{Offset: 20, Line: 1, Column: 21}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 21, Line: 1, Column: 22}},
 			},
 		},
 		{
 			name:  "Conditional with false condition",
 			input: `if false then 100 else 200`,
 			expected: []token.Token{
-				{Kind: token.TokenKindIf, Value: "if", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindFalse, Value: "false", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindThen, Value: "then", Pos: token.Position{Line: 1, Column: 10}},
-				{Kind: token.TokenKindInt, Value: "100", Pos: token.Position{Line: 1, Column: 15}},
-				{Kind: token.TokenKindElse, Value: "else", Pos: token.Position{Line: 1, Column: 19}},
-				{Kind: token.TokenKindInt, Value: "200", Pos: token.Position{Line: 1, Column: 24}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 27}},
+				{Kind: token.TokenKindIf, Value: "if", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindFalse, Value: "false", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindThen, Value: "then", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindInt, Value: "100", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+				{Kind: token.TokenKindElse, Value: "else", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindInt, Value: "200", Pos: token.Position{Offset: 23, Line: 1, Column: 24}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 26, Line: 1, Column: 27}},
 			},
 		},
 		{
 			name:  "Nested function application",
 			input: `(\x:Bool. \y:Bool. x) true false`,
 			expected: []token.Token{
-				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 2}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 3}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Line: 1, Column: 5}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 9}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 11}},
-				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Line: 1, Column: 12}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 13}},
-				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Line: 1, Column: 14}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 18}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 20}},
-				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Line: 1, Column: 21}},
-				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Line: 1, Column: 23}},
-				{Kind: token.TokenKindFalse, Value: "false", Pos: token.Position{Line: 1, Column: 28}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 33}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 19, Line: 1, Column: 20}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 20, Line: 1, Column: 21}},
+				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Offset: 22, Line: 1, Column: 23}},
+				{Kind: token.TokenKindFalse, Value: "false", Pos: token.Position{Offset: 27, Line: 1, Column: 28}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 32, Line: 1, Column: 33}},
 			},
 		},
 		{
 			name:  "Select second argument",
 			input: `(\x:Int. \y:Int. y) 5 7`,
 			expected: []token.Token{
-				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 2}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 3}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Line: 1, Column: 5}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 8}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 10}},
-				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Line: 1, Column: 11}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 12}},
-				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Line: 1, Column: 13}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 16}},
-				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Line: 1, Column: 18}},
-				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Line: 1, Column: 19}},
-				{Kind: token.TokenKindInt, Value: "5", Pos: token.Position{Line: 1, Column: 21}},
-				{Kind: token.TokenKindInt, Value: "7", Pos: token.Position{Line: 1, Column: 23}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 24}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindInt, Value: "5", Pos: token.Position{Offset: 20, Line: 1, Column: 21}},
+				{Kind: token.TokenKindInt, Value: "7", Pos: token.Position{Offset: 22, Line: 1, Column: 23}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 23, Line: 1, Column: 24}},
 			},
 		},
 		{
 			name:  "Apply identity to itself",
 			input: `(\f:Bool->Bool. f true) (\x:Bool. x)`,
 			expected: []token.Token{
-				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 2}},
-				{Kind: token.TokenKindIdent, Value: "f", Pos: token.Position{Line: 1, Column: 3}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 4}},
-				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Line: 1, Column: 5}},
-				{Kind: token.TokenKindArrow, Value: "->", Pos: token.Position{Line: 1, Column: 9}},
-				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Line: 1, Column: 11}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 15}},
-				{Kind: token.TokenKindIdent, Value: "f", Pos: token.Position{Line: 1, Column: 17}},
-				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Line: 1, Column: 19}},
-				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Line: 1, Column: 23}},
-				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Line: 1, Column: 25}},
-				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Line: 1, Column: 26}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 27}},
-				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Line: 1, Column: 28}},
-				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Line: 1, Column: 29}},
-				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Line: 1, Column: 33}},
-				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Line: 1, Column: 35}},
-				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Line: 1, Column: 36}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 37}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindIdent, Value: "f", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindArrow, Value: "->", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+				{Kind: token.TokenKindIdent, Value: "f", Pos: token.Position{Offset: 16, Line: 1, Column: 17}},
+				{Kind: token.TokenKindTrue, Value: "true", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 22, Line: 1, Column: 23}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 24, Line: 1, Column: 25}},
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 25, Line: 1, Column: 26}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 26, Line: 1, Column: 27}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 27, Line: 1, Column: 28}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 28, Line: 1, Column: 29}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 32, Line: 1, Column: 33}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 34, Line: 1, Column: 35}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 35, Line: 1, Column: 36}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 36, Line: 1, Column: 37}},
 			},
 		},
 		{
 			name:  "Simple integer literal",
 			input: `42`,
 			expected: []token.Token{
-				{Kind: token.TokenKindInt, Value: "42", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 3}},
+				{Kind: token.TokenKindInt, Value: "42", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
 			},
 		},
 		{
 			name:  "Negative integer literal",
 			input: `-123`,
 			expected: []token.Token{
-				{Kind: token.TokenKindInt, Value: "-123", Pos: token.Position{Line: 1, Column: 1}},
-				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Line: 1, Column: 5}},
+				{Kind: token.TokenKindInt, Value: "-123", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+			},
+		},
+		{
+			name:  "Infix operators",
+			input: `a+b-c*d/e%f==g!=h<i<=j>k>=l&&m||!n`,
+			expected: []token.Token{
+				{Kind: token.TokenKindIdent, Value: "a", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindPlus, Value: "+", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindIdent, Value: "b", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindMinus, Value: "-", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindIdent, Value: "c", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindStar, Value: "*", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Kind: token.TokenKindIdent, Value: "d", Pos: token.Position{Offset: 6, Line: 1, Column: 7}},
+				{Kind: token.TokenKindSlash, Value: "/", Pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Kind: token.TokenKindIdent, Value: "e", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindPercent, Value: "%", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindIdent, Value: "f", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindEqualEqual, Value: "==", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindIdent, Value: "g", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindNotEqual, Value: "!=", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+				{Kind: token.TokenKindIdent, Value: "h", Pos: token.Position{Offset: 16, Line: 1, Column: 17}},
+				{Kind: token.TokenKindLAngle, Value: "<", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindIdent, Value: "i", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindLessEqual, Value: "<=", Pos: token.Position{Offset: 19, Line: 1, Column: 20}},
+				{Kind: token.TokenKindIdent, Value: "j", Pos: token.Position{Offset: 21, Line: 1, Column: 22}},
+				{Kind: token.TokenKindRAngle, Value: ">", Pos: token.Position{Offset: 22, Line: 1, Column: 23}},
+				{Kind: token.TokenKindIdent, Value: "k", Pos: token.Position{Offset: 23, Line: 1, Column: 24}},
+				{Kind: token.TokenKindGreaterEqual, Value: ">=", Pos: token.Position{Offset: 24, Line: 1, Column: 25}},
+				{Kind: token.TokenKindIdent, Value: "l", Pos: token.Position{Offset: 26, Line: 1, Column: 27}},
+				{Kind: token.TokenKindAndAnd, Value: "&&", Pos: token.Position{Offset: 27, Line: 1, Column: 28}},
+				{Kind: token.TokenKindIdent, Value: "m", Pos: token.Position{Offset: 29, Line: 1, Column: 30}},
+				{Kind: token.TokenKindOrOr, Value: "||", Pos: token.Position{Offset: 30, Line: 1, Column: 31}},
+				{Kind: token.TokenKindBang, Value: "!", Pos: token.Position{Offset: 32, Line: 1, Column: 33}},
+				{Kind: token.TokenKindIdent, Value: "n", Pos: token.Position{Offset: 33, Line: 1, Column: 34}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 34, Line: 1, Column: 35}},
+			},
+		},
+		{
+			name:  "Negative literals and subtraction",
+			input: `n-1 f -1 (2)-3 x - 4 -5`,
+			expected: []token.Token{
+				{Kind: token.TokenKindIdent, Value: "n", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindMinus, Value: "-", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindIdent, Value: "f", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindInt, Value: "-1", Pos: token.Position{Offset: 6, Line: 1, Column: 7}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindInt, Value: "2", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindMinus, Value: "-", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindInt, Value: "3", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindMinus, Value: "-", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindInt, Value: "4", Pos: token.Position{Offset: 19, Line: 1, Column: 20}},
+				{Kind: token.TokenKindInt, Value: "-5", Pos: token.Position{Offset: 21, Line: 1, Column: 22}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 23, Line: 1, Column: 24}},
+			},
+		},
+		{
+			name:  "Literals",
+			input: `"a\"b\n" 'c' '\'' 1.5 -0.25 p.1.2 ()`,
+			expected: []token.Token{
+				{Kind: token.TokenKindString, Value: `"a\"b\n"`, Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindChar, Value: `'c'`, Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindChar, Value: `'\''`, Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindFloat, Value: "1.5", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindFloat, Value: "-0.25", Pos: token.Position{Offset: 22, Line: 1, Column: 23}},
+				{Kind: token.TokenKindIdent, Value: "p", Pos: token.Position{Offset: 28, Line: 1, Column: 29}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 29, Line: 1, Column: 30}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 30, Line: 1, Column: 31}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 31, Line: 1, Column: 32}},
+				{Kind: token.TokenKindInt, Value: "2", Pos: token.Position{Offset: 32, Line: 1, Column: 33}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 34, Line: 1, Column: 35}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 35, Line: 1, Column: 36}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 36, Line: 1, Column: 37}},
+			},
+		},
+		{
+			name:  "Base type keywords",
+			input: `Unit String Char Float`,
+			expected: []token.Token{
+				{Kind: token.TokenKindUnitType, Value: "Unit", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindStringType, Value: "String", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Kind: token.TokenKindCharType, Value: "Char", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindFloatType, Value: "Float", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 22, Line: 1, Column: 23}},
+			},
+		},
+		{
+			name:  "Lists",
+			input: `nil[List Int] :: x::xs`,
+			expected: []token.Token{
+				{Kind: token.TokenKindNil, Value: "nil", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLBracket, Value: "[", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindListType, Value: "List", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindRBracket, Value: "]", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindColonColon, Value: "::", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindColonColon, Value: "::", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindIdent, Value: "xs", Pos: token.Position{Offset: 20, Line: 1, Column: 21}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 22, Line: 1, Column: 23}},
+			},
+		},
+		{
+			name:  "Data types and matches",
+			input: "type T = A Int | B\nmatch t with | A _ -> -1",
+			expected: []token.Token{
+				{Kind: token.TokenKindType, Value: "type", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "T", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Kind: token.TokenKindEqual, Value: "=", Pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Kind: token.TokenKindIdent, Value: "A", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindBar, Value: "|", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindIdent, Value: "B", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindMatch, Value: "match", Pos: token.Position{Offset: 19, Line: 2, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "t", Pos: token.Position{Offset: 25, Line: 2, Column: 7}},
+				{Kind: token.TokenKindWith, Value: "with", Pos: token.Position{Offset: 27, Line: 2, Column: 9}},
+				{Kind: token.TokenKindBar, Value: "|", Pos: token.Position{Offset: 32, Line: 2, Column: 14}},
+				{Kind: token.TokenKindIdent, Value: "A", Pos: token.Position{Offset: 34, Line: 2, Column: 16}},
+				{Kind: token.TokenKindIdent, Value: "_", Pos: token.Position{Offset: 36, Line: 2, Column: 18}},
+				{Kind: token.TokenKindArrow, Value: "->", Pos: token.Position{Offset: 38, Line: 2, Column: 20}},
+				{Kind: token.TokenKindInt, Value: "-1", Pos: token.Position{Offset: 41, Line: 2, Column: 23}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 43, Line: 2, Column: 25}},
+			},
+		},
+		{
+			name:  "Recursive types",
+			input: "fold [mu X. X] (unfold [T] x)",
+			expected: []token.Token{
+				{Kind: token.TokenKindFold, Value: "fold", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindLBracket, Value: "[", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Kind: token.TokenKindMu, Value: "mu", Pos: token.Position{Offset: 6, Line: 1, Column: 7}},
+				{Kind: token.TokenKindIdent, Value: "X", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindIdent, Value: "X", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindRBracket, Value: "]", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindUnfold, Value: "unfold", Pos: token.Position{Offset: 16, Line: 1, Column: 17}},
+				{Kind: token.TokenKindLBracket, Value: "[", Pos: token.Position{Offset: 23, Line: 1, Column: 24}},
+				{Kind: token.TokenKindIdent, Value: "T", Pos: token.Position{Offset: 24, Line: 1, Column: 25}},
+				{Kind: token.TokenKindRBracket, Value: "]", Pos: token.Position{Offset: 25, Line: 1, Column: 26}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 27, Line: 1, Column: 28}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 28, Line: 1, Column: 29}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 29, Line: 1, Column: 30}},
+			},
+		},
+		{
+			name:  "Let binding",
+			input: `let x = 1 in x`,
+			expected: []token.Token{
+				{Kind: token.TokenKindLet, Value: "let", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 4, Line: 1, Column: 5}},
+				{Kind: token.TokenKindEqual, Value: "=", Pos: token.Position{Offset: 6, Line: 1, Column: 7}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindIn, Value: "in", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+			},
+		},
+		{
+			name:  "Tuple and product type",
+			input: `\p:Int*Bool. (p.1, p)`,
+			expected: []token.Token{
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "p", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindStar, Value: "*", Pos: token.Position{Offset: 6, Line: 1, Column: 7}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindLParen, Value: "(", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+				{Kind: token.TokenKindIdent, Value: "p", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 16, Line: 1, Column: 17}},
+				{Kind: token.TokenKindComma, Value: ",", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindIdent, Value: "p", Pos: token.Position{Offset: 19, Line: 1, Column: 20}},
+				{Kind: token.TokenKindRParen, Value: ")", Pos: token.Position{Offset: 20, Line: 1, Column: 21}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 21, Line: 1, Column: 22}},
+			},
+		},
+		{
+			name:  "Case analysis",
+			input: `case inl 1 as Int+Bool of inl x => x | inr y => 0`,
+			expected: []token.Token{
+				{Kind: token.TokenKindCase, Value: "case", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindInl, Value: "inl", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 9, Line: 1, Column: 10}},
+				{Kind: token.TokenKindAs, Value: "as", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+				{Kind: token.TokenKindPlus, Value: "+", Pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+				{Kind: token.TokenKindBoolType, Value: "Bool", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindOf, Value: "of", Pos: token.Position{Offset: 23, Line: 1, Column: 24}},
+				{Kind: token.TokenKindInl, Value: "inl", Pos: token.Position{Offset: 26, Line: 1, Column: 27}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 30, Line: 1, Column: 31}},
+				{Kind: token.TokenKindFatArrow, Value: "=>", Pos: token.Position{Offset: 32, Line: 1, Column: 33}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 35, Line: 1, Column: 36}},
+				{Kind: token.TokenKindBar, Value: "|", Pos: token.Position{Offset: 37, Line: 1, Column: 38}},
+				{Kind: token.TokenKindInr, Value: "inr", Pos: token.Position{Offset: 39, Line: 1, Column: 40}},
+				{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Offset: 43, Line: 1, Column: 44}},
+				{Kind: token.TokenKindFatArrow, Value: "=>", Pos: token.Position{Offset: 45, Line: 1, Column: 46}},
+				{Kind: token.TokenKindInt, Value: "0", Pos: token.Position{Offset: 48, Line: 1, Column: 49}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 49, Line: 1, Column: 50}},
+			},
+		},
+		{
+			name:  "Record and variant",
+			input: `{x = <a = 1>}`,
+			expected: []token.Token{
+				{Kind: token.TokenKindLBrace, Value: "{", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindEqual, Value: "=", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindLAngle, Value: "<", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Kind: token.TokenKindIdent, Value: "a", Pos: token.Position{Offset: 6, Line: 1, Column: 7}},
+				{Kind: token.TokenKindEqual, Value: "=", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 10, Line: 1, Column: 11}},
+				{Kind: token.TokenKindRAngle, Value: ">", Pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+				{Kind: token.TokenKindRBrace, Value: "}", Pos: token.Position{Offset: 12, Line: 1, Column: 13}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 13, Line: 1, Column: 14}},
+			},
+		},
+		{
+			name:  "Type abstraction and application",
+			input: `/\A. f [forall B. B]`,
+			expected: []token.Token{
+				{Kind: token.TokenKindTyLambda, Value: `/\`, Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "A", Pos: token.Position{Offset: 2, Line: 1, Column: 3}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindIdent, Value: "f", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				{Kind: token.TokenKindLBracket, Value: "[", Pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+				{Kind: token.TokenKindForall, Value: "forall", Pos: token.Position{Offset: 8, Line: 1, Column: 9}},
+				{Kind: token.TokenKindIdent, Value: "B", Pos: token.Position{Offset: 15, Line: 1, Column: 16}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 16, Line: 1, Column: 17}},
+				{Kind: token.TokenKindIdent, Value: "B", Pos: token.Position{Offset: 18, Line: 1, Column: 19}},
+				{Kind: token.TokenKindRBracket, Value: "]", Pos: token.Position{Offset: 19, Line: 1, Column: 20}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 20, Line: 1, Column: 21}},
+			},
+		},
+		{
+			name:  "Line comments",
+			input: "-- identity\n\\x:Int->Int. x -- body\n-1--end",
+			expected: []token.Token{
+				{Kind: token.TokenKindLambda, Value: "\\", Pos: token.Position{Offset: 12, Line: 2, Column: 1}, Trivia: []token.Token{
+					{Kind: token.TokenKindComment, Value: "-- identity", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 13, Line: 2, Column: 2}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 14, Line: 2, Column: 3}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 15, Line: 2, Column: 4}},
+				{Kind: token.TokenKindArrow, Value: "->", Pos: token.Position{Offset: 18, Line: 2, Column: 7}},
+				{Kind: token.TokenKindIntType, Value: "Int", Pos: token.Position{Offset: 20, Line: 2, Column: 9}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 23, Line: 2, Column: 12}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 25, Line: 2, Column: 14}},
+				{Kind: token.TokenKindInt, Value: "-1", Pos: token.Position{Offset: 35, Line: 3, Column: 1}, Trivia: []token.Token{
+					{Kind: token.TokenKindComment, Value: "-- body", Pos: token.Position{Offset: 27, Line: 2, Column: 16}},
+				}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 42, Line: 3, Column: 8}, Trivia: []token.Token{
+					{Kind: token.TokenKindComment, Value: "--end", Pos: token.Position{Offset: 37, Line: 3, Column: 3}},
+				}},
+			},
+		},
+		{
+			name:  "Nested block comments",
+			input: "{x = {- a {- nested -} comment\n-} 1} {--}",
+			expected: []token.Token{
+				{Kind: token.TokenKindLBrace, Value: "{", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+				{Kind: token.TokenKindEqual, Value: "=", Pos: token.Position{Offset: 3, Line: 1, Column: 4}},
+				{Kind: token.TokenKindInt, Value: "1", Pos: token.Position{Offset: 34, Line: 2, Column: 4}, Trivia: []token.Token{
+					{Kind: token.TokenKindComment, Value: "{- a {- nested -} comment\n-}", Pos: token.Position{Offset: 5, Line: 1, Column: 6}},
+				}},
+				{Kind: token.TokenKindRBrace, Value: "}", Pos: token.Position{Offset: 35, Line: 2, Column: 5}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 41, Line: 2, Column: 11}, Trivia: []token.Token{
+					{Kind: token.TokenKindComment, Value: "{--}", Pos: token.Position{Offset: 37, Line: 2, Column: 7}},
+				}},
+			},
+		},
+		{
+			name:  "Unicode syntax",
+			input: "ΛA. λx₁:∀B. B→A. f' x₁ α_2",
+			expected: []token.Token{
+				{Kind: token.TokenKindTyLambda, Value: "Λ", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: token.TokenKindIdent, Value: "A", Pos: token.Position{Offset: 2, Line: 1, Column: 2}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 3, Line: 1, Column: 3}},
+				{Kind: token.TokenKindLambda, Value: "λ", Pos: token.Position{Offset: 5, Line: 1, Column: 5}},
+				{Kind: token.TokenKindIdent, Value: "x₁", Pos: token.Position{Offset: 7, Line: 1, Column: 6}},
+				{Kind: token.TokenKindColon, Value: ":", Pos: token.Position{Offset: 11, Line: 1, Column: 8}},
+				{Kind: token.TokenKindForall, Value: "∀", Pos: token.Position{Offset: 12, Line: 1, Column: 9}},
+				{Kind: token.TokenKindIdent, Value: "B", Pos: token.Position{Offset: 15, Line: 1, Column: 10}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 16, Line: 1, Column: 11}},
+				{Kind: token.TokenKindIdent, Value: "B", Pos: token.Position{Offset: 18, Line: 1, Column: 13}},
+				{Kind: token.TokenKindArrow, Value: "→", Pos: token.Position{Offset: 19, Line: 1, Column: 14}},
+				{Kind: token.TokenKindIdent, Value: "A", Pos: token.Position{Offset: 22, Line: 1, Column: 15}},
+				{Kind: token.TokenKindDot, Value: ".", Pos: token.Position{Offset: 23, Line: 1, Column: 16}},
+				{Kind: token.TokenKindIdent, Value: "f'", Pos: token.Position{Offset: 25, Line: 1, Column: 18}},
+				{Kind: token.TokenKindIdent, Value: "x₁", Pos: token.Position{Offset: 28, Line: 1, Column: 21}},
+				{Kind: token.TokenKindIdent, Value: "α_2", Pos: token.Position{Offset: 33, Line: 1, Column: 24}},
+				{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Offset: 37, Line: 1, Column: 27}},
 			},
 		},
 	} {
@@ -214,7 +515,7 @@ func TestLexer(t *testing.T) {
 				t.Fatalf("expected %d tokens, got %d", len(tt.expected), len(tokens))
 			}
 			for i, expectedTok := range tt.expected {
-				if tokens[i] != expectedTok {
+				if !reflect.DeepEqual(tokens[i], expectedTok) {
 					t.Errorf("token %d: expected %+v, got %+v", i, expectedTok, tokens[i])
 				}
 			}
@@ -242,28 +543,10 @@ func TestLexerErrors(t *testing.T) {
 			expectedPos:   token.Position{Line: 1, Column: 6},
 		},
 		{
-			name:          "Single dash without arrow",
-			input:         `- 5`,
-			expectedError: `1:2: unexpected character after '-': ' '`,
-			expectedPos:   token.Position{Line: 1, Column: 2},
-		},
-		{
-			name:          "Dash at end of input",
-			input:         `(\x:Int. x) -`,
-			expectedError: `1:14: unexpected eof after '-'`,
-			expectedPos:   token.Position{Line: 1, Column: 14},
-		},
-		{
-			name:          "Unexpected character after dash",
-			input:         `-a`,
-			expectedError: `1:2: unexpected character after '-': 'a'`,
-			expectedPos:   token.Position{Line: 1, Column: 2},
-		},
-		{
-			name:          "Multiple unexpected characters",
+			name:          "Single ampersand",
 			input:         `true & false`,
-			expectedError: `1:6: unexpected character: '&'`,
-			expectedPos:   token.Position{Line: 1, Column: 6},
+			expectedError: `1:7: unexpected character after '&': ' '`,
+			expectedPos:   token.Position{Line: 1, Column: 7},
 		},
 		{
 			name:          "Unexpected character on new line",
@@ -273,20 +556,56 @@ func TestLexerErrors(t *testing.T) {
 		},
 		{
 			name:          "Unexpected character after whitespace",
-			input:         `   !`,
-			expectedError: `1:4: unexpected character: '!'`,
+			input:         `   ?`,
+			expectedError: `1:4: unexpected character: '?'`,
 			expectedPos:   token.Position{Line: 1, Column: 4},
 		},
 		{
-			name:          "Unicode character",
-			input:         `λ`,
-			expectedError: `1:1: unexpected character: 'λ'`,
+			name:          "Unicode symbol",
+			input:         `§`,
+			expectedError: `1:1: unexpected character: '§'`,
 			expectedPos:   token.Position{Line: 1, Column: 1},
 		},
+		{
+			name:          "Unterminated block comment",
+			input:         "1 {- a {- b -}\n",
+			expectedError: `1:3: unterminated block comment`,
+			expectedPos:   token.Position{Line: 1, Column: 3},
+		},
+		{
+			name:          "Unterminated string literal",
+			input:         "f \"abc\n\"",
+			expectedError: `1:3: unterminated string literal`,
+			expectedPos:   token.Position{Line: 1, Column: 3},
+		},
+		{
+			name:          "Unknown escape sequence",
+			input:         `"a\qb"`,
+			expectedError: `1:1: invalid string literal: unknown escape sequence: \q`,
+			expectedPos:   token.Position{Line: 1, Column: 1},
+		},
+		{
+			name:          "Invalid code point",
+			input:         `'\u{110000}'`,
+			expectedError: `1:1: invalid character literal: invalid code point in escape sequence: \u{110000}`,
+			expectedPos:   token.Position{Line: 1, Column: 1},
+		},
+		{
+			name:          "Character literal of two characters",
+			input:         `'ab'`,
+			expectedError: `1:1: character literal must contain exactly one character`,
+			expectedPos:   token.Position{Line: 1, Column: 1},
+		},
+		{
+			name:          "Float literal without fractional digits",
+			input:         `1.x`,
+			expectedError: `1:3: expected digit after '.' in float literal`,
+			expectedPos:   token.Position{Line: 1, Column: 3},
+		},
 		{
 			name:          "Unexpected character in expression",
-			input:         `(\x:Bool. x) % true`,
-			expectedError: `1:14: unexpected character: '%'`,
+			input:         `(\x:Bool. x) ^ true`,
+			expectedError: `1:14: unexpected character: '^'`,
 			expectedPos:   token.Position{Line: 1, Column: 14},
 		},
 	} {
@@ -313,3 +632,45 @@ func TestLexerErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestNewReader(t *testing.T) {
+	// A reader without ReadRune, returning a multibyte character one byte at a time
+	l := lexer.NewReader(iotest.OneByteReader(strings.NewReader("{- λ -} x\n  y")), "main.stlc")
+
+	expected := []token.Token{
+		{Kind: token.TokenKindIdent, Value: "x", Pos: token.Position{Filename: "main.stlc", Offset: 9, Line: 1, Column: 9}, Trivia: []token.Token{
+			{Kind: token.TokenKindComment, Value: "{- λ -}", Pos: token.Position{Filename: "main.stlc", Offset: 0, Line: 1, Column: 1}},
+		}},
+		{Kind: token.TokenKindIdent, Value: "y", Pos: token.Position{Filename: "main.stlc", Offset: 13, Line: 2, Column: 3}},
+		{Kind: token.TokenKindEOF, Value: "", Pos: token.Position{Filename: "main.stlc", Offset: 14, Line: 2, Column: 4}},
+	}
+	for i, expectedTok := range expected {
+		tok, err := l.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(tok, expectedTok) {
+			t.Errorf("token %d: expected %+v, got %+v", i, expectedTok, tok)
+		}
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	errRead := errors.New("connection reset")
+	l := lexer.NewReader(io.MultiReader(strings.NewReader("x  "), iotest.ErrReader(errRead)), "")
+
+	if _, err := l.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := l.Next()
+	var lexErr *lexer.LexerError
+	if !errors.As(err, &lexErr) {
+		t.Fatalf("expected *lexer.LexerError, got %T", err)
+	}
+	if !errors.Is(err, errRead) {
+		t.Errorf("expected error wrapping %v, got %v", errRead, err)
+	}
+	if expected := "1:4: read character: connection reset"; err.Error() != expected {
+		t.Errorf("error message: expected %q, got %q", expected, err.Error())
+	}
+}
@@ -0,0 +1,510 @@
+package lsp
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/builtin"
+	"github.com/shota3506/gostlc/internal/diagnostics"
+	"github.com/shota3506/gostlc/internal/parser"
+	"github.com/shota3506/gostlc/internal/token"
+	"github.com/shota3506/gostlc/internal/types"
+)
+
+// document is an open text document and the result of its analysis.
+type document struct {
+	uri   string
+	lines []string
+
+	// prog is the program recovered from syntax errors, or nil if the document could not be parsed.
+	prog *ast.Program
+	// typedProg is the type-checked prog, or nil if prog is nil.
+	typedProg *ast.TypedProgram
+	diags     []diagnostics.Diagnostic
+}
+
+// newDocument parses and type checks the text of a document.
+func newDocument(uri, text string) *document {
+	d := &document{
+		uri:   uri,
+		lines: strings.Split(text, "\n"),
+	}
+
+	prog, parseErr := parser.ParseProgram(text)
+	var checkErr error
+	if prog != nil {
+		d.prog = prog
+		d.typedProg, checkErr = types.CheckProgram(prog)
+	}
+	if err := errors.Join(parseErr, checkErr); err != nil {
+		d.diags = diagnostics.FromError(err)
+	}
+	if d.typedProg != nil {
+		d.diags = append(d.diags, diagnostics.FromWarnings(d.typedProg.Warnings)...)
+	}
+	return d
+}
+
+// position converts a protocol position to a source position.
+func (d *document) position(p Position) token.Position {
+	var line string
+	if p.Line < len(d.lines) {
+		line = d.lines[p.Line]
+	}
+
+	column, units := 1, 0
+	for _, ch := range line {
+		if units >= p.Character {
+			break
+		}
+		units += utf16.RuneLen(ch)
+		column++
+	}
+	return token.Position{Line: p.Line + 1, Column: column}
+}
+
+// protocolPosition converts a source position to a protocol position.
+func (d *document) protocolPosition(pos token.Position) Position {
+	if pos.Line < 1 {
+		return Position{}
+	}
+	var line string
+	if pos.Line <= len(d.lines) {
+		line = d.lines[pos.Line-1]
+	}
+
+	column, units := 1, 0
+	for _, ch := range line {
+		if column >= pos.Column {
+			break
+		}
+		units += utf16.RuneLen(ch)
+		column++
+	}
+	return Position{Line: pos.Line - 1, Character: units + max(pos.Column-column, 0)}
+}
+
+func (d *document) protocolRange(span token.Span) Range {
+	return Range{Start: d.protocolPosition(span.Start), End: d.protocolPosition(span.End)}
+}
+
+// protocolDiagnostics returns the errors and warnings of the document as protocol diagnostics.
+func (d *document) protocolDiagnostics() []Diagnostic {
+	diags := make([]Diagnostic, 0, len(d.diags))
+	for _, diag := range d.diags {
+		severity := severityError
+		if diag.Warning {
+			severity = severityWarning
+		}
+		var related []DiagnosticRelatedInformation
+		for _, label := range diag.Labels {
+			related = append(related, DiagnosticRelatedInformation{
+				Location: Location{URI: d.uri, Range: d.protocolRange(label.Span)},
+				Message:  label.Message,
+			})
+		}
+		diags = append(diags, Diagnostic{
+			Range:              d.protocolRange(diag.Span),
+			Severity:           severity,
+			Code:               diag.Kind(),
+			Source:             "gostlc",
+			Message:            diag.Message,
+			RelatedInformation: related,
+		})
+	}
+	return diags
+}
+
+// hover returns the type of the innermost expression at pos,
+// or the type of the definition if pos is on its name.
+func (d *document) hover(pos token.Position) *Hover {
+	if d.typedProg == nil {
+		return nil
+	}
+
+	for _, decl := range d.typedProg.Decls {
+		if !contains(decl.Span(), pos) {
+			continue
+		}
+		if expr := findTyped(decl.Value, pos); expr != nil {
+			return d.typeHover(expr)
+		}
+		return &Hover{
+			Contents: typeMarkup(decl.Name + " : " + decl.Type().String()),
+			Range:    d.protocolRange(decl.Span()),
+		}
+	}
+	if expr := findTyped(d.typedProg.Main, pos); expr != nil {
+		return d.typeHover(expr)
+	}
+	return nil
+}
+
+func (d *document) typeHover(expr ast.TypedExpr) *Hover {
+	// An expression that failed to type check has no type to show
+	if _, ok := expr.Type().(*ast.ErrorType); ok {
+		return nil
+	}
+
+	text := expr.Type().String()
+	if v, ok := expr.(*ast.TypedVarExpr); ok {
+		name := v.Name
+		if v.Op != "" {
+			name = "(" + v.Op + ")"
+		}
+		text = name + " : " + text
+	}
+	return &Hover{Contents: typeMarkup(text), Range: d.protocolRange(expr.Span())}
+}
+
+func typeMarkup(text string) MarkupContent {
+	return MarkupContent{Kind: "markdown", Value: "```stlc\n" + text + "\n```"}
+}
+
+// definition returns the location of the construct binding the variable at pos.
+// It returns nil if there is no variable at pos or if the variable is builtin or undefined.
+func (d *document) definition(pos token.Position) *Location {
+	expr, s := d.find(pos)
+	v, ok := expr.(*ast.VarExpr)
+	if !ok || v.Op != "" {
+		return nil
+	}
+	b := s.lookup(v.Name)
+	if b == nil {
+		return nil
+	}
+	return &Location{URI: d.uri, Range: d.protocolRange(b.binder)}
+}
+
+// completion returns the variables in scope at pos followed by the builtin functions not shadowed by them.
+func (d *document) completion(pos token.Position) []CompletionItem {
+	// The scope is that of the character before the cursor, which is often at the end of a partial name
+	if pos.Column > 1 {
+		pos.Column--
+	}
+	_, s := d.find(pos)
+
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+	for ; s != nil; s = s.parent {
+		if seen[s.name] {
+			continue
+		}
+		seen[s.name] = true
+		items = append(items, CompletionItem{
+			Label:  s.name,
+			Kind:   completionKindVariable,
+			Detail: d.declType(s.binder),
+		})
+	}
+	for _, name := range slices.Sorted(maps.Keys(builtin.FunctionTypes)) {
+		if seen[name] {
+			continue
+		}
+		items = append(items, CompletionItem{
+			Label:  name,
+			Kind:   completionKindFunction,
+			Detail: builtin.FunctionTypes[name].String(),
+		})
+	}
+	return items
+}
+
+// declType returns the type of the top-level definition or constructor with the given span, or "" if there is none.
+func (d *document) declType(span token.Span) string {
+	if d.typedProg == nil {
+		return ""
+	}
+	for _, typeDecl := range d.typedProg.Types {
+		for _, con := range typeDecl.Constructors {
+			if con.Span() == span {
+				return constructorType(typeDecl, con).String()
+			}
+		}
+	}
+	for _, decl := range d.typedProg.Decls {
+		if decl.Span() == span {
+			return decl.Type().String()
+		}
+	}
+	return ""
+}
+
+// constructorType returns the type of the function building a value of typeDecl with con.
+func constructorType(typeDecl *ast.TypeDecl, con *ast.Constructor) ast.Type {
+	var typ ast.Type = &ast.DataType{Name: typeDecl.Name}
+	for _, param := range slices.Backward(con.Params) {
+		typ = &ast.FuncType{From: param, To: typ}
+	}
+	return typ
+}
+
+// symbols returns the top-level data types and definitions of the document.
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	if d.prog == nil {
+		return symbols
+	}
+	for _, typeDecl := range d.prog.Types {
+		symbols = append(symbols, DocumentSymbol{
+			Name:           typeDecl.Name,
+			Kind:           symbolKindEnum,
+			Range:          d.protocolRange(typeDecl.Span()),
+			SelectionRange: d.protocolRange(typeDecl.Span()),
+		})
+	}
+	for i, decl := range d.prog.Decls {
+		symbol := DocumentSymbol{
+			Name:           decl.Name,
+			Kind:           symbolKindVariable,
+			Range:          d.protocolRange(decl.Span()),
+			SelectionRange: d.protocolRange(decl.Span()),
+		}
+		if d.typedProg != nil {
+			typ := d.typedProg.Decls[i].Type()
+			symbol.Detail = typ.String()
+			if _, ok := typ.(*ast.FuncType); ok {
+				symbol.Kind = symbolKindFunction
+			}
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// scope is a list of the variables bound around an expression, innermost first.
+type scope struct {
+	name string
+	// binder is the span of the construct binding the variable
+	binder token.Span
+	parent *scope
+}
+
+func (s *scope) bind(name string, binder token.Span) *scope {
+	return &scope{name: name, binder: binder, parent: s}
+}
+
+// lookup returns the innermost binding of name, or nil if name is not bound.
+func (s *scope) lookup(name string) *scope {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// find returns the innermost expression of the program at pos and the variables in scope of it.
+// If no expression is at pos, the expression is nil and the scope is that of the position.
+func (d *document) find(pos token.Position) (ast.Expr, *scope) {
+	if d.prog == nil {
+		return nil, nil
+	}
+
+	// Constructors are in scope of every definition
+	var s *scope
+	for _, typeDecl := range d.prog.Types {
+		for _, con := range typeDecl.Constructors {
+			s = s.bind(con.Name, con.Span())
+		}
+	}
+	for _, decl := range d.prog.Decls {
+		if pos.Before(decl.Pos) {
+			return nil, s
+		}
+		if contains(decl.Span(), pos) {
+			if expr, exprScope := findExpr(decl.Value, s, pos); expr != nil {
+				return expr, exprScope
+			}
+			return nil, s
+		}
+		s = s.bind(decl.Name, decl.Span())
+	}
+	if expr, exprScope := findExpr(d.prog.Main, s, pos); expr != nil {
+		return expr, exprScope
+	}
+	return nil, s
+}
+
+func findExpr(expr ast.Expr, s *scope, pos token.Position) (ast.Expr, *scope) {
+	if expr == nil || !contains(expr.Span(), pos) {
+		return nil, nil
+	}
+	for _, sub := range subexprs(expr, s) {
+		if found, foundScope := findExpr(sub.expr, sub.scope, pos); found != nil {
+			return found, foundScope
+		}
+	}
+	return expr, s
+}
+
+// scopedExpr is an expression with the variables in scope of it.
+type scopedExpr struct {
+	expr  ast.Expr
+	scope *scope
+}
+
+// subexprs returns the direct subexpressions of expr, whose scope is s.
+func subexprs(expr ast.Expr, s *scope) []scopedExpr {
+	switch e := expr.(type) {
+	case *ast.AbsExpr:
+		// The parameter of a section is not visible in the source
+		if e.Param == ast.SectionParam {
+			return []scopedExpr{{e.Body, s}}
+		}
+		return []scopedExpr{{e.Body, s.bind(e.Param, e.Span())}}
+	case *ast.AppExpr:
+		return []scopedExpr{{e.Func, s}, {e.Arg, s}}
+	case *ast.IfExpr:
+		return []scopedExpr{{e.Cond, s}, {e.Then, s}, {e.Else, s}}
+	case *ast.LetExpr:
+		return []scopedExpr{{e.Value, s}, {e.Body, s.bind(e.Name, e.Span())}}
+	case *ast.FixExpr:
+		return []scopedExpr{{e.Func, s}}
+	case *ast.TupleExpr:
+		subs := make([]scopedExpr, len(e.Elems))
+		for i, elem := range e.Elems {
+			subs[i] = scopedExpr{elem, s}
+		}
+		return subs
+	case *ast.ProjExpr:
+		return []scopedExpr{{e.Tuple, s}}
+	case *ast.InjExpr:
+		return []scopedExpr{{e.Value, s}}
+	case *ast.CaseExpr:
+		return []scopedExpr{
+			{e.Scrutinee, s},
+			{e.Left, s.bind(e.LeftVar, e.Span())},
+			{e.Right, s.bind(e.RightVar, e.Span())},
+		}
+	case *ast.ListExpr:
+		subs := make([]scopedExpr, len(e.Elems))
+		for i, elem := range e.Elems {
+			subs[i] = scopedExpr{elem, s}
+		}
+		return subs
+	case *ast.ListCaseExpr:
+		return []scopedExpr{
+			{e.Scrutinee, s},
+			{e.Nil, s},
+			{e.Cons, s.bind(e.HeadVar, e.Span()).bind(e.TailVar, e.Span())},
+		}
+	case *ast.RecordExpr:
+		subs := make([]scopedExpr, len(e.Fields))
+		for i, field := range e.Fields {
+			subs[i] = scopedExpr{field.Value, s}
+		}
+		return subs
+	case *ast.RecordProjExpr:
+		return []scopedExpr{{e.Record, s}}
+	case *ast.VariantExpr:
+		return []scopedExpr{{e.Value, s}}
+	case *ast.VariantCaseExpr:
+		subs := []scopedExpr{{e.Scrutinee, s}}
+		for _, branch := range e.Branches {
+			subs = append(subs, scopedExpr{branch.Body, s.bind(branch.Var, branch.Span())})
+		}
+		return subs
+	case *ast.MatchExpr:
+		subs := []scopedExpr{{e.Scrutinee, s}}
+		for _, arm := range e.Arms {
+			armScope := s
+			for _, v := range ast.PatternVars(arm.Pattern) {
+				armScope = armScope.bind(v.Name, v.Span())
+			}
+			subs = append(subs, scopedExpr{arm.Body, armScope})
+		}
+		return subs
+	case *ast.TyAbsExpr:
+		return []scopedExpr{{e.Body, s}}
+	case *ast.TyAppExpr:
+		return []scopedExpr{{e.Func, s}}
+	case *ast.FoldExpr:
+		return []scopedExpr{{e.Value, s}}
+	case *ast.UnfoldExpr:
+		return []scopedExpr{{e.Value, s}}
+	default:
+		return nil
+	}
+}
+
+// findTyped returns the innermost typed expression at pos, or nil if there is none.
+func findTyped(expr ast.TypedExpr, pos token.Position) ast.TypedExpr {
+	if expr == nil || !contains(expr.Span(), pos) {
+		return nil
+	}
+	for _, sub := range typedSubexprs(expr) {
+		if found := findTyped(sub, pos); found != nil {
+			return found
+		}
+	}
+	return expr
+}
+
+func typedSubexprs(expr ast.TypedExpr) []ast.TypedExpr {
+	switch e := expr.(type) {
+	case *ast.TypedAbsExpr:
+		return []ast.TypedExpr{e.Body}
+	case *ast.TypedAppExpr:
+		return []ast.TypedExpr{e.Func, e.Arg}
+	case *ast.TypedIfExpr:
+		return []ast.TypedExpr{e.Cond, e.Then, e.Else}
+	case *ast.TypedLetExpr:
+		return []ast.TypedExpr{e.Value, e.Body}
+	case *ast.TypedFixExpr:
+		return []ast.TypedExpr{e.Func}
+	case *ast.TypedTupleExpr:
+		return e.Elems
+	case *ast.TypedProjExpr:
+		return []ast.TypedExpr{e.Tuple}
+	case *ast.TypedInjExpr:
+		return []ast.TypedExpr{e.Value}
+	case *ast.TypedCaseExpr:
+		return []ast.TypedExpr{e.Scrutinee, e.Left, e.Right}
+	case *ast.TypedListExpr:
+		return e.Elems
+	case *ast.TypedListCaseExpr:
+		return []ast.TypedExpr{e.Scrutinee, e.Nil, e.Cons}
+	case *ast.TypedRecordExpr:
+		subs := make([]ast.TypedExpr, len(e.Fields))
+		for i, field := range e.Fields {
+			subs[i] = field.Value
+		}
+		return subs
+	case *ast.TypedRecordProjExpr:
+		return []ast.TypedExpr{e.Record}
+	case *ast.TypedVariantExpr:
+		return []ast.TypedExpr{e.Value}
+	case *ast.TypedVariantCaseExpr:
+		subs := []ast.TypedExpr{e.Scrutinee}
+		for _, branch := range e.Branches {
+			subs = append(subs, branch.Body)
+		}
+		return subs
+	case *ast.TypedMatchExpr:
+		subs := []ast.TypedExpr{e.Scrutinee}
+		for _, arm := range e.Arms {
+			subs = append(subs, arm.Body)
+		}
+		return subs
+	case *ast.TypedTyAbsExpr:
+		return []ast.TypedExpr{e.Body}
+	case *ast.TypedTyAppExpr:
+		return []ast.TypedExpr{e.Func}
+	case *ast.TypedFoldExpr:
+		return []ast.TypedExpr{e.Value}
+	case *ast.TypedUnfoldExpr:
+		return []ast.TypedExpr{e.Value}
+	default:
+		return nil
+	}
+}
+
+// contains reports whether pos is within span.
+func contains(span token.Span, pos token.Position) bool {
+	return !pos.Before(span.Start) && pos.Before(span.End)
+}
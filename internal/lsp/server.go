@@ -0,0 +1,218 @@
+// Package lsp implements a Language Server Protocol server for STLC programs.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Server is a language server communicating over a stream with JSON-RPC messages.
+// Documents are analyzed on every change, and their errors are published as diagnostics.
+type Server struct {
+	w    io.Writer
+	docs map[string]*document
+
+	initialized bool
+	shutdown    bool
+}
+
+func NewServer() *Server {
+	return &Server{
+		docs: make(map[string]*document),
+	}
+}
+
+// Serve reads requests and notifications from r and writes responses and notifications to w
+// until the exit notification or the end of r.
+// It returns an error if the client exits without requesting shutdown first.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			// A malformed message is answered with an error, as its ID is unknown
+			var respErr *ResponseError
+			if errors.As(err, &respErr) {
+				null := json.RawMessage("null")
+				if err := writeMessage(s.w, &message{ID: &null, Error: respErr}); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification and replies to a request.
+// Errors other than a ResponseError are errors of the connection.
+func (s *Server) handle(msg *message) error {
+	result, err := s.dispatch(msg.Method, msg.Params)
+	var respErr *ResponseError
+	if err != nil && !errors.As(err, &respErr) {
+		return err
+	}
+	if !msg.isRequest() {
+		// Notifications are not answered, even on error
+		return nil
+	}
+
+	if respErr != nil {
+		return writeMessage(s.w, &message{ID: msg.ID, Error: respErr})
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.w, &message{ID: msg.ID, Result: data})
+}
+
+func (s *Server) dispatch(method string, params json.RawMessage) (any, error) {
+	if !s.initialized && method != "initialize" {
+		return nil, &ResponseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+	if s.shutdown {
+		// Only the exit notification is expected after shutdown, and other notifications are dropped
+		return nil, &ResponseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch method {
+	case "initialize":
+		s.initialized = true
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       textDocumentSyncFull,
+				HoverProvider:          true,
+				DefinitionProvider:     true,
+				CompletionProvider:     &CompletionOptions{},
+				DocumentSymbolProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "gostlc"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		p, err := decode[DidOpenTextDocumentParams](params)
+		if err != nil {
+			return nil, err
+		}
+		return nil, s.update(p.TextDocument.URI, p.TextDocument.Text)
+	case "textDocument/didChange":
+		p, err := decode[DidChangeTextDocumentParams](params)
+		if err != nil {
+			return nil, err
+		}
+		if len(p.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// Each change holds the full content, so only the last one matters
+		return nil, s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		p, err := decode[DidCloseTextDocumentParams](params)
+		if err != nil {
+			return nil, err
+		}
+		delete(s.docs, p.TextDocument.URI)
+		// Clear the diagnostics of the closed document
+		return nil, s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         p.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/hover":
+		doc, pos, err := s.documentPosition(params)
+		if err != nil {
+			return nil, err
+		}
+		return doc.hover(doc.position(pos)), nil
+	case "textDocument/definition":
+		doc, pos, err := s.documentPosition(params)
+		if err != nil {
+			return nil, err
+		}
+		return doc.definition(doc.position(pos)), nil
+	case "textDocument/completion":
+		doc, pos, err := s.documentPosition(params)
+		if err != nil {
+			return nil, err
+		}
+		return doc.completion(doc.position(pos)), nil
+	case "textDocument/documentSymbol":
+		p, err := decode[DocumentSymbolParams](params)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := s.document(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return doc.symbols(), nil
+	default:
+		return nil, &ResponseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+	}
+}
+
+// update analyzes the new text of a document and publishes its diagnostics.
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.docs[uri] = doc
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: doc.protocolDiagnostics(),
+	})
+}
+
+func (s *Server) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.w, &message{Method: method, Params: data})
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &ResponseError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown document: %s", uri)}
+	}
+	return doc, nil
+}
+
+func (s *Server) documentPosition(params json.RawMessage) (*document, Position, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, Position{}, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, Position{}, err
+	}
+	return doc, p.Position, nil
+}
+
+func decode[T any](params json.RawMessage) (T, error) {
+	var p T
+	if err := json.Unmarshal(params, &p); err != nil {
+		return p, &ResponseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return p, nil
+}
@@ -0,0 +1,525 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+const testURI = "file:///main.stlc"
+
+// testClient is an in-process JSON-RPC client of a server running in another goroutine.
+type testClient struct {
+	t      *testing.T
+	w      io.WriteCloser
+	nextID int
+
+	responses     chan *message
+	notifications chan *message
+	done          chan error
+}
+
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &testClient{
+		t:             t,
+		w:             clientOut,
+		responses:     make(chan *message, 16),
+		notifications: make(chan *message, 16),
+		done:          make(chan error, 1),
+	}
+
+	go func() {
+		c.done <- NewServer().Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+	go func() {
+		r := bufio.NewReader(clientIn)
+		for {
+			msg, err := readMessage(r)
+			if err != nil {
+				return
+			}
+			if msg.Method != "" {
+				c.notifications <- msg
+			} else {
+				c.responses <- msg
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		clientOut.Close()
+	})
+
+	if err := c.call("initialize", struct{}{}, nil); err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+	c.notify("initialized", struct{}{})
+	return c
+}
+
+// call sends a request and waits for its response, decoded into result if not nil.
+func (c *testClient) call(method string, params, result any) error {
+	c.t.Helper()
+
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	data, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if err := writeMessage(c.w, &message{ID: &id, Method: method, Params: data}); err != nil {
+		c.t.Fatalf("writeMessage() error = %v", err)
+	}
+
+	select {
+	case msg := <-c.responses:
+		if string(*msg.ID) != string(id) {
+			c.t.Fatalf("response ID = %s, want %s", *msg.ID, id)
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+		}
+		return nil
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("no response to %s", method)
+		return nil
+	}
+}
+
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if err := writeMessage(c.w, &message{Method: method, Params: data}); err != nil {
+		c.t.Fatalf("writeMessage() error = %v", err)
+	}
+}
+
+// diagnostics waits for the next diagnostics published by the server.
+func (c *testClient) diagnostics() PublishDiagnosticsParams {
+	c.t.Helper()
+
+	select {
+	case msg := <-c.notifications:
+		if msg.Method != "textDocument/publishDiagnostics" {
+			c.t.Fatalf("notification method = %s, want textDocument/publishDiagnostics", msg.Method)
+		}
+		var p PublishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			c.t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		return p
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("no diagnostics published")
+		return PublishDiagnosticsParams{}
+	}
+}
+
+// open opens a document and returns its diagnostics.
+func (c *testClient) open(text string) PublishDiagnosticsParams {
+	c.t.Helper()
+
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, Version: 1, Text: text},
+	})
+	return c.diagnostics()
+}
+
+func positionParams(line, character int) *TextDocumentPositionParams {
+	return &TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+func rng(startLine, startCharacter, endLine, endCharacter int) Range {
+	return Range{
+		Start: Position{Line: startLine, Character: startCharacter},
+		End:   Position{Line: endLine, Character: endCharacter},
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := newTestClient(t)
+
+	p := c.open("let f = \\x:Int. x\nf true")
+	expected := []Diagnostic{
+		{
+			Range:    rng(1, 0, 1, 6),
+			Severity: severityError,
+			Code:     "TypeMismatchError",
+			Source:   "gostlc",
+			Message:  "type mismatch in application: expected Int, got Bool",
+			RelatedInformation: []DiagnosticRelatedInformation{
+				{Location: Location{URI: testURI, Range: rng(1, 0, 1, 1)}, Message: "function expects Int here"},
+				{Location: Location{URI: testURI, Range: rng(1, 2, 1, 6)}, Message: "argument is Bool here"},
+			},
+		},
+	}
+	if p.URI != testURI || !reflect.DeepEqual(p.Diagnostics, expected) {
+		t.Errorf("diagnostics = %+v, want %+v", p.Diagnostics, expected)
+	}
+
+	// Every change is analyzed again
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: testURI},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "let f = \\x:Int. x\nf (1 2, y"}},
+	})
+	p = c.diagnostics()
+	var messages []string
+	for _, d := range p.Diagnostics {
+		messages = append(messages, d.Message)
+	}
+	if want := []string{"expected ')': EOF"}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("diagnostics = %q, want %q", messages, want)
+	}
+
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: testURI},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "let f = \\x:Int. x\nf 1"}},
+	})
+	if p := c.diagnostics(); p.Diagnostics == nil || len(p.Diagnostics) != 0 {
+		t.Errorf("diagnostics = %+v, want none", p.Diagnostics)
+	}
+
+	// Warnings do not prevent running the program
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: testURI},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "match true with | _ -> 1 | true -> 2"}},
+	})
+	p = c.diagnostics()
+	if len(p.Diagnostics) != 1 || p.Diagnostics[0].Severity != severityWarning || p.Diagnostics[0].Range != rng(0, 27, 0, 36) {
+		t.Errorf("diagnostics = %+v, want a warning of the redundant arm", p.Diagnostics)
+	}
+
+	c.notify("textDocument/didClose", &DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}})
+	if p := c.diagnostics(); len(p.Diagnostics) != 0 {
+		t.Errorf("diagnostics = %+v, want none", p.Diagnostics)
+	}
+}
+
+func TestHover(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		text      string
+		line      int
+		character int
+		expected  *Hover
+	}{
+		{
+			name:      "Variable",
+			text:      "let f = \\x:Int. add x 1\nf 2",
+			line:      0,
+			character: 20,
+			expected: &Hover{
+				Contents: MarkupContent{Kind: "markdown", Value: "```stlc\nx : Int\n```"},
+				Range:    rng(0, 20, 0, 21),
+			},
+		},
+		{
+			name:      "Builtin",
+			text:      "let f = \\x:Int. add x 1\nf 2",
+			line:      0,
+			character: 18,
+			expected: &Hover{
+				Contents: MarkupContent{Kind: "markdown", Value: "```stlc\nadd : (Int->(Int->Int))\n```"},
+				Range:    rng(0, 16, 0, 19),
+			},
+		},
+		{
+			name:      "Operator",
+			text:      "let f = \\x:Int. x + 1\nf 2",
+			line:      0,
+			character: 18,
+			expected: &Hover{
+				Contents: MarkupContent{Kind: "markdown", Value: "```stlc\n(+) : (Int->(Int->Int))\n```"},
+				Range:    rng(0, 18, 0, 19),
+			},
+		},
+		{
+			name:      "Name of definition",
+			text:      "let f = \\x:Int. add x 1\nf 2",
+			line:      0,
+			character: 4,
+			expected: &Hover{
+				Contents: MarkupContent{Kind: "markdown", Value: "```stlc\nf : (Int->Int)\n```"},
+				Range:    rng(0, 0, 0, 23),
+			},
+		},
+		{
+			name:      "Main expression",
+			text:      "let f = \\x:Int. add x 1\nf 2",
+			line:      1,
+			character: 2,
+			expected: &Hover{
+				Contents: MarkupContent{Kind: "markdown", Value: "```stlc\nInt\n```"},
+				Range:    rng(1, 2, 1, 3),
+			},
+		},
+		{
+			name:      "Whitespace",
+			text:      "let x = 1\n\nx",
+			line:      1,
+			character: 0,
+			expected:  nil,
+		},
+		{
+			name:      "Expression with a type error",
+			text:      "add true",
+			line:      0,
+			character: 5,
+			expected:  nil,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t)
+			c.open(tt.text)
+
+			var hover *Hover
+			if err := c.call("textDocument/hover", positionParams(tt.line, tt.character), &hover); err != nil {
+				t.Fatalf("hover error = %v", err)
+			}
+			if !reflect.DeepEqual(hover, tt.expected) {
+				t.Errorf("hover = %+v, want %+v", hover, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		text      string
+		line      int
+		character int
+		expected  *Location
+	}{
+		{
+			name:      "Lambda parameter",
+			text:      `(\x:Int. \y:Int. x) 1 2`,
+			line:      0,
+			character: 17,
+			expected:  &Location{URI: testURI, Range: rng(0, 1, 0, 18)},
+		},
+		{
+			name:      "Shadowed parameter",
+			text:      `(\x:Int. \x:Int. x) 1 2`,
+			line:      0,
+			character: 17,
+			expected:  &Location{URI: testURI, Range: rng(0, 9, 0, 18)},
+		},
+		{
+			name:      "Let binding",
+			text:      `let y = 1 in add y y`,
+			line:      0,
+			character: 17,
+			expected:  &Location{URI: testURI, Range: rng(0, 0, 0, 20)},
+		},
+		{
+			name:      "Top-level definition",
+			text:      "let one = 1\n\nadd one one",
+			line:      2,
+			character: 5,
+			expected:  &Location{URI: testURI, Range: rng(0, 0, 0, 11)},
+		},
+		{
+			name:      "Pattern variable",
+			text:      "type T = A Int | B\nmatch A 1 with | A n -> n | B -> 0",
+			line:      1,
+			character: 24,
+			expected:  &Location{URI: testURI, Range: rng(1, 19, 1, 20)},
+		},
+		{
+			name:      "Constructor",
+			text:      "type T = A Int | B\nmatch A 1 with | A n -> n | B -> 0",
+			line:      1,
+			character: 6,
+			expected:  &Location{URI: testURI, Range: rng(0, 9, 0, 14)},
+		},
+		{
+			name:      "Builtin",
+			text:      `add 1 2`,
+			line:      0,
+			character: 1,
+			expected:  nil,
+		},
+		{
+			name:      "Operator with its builtin shadowed",
+			text:      `let add = \x:Int. \y:Int. x in 1 + 2`,
+			line:      0,
+			character: 33,
+			expected:  nil,
+		},
+		{
+			name:      "Not a variable",
+			text:      `add 1 2`,
+			line:      0,
+			character: 4,
+			expected:  nil,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t)
+			c.open(tt.text)
+
+			var location *Location
+			if err := c.call("textDocument/definition", positionParams(tt.line, tt.character), &location); err != nil {
+				t.Fatalf("definition error = %v", err)
+			}
+			if !reflect.DeepEqual(location, tt.expected) {
+				t.Errorf("definition = %+v, want %+v", location, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		text      string
+		line      int
+		character int
+		included  []string
+		excluded  []string
+	}{
+		{
+			name:      "Lambda parameters and definitions",
+			text:      "let one = 1\nlet f = \\x:Int. \\y:Int. add x y\nf one 2",
+			line:      1,
+			character: 28,
+			included:  []string{"y", "x", "one", "add", "not"},
+			excluded:  []string{"f"},
+		},
+		{
+			name:      "Main expression",
+			text:      "let one = 1\nlet f = \\x:Int. \\y:Int. add x y\nf one 2",
+			line:      2,
+			character: 1,
+			included:  []string{"f", "one", "add"},
+			excluded:  []string{"x", "y"},
+		},
+		{
+			name:      "Shadowed builtin",
+			text:      "let add = 1\nadd",
+			line:      1,
+			character: 3,
+			included:  []string{"add"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t)
+			c.open(tt.text)
+
+			var items []CompletionItem
+			if err := c.call("textDocument/completion", positionParams(tt.line, tt.character), &items); err != nil {
+				t.Fatalf("completion error = %v", err)
+			}
+			labels := make(map[string]int)
+			for _, item := range items {
+				labels[item.Label]++
+			}
+			for _, label := range tt.included {
+				if labels[label] != 1 {
+					t.Errorf("completion has %d items %q, want 1", labels[label], label)
+				}
+			}
+			for _, label := range tt.excluded {
+				if labels[label] != 0 {
+					t.Errorf("completion has item %q", label)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	c := newTestClient(t)
+	c.open("let one = 1\nletrec loop : Int -> Int = \\n:Int. loop n\nloop one")
+
+	var symbols []DocumentSymbol
+	params := &DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: testURI}}
+	if err := c.call("textDocument/documentSymbol", params, &symbols); err != nil {
+		t.Fatalf("documentSymbol error = %v", err)
+	}
+	expected := []DocumentSymbol{
+		{Name: "one", Detail: "Int", Kind: symbolKindVariable, Range: rng(0, 0, 0, 11), SelectionRange: rng(0, 0, 0, 11)},
+		{Name: "loop", Detail: "(Int->Int)", Kind: symbolKindFunction, Range: rng(1, 0, 1, 41), SelectionRange: rng(1, 0, 1, 41)},
+	}
+	if !reflect.DeepEqual(symbols, expected) {
+		t.Errorf("documentSymbol = %+v, want %+v", symbols, expected)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	c := newTestClient(t)
+
+	var respErr *ResponseError
+	if err := c.call("textDocument/unknown", struct{}{}, nil); !errors.As(err, &respErr) || respErr.Code != codeMethodNotFound {
+		t.Errorf("unknown method error = %v, want code %d", err, codeMethodNotFound)
+	}
+	if err := c.call("textDocument/hover", positionParams(0, 0), nil); !errors.As(err, &respErr) || respErr.Code != codeInvalidParams {
+		t.Errorf("unknown document error = %v, want code %d", err, codeInvalidParams)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	c := newTestClient(t)
+
+	if err := c.call("shutdown", nil, nil); err != nil {
+		t.Fatalf("shutdown error = %v", err)
+	}
+	var respErr *ResponseError
+	if err := c.call("textDocument/documentSymbol", struct{}{}, nil); !errors.As(err, &respErr) || respErr.Code != codeInvalidRequest {
+		t.Errorf("request after shutdown error = %v, want code %d", err, codeInvalidRequest)
+	}
+	c.notify("exit", nil)
+	select {
+	case err := <-c.done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not exit")
+	}
+}
+
+func TestPositionConversion(t *testing.T) {
+	// Characters outside the Basic Multilingual Plane take two UTF-16 code units
+	d := newDocument(testURI, "a𝜆b\nc")
+	for _, tt := range []struct {
+		pos      token.Position
+		protocol Position
+	}{
+		{pos: token.Position{Line: 1, Column: 1}, protocol: Position{Line: 0, Character: 0}},
+		{pos: token.Position{Line: 1, Column: 2}, protocol: Position{Line: 0, Character: 1}},
+		{pos: token.Position{Line: 1, Column: 3}, protocol: Position{Line: 0, Character: 3}},
+		{pos: token.Position{Line: 1, Column: 4}, protocol: Position{Line: 0, Character: 4}},
+		{pos: token.Position{Line: 2, Column: 2}, protocol: Position{Line: 1, Character: 1}},
+	} {
+		if got := d.protocolPosition(tt.pos); got != tt.protocol {
+			t.Errorf("protocolPosition(%v) = %v, want %v", tt.pos, got, tt.protocol)
+		}
+		if got := d.position(tt.protocol); got != tt.pos {
+			t.Errorf("position(%v) = %v, want %v", tt.protocol, got, tt.pos)
+		}
+	}
+}
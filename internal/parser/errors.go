@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/shota3506/gostlc/internal/token"
 )
@@ -9,6 +10,7 @@ import (
 // ParseError represents an error that occurred during parsing.
 type ParseError struct {
 	Pos     token.Position
+	End     token.Position
 	Message string
 }
 
@@ -16,9 +18,29 @@ func (e *ParseError) Error() string {
 	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Message)
 }
 
+func (e *ParseError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// ErrorList is a list of syntax errors in the order they were found.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
 func newParseError(tok token.Token, message string) error {
 	return &ParseError{
 		Pos:     tok.Pos,
+		End:     tok.End(),
 		Message: message,
 	}
 }
@@ -2,25 +2,79 @@
 //
 // The grammar is defined as follows:
 // ```
+// program ::= decl* expr
+// decl ::= "let" var [":" type] "=" expr      (* top-level definition *)
+//        | "letrec" var [":" type] "=" expr (* recursive top-level definition *)
+//        | "type" var "=" ["|"] con ("|" con)* (* algebraic data type *)
+// con ::= var type*                           (* constructor, whose name starts with an uppercase letter *)
 // expr ::= var
-//        | "\" var ":" type "." expr         (* abstraction *)
+//        | ("\" | "λ") var [":" type] "." expr (* abstraction *)
 //        | expr expr                         (* application *)
 //        | "(" expr ")"                      (* grouping *)
 //        | "true" | "false"                  (* boolean literals *)
 //        | "if" expr "then" expr "else" expr (* conditional *)
-//        | digit+                            (* integer literals *)
+//        | ["-"] digit+                      (* integer literals *)
+//        | ["-"] digit+ "." digit+           (* float literals *)
+//        | '"' char* '"'                     (* string literals *)
+//        | "'" char "'"                      (* character literals *)
+//        | "(" ")"                           (* unit *)
+//        | "let" var [":" type] "=" expr "in" expr (* let binding *)
+//        | "letrec" var [":" type] "=" expr "in" expr (* recursive let binding *)
+//        | "fix" expr                      (* fixed point *)
+//        | "(" expr ("," expr)+ ")"        (* tuple *)
+//        | expr "." digit+                 (* tuple projection *)
+//        | ("inl" | "inr") expr "as" type  (* injection *)
+//        | "case" expr "of" "inl" var "=>" expr "|" "inr" var "=>" expr (* case analysis *)
+//        | "{" [var "=" expr ("," var "=" expr)*] "}" (* record *)
+//        | expr "." var                    (* record projection *)
+//        | "<" var "=" expr ">" "as" type  (* variant *)
+//        | "case" expr "of" "<" var "=" var ">" "=>" expr ("|" "<" var "=" var ">" "=>" expr)* (* variant case analysis *)
+//        | "nil" "[" type "]"               (* empty list *)
+//        | "[" [expr ("," expr)*] "]"      (* list literal *)
+//        | "case" expr "of" "[" "]" "=>" expr "|" var "::" var "=>" expr (* list case analysis *)
+//        | "match" expr "with" ["|"] pattern "->" expr ("|" pattern "->" expr)* (* pattern match *)
+//        | "fold" "[" type "]" expr          (* fold into a recursive type *)
+//        | "unfold" "[" type "]" expr        (* unfold of a recursive type *)
+//        | ("/\" | "Λ") var "." expr      (* type abstraction *)
+//        | expr "[" type "]"                 (* type application *)
+//        | expr binop expr                   (* infix operator *)
+//        | ("-" | "!") expr                  (* negation *)
+//        | "(" binop ")" | "(" expr binop ")" | "(" binop expr ")" (* operator sections *)
 // type ::= "Bool"                            (* boolean type *)
 //        | "Int"                             (* integer type *)
-//        | type "->" type                    (* function type *)
+//        | "Unit" | "String" | "Char" | "Float" (* unit, string, character and float types *)
+//        | "List" type                     (* list type *)
+//        | type ("->" | "→") type            (* function type *)
+//        | type ("*" type)+                  (* product type *)
+//        | type "+" type                     (* sum type *)
+//        | "{" [var ":" type ("," var ":" type)*] "}" (* record type *)
+//        | "<" var ":" type ("," var ":" type)* ">" (* variant type *)
+//        | var                               (* type variable *)
+//        | ("forall" | "∀") var "." type     (* universal type *)
+//        | "mu" var "." type                 (* recursive type *)
 //        | "(" type ")"                      (* grouping *)
-// var  ::= letter (letter | digit)*          (* variable names *)
+// pattern ::= "_"                             (* wildcard *)
+//        | var                               (* variable *)
+//        | "true" | "false" | ["-"] digit+ | '"' char* '"' | "'" char "'" | "(" ")" (* literals *)
+//        | var pattern+                      (* constructor applied to patterns *)
+//        | "(" pattern ")"                   (* grouping *)
+// binop ::= "||" | "&&" | "==" | "!=" | "<" | "<=" | ">" | ">=" | "::" | "+" | "-" | "*" | "/" | "%"
+// char ::= any character but the quote, "\" or a line break
+//        | "\" ("n" | "t" | "r" | "0" | "\" | '"' | "'") | "\u{" hexdigit+ "}" (* escape sequences *)
+// var  ::= (letter | "_") (letter | digit | "_" | "'")* (* variable names *)
 // ```
 
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"slices"
 	"strconv"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/shota3506/gostlc/internal/ast"
 	"github.com/shota3506/gostlc/internal/lexer"
@@ -31,11 +85,61 @@ type parser struct {
 	lexer     *lexer.Lexer
 	curToken  token.Token
 	peekToken token.Token
+
+	// prevEnd is the end position of the last consumed token, where a node being parsed ends.
+	prevEnd token.Position
+
+	// decl is set while parsing a top-level definition. A token at column 1 then never continues
+	// a complete expression or type, but ends the definition and starts the next one or the main expression.
+	decl bool
+
+	// variant is set while parsing the value of a variant, where '>' closes the variant rather than comparing.
+	variant bool
+
+	// errors collects the syntax errors recovered from so far.
+	errors ErrorList
 }
 
+// errSkipped is returned once a syntax error has been recorded and the parser has skipped
+// to the end of the enclosing definition, to abandon the constructs still being parsed.
+var errSkipped = errors.New("skipped to the end of the definition")
+
 // Parse parses the input string and returns the corresponding AST expression.
+// After a syntax error, parsing resumes at the next ')', 'then' or 'else', and all
+// syntax errors found are returned as an ErrorList along with the AST recovered so far,
+// in which the expressions that failed to parse are ast.ErrorExpr.
 func Parse(s string) (ast.Expr, error) {
-	l := lexer.New(s)
+	p, err := newParser(lexer.New(s))
+	if err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr()
+	return expr, p.err(err)
+}
+
+// ParseProgram parses the input string as a program, a sequence of top-level
+// definitions followed by a main expression.
+// Besides the recovery points of Parse, parsing also resumes at the next definition.
+func ParseProgram(s string) (*ast.Program, error) {
+	return parseProgram(lexer.New(s))
+}
+
+// ParseProgramReader is like ParseProgram but reads the program from r as it goes.
+// The positions in the AST and errors carry filename.
+func ParseProgramReader(r io.Reader, filename string) (*ast.Program, error) {
+	return parseProgram(lexer.NewReader(r, filename))
+}
+
+func parseProgram(l *lexer.Lexer) (*ast.Program, error) {
+	p, err := newParser(l)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := p.parseProgram()
+	return prog, p.err(err)
+}
+
+func newParser(l *lexer.Lexer) (*parser, error) {
 	p := &parser{lexer: l}
 
 	if err := p.nextToken(); err != nil {
@@ -44,10 +148,11 @@ func Parse(s string) (ast.Expr, error) {
 	if err := p.nextToken(); err != nil {
 		return nil, err
 	}
-	return p.parseExpr()
+	return p, nil
 }
 
 func (p *parser) nextToken() error {
+	p.prevEnd = p.curToken.End()
 	p.curToken = p.peekToken
 	tok, err := p.lexer.Next()
 	if err != nil {
@@ -57,67 +162,401 @@ func (p *parser) nextToken() error {
 	return nil
 }
 
-// parseExpr parses an expression with left-associative application.
+// err returns the recorded syntax errors, together with err if it ended parsing.
+func (p *parser) err(err error) error {
+	if err != nil && err != errSkipped {
+		p.errors = append(p.errors, err)
+	}
+	if len(p.errors) == 0 {
+		return nil
+	}
+	return p.errors
+}
+
+// synchronize records err and skips tokens up to one of kinds outside of nested parentheses,
+// so that parsing can resume after a syntax error. It returns errSkipped if the end of the
+// definition is reached first, and lexical errors, which cannot be recovered from, as they are.
+func (p *parser) synchronize(err error, kinds ...token.TokenKind) error {
+	if err == errSkipped {
+		return err
+	}
+	var lexErr *lexer.LexerError
+	if errors.As(err, &lexErr) {
+		return err
+	}
+	p.errors = append(p.errors, err)
+
+	depth := 0
+	for {
+		switch {
+		case p.curToken.Kind == token.TokenKindEOF, p.atDeclBoundary():
+			return errSkipped
+		case depth == 0 && slices.Contains(kinds, p.curToken.Kind):
+			return nil
+		case p.curToken.Kind == token.TokenKindLParen:
+			depth++
+		case p.curToken.Kind == token.TokenKindRParen && depth > 0:
+			depth--
+		}
+		if err := p.nextToken(); err != nil {
+			return err
+		}
+	}
+}
+
+// atDeclBoundary reports whether the current token ends a top-level definition.
+func (p *parser) atDeclBoundary() bool {
+	return p.decl && p.curToken.Pos.Column == 1
+}
+
+// parseProgram parses top-level definitions followed by the main expression.
+func (p *parser) parseProgram() (*ast.Program, error) {
+	prog := &ast.Program{}
+
+	for p.curToken.Kind == token.TokenKindLet || p.curToken.Kind == token.TokenKindLetrec || p.curToken.Kind == token.TokenKindType {
+		p.decl = true
+		var decl *ast.Decl
+		var err error
+		if p.curToken.Kind == token.TokenKindType {
+			var typeDecl *ast.TypeDecl
+			if typeDecl, err = p.parseTypeDecl(); err == nil {
+				prog.Types = append(prog.Types, typeDecl)
+			}
+		} else {
+			decl, err = p.parseBinding()
+		}
+		if err != nil {
+			// Skip to the next definition
+			if err := p.synchronize(err); err != errSkipped {
+				return nil, err
+			}
+			if p.curToken.Kind == token.TokenKindEOF {
+				// The main expression may have been skipped as part of the definition
+				prog.Main = &ast.ErrorExpr{Pos: p.curToken.Pos, End: p.prevEnd}
+				return prog, nil
+			}
+			continue
+		}
+		if decl == nil {
+			continue
+		}
+
+		// A binding followed by 'in' is a let expression serving as the main expression
+		if p.curToken.Kind == token.TokenKindIn {
+			p.decl = false
+			expr, err := p.parseLetBody(decl)
+			if err != nil {
+				if err := p.synchronize(err); err != errSkipped {
+					return nil, err
+				}
+				expr = &ast.ErrorExpr{Pos: decl.Pos, End: p.prevEnd}
+			}
+			prog.Main = expr
+			break
+		}
+
+		prog.Decls = append(prog.Decls, decl)
+	}
+
+	// The main expression extends to the end of the program, across lines starting at column 1
+	p.decl = false
+	if prog.Main == nil {
+		pos := p.curToken.Pos
+		main, err := p.parseExpr()
+		if err != nil {
+			// Nothing follows the main expression to resume at
+			if err := p.synchronize(err); err != errSkipped {
+				return nil, err
+			}
+			prog.Main = &ast.ErrorExpr{Pos: pos, End: p.prevEnd}
+			return prog, nil
+		}
+		prog.Main = main
+	}
+
+	// Expect EOF
+	if p.curToken.Kind != token.TokenKindEOF {
+		return nil, newParseError(p.curToken, fmt.Sprintf("unexpected token after main expression: %v", p.curToken.Kind))
+	}
+
+	return prog, nil
+}
+
+// parseExpr parses an expression with infix operators.
 func (p *parser) parseExpr() (ast.Expr, error) {
-	expr, err := p.parsePrimary()
+	return p.parseBinary(1, false)
+}
+
+// parseBinary parses operands separated by infix operators of precedence at least minPrec, by precedence climbing.
+// An operator application desugars to the application of its builtin: a + b is add a b.
+// If section is set, an operator followed by ')' ends the expression as a left section: (a +) is add a.
+func (p *parser) parseBinary(minPrec int, section bool) (ast.Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.binaryOperator()
+		if !ok || op.Prec < minPrec {
+			return left, nil
+		}
+
+		// Consume the operator
+		opVar, err := p.parseOperator(op)
+		if err != nil {
+			return nil, err
+		}
+
+		if section && p.curToken.Kind == token.TokenKindRParen {
+			return &ast.AppExpr{Pos: left.Position(), End: p.prevEnd, Func: opVar, Arg: left}, nil
+		}
+
+		// Parse the right operand, which only takes tighter operators unless the operator is right-associative
+		rightPrec := op.Prec + 1
+		if op.Assoc == ast.AssocRight {
+			rightPrec = op.Prec
+		}
+		right, err := p.parseBinary(rightPrec, false)
+		if err != nil {
+			return nil, err
+		}
+		left = &ast.AppExpr{
+			Pos:  left.Position(),
+			End:  p.prevEnd,
+			Func: &ast.AppExpr{Pos: left.Position(), End: opVar.End, Func: opVar, Arg: left},
+			Arg:  right,
+		}
+
+		if next, ok := p.binaryOperator(); ok && op.Assoc == ast.AssocNone && next.Prec == op.Prec {
+			return nil, newParseError(p.curToken, fmt.Sprintf("operator %s cannot follow %s without parentheses", next.Symbol, op.Symbol))
+		}
+	}
+}
+
+// binaryOperator returns the infix operator of the current token, if any.
+func (p *parser) binaryOperator() (ast.Operator, bool) {
+	if p.atDeclBoundary() {
+		return ast.Operator{}, false
+	}
+	if p.variant && p.curToken.Kind == token.TokenKindRAngle {
+		return ast.Operator{}, false
+	}
+	op, ok := ast.BinaryOperators[p.curToken.Value]
+	return op, ok
+}
+
+// parseOperator consumes the operator op and returns the variable of its builtin.
+func (p *parser) parseOperator(op ast.Operator) (*ast.VarExpr, error) {
+	pos := p.curToken.Pos
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	return &ast.VarExpr{Pos: pos, End: p.prevEnd, Name: op.Builtin, Op: op.Symbol}, nil
+}
+
+// parseUnary parses an application preceded by any number of prefix operators: -e is neg e and !e is not e.
+func (p *parser) parseUnary() (ast.Expr, error) {
+	op, ok := ast.UnaryOperators[p.curToken.Value]
+	if !ok || (p.curToken.Kind != token.TokenKindMinus && p.curToken.Kind != token.TokenKindBang) {
+		return p.parseApplication()
+	}
+
+	// Consume the operator
+	opVar, err := p.parseOperator(op)
+	if err != nil {
+		return nil, err
+	}
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &ast.AppExpr{Pos: opVar.Pos, End: p.prevEnd, Func: opVar, Arg: operand}, nil
+}
+
+// parseApplication parses an expression with left-associative application.
+func (p *parser) parseApplication() (ast.Expr, error) {
+	expr, err := p.parsePostfix()
 	if err != nil {
 		return nil, err
 	}
 
 	// Handle application (left-associative)
 	for {
+		if p.curToken.Kind == token.TokenKindLBracket && !p.atDeclBoundary() && p.startsTypeArg() {
+			typeArg, err := p.parseTypeArg()
+			if err != nil {
+				return nil, err
+			}
+			expr = &ast.TyAppExpr{
+				Pos:     expr.Position(),
+				End:     p.prevEnd,
+				Func:    expr,
+				TypeArg: typeArg,
+			}
+			continue
+		}
 		if !p.canStartExpr() {
 			return expr, nil
 		}
-		arg, err := p.parsePrimary()
+		arg, err := p.parsePostfix()
 		if err != nil {
 			return nil, err
 		}
 		expr = &ast.AppExpr{
 			Pos:  expr.Position(),
+			End:  p.prevEnd,
 			Func: expr,
 			Arg:  arg,
 		}
 	}
 }
 
+// canStartExpr reports whether the current token can start an argument of an application.
+// 'let' and 'letrec' are excluded so that a top-level definition ends where the next one begins.
+// '<' is excluded so that a variant passed as an argument must be parenthesized.
 func (p *parser) canStartExpr() bool {
+	if p.atDeclBoundary() {
+		return false
+	}
 	switch p.curToken.Kind {
-	case token.TokenKindLambda, token.TokenKindLParen,
+	case token.TokenKindLambda, token.TokenKindTyLambda, token.TokenKindLParen,
 		token.TokenKindTrue, token.TokenKindFalse,
 		token.TokenKindIf, token.TokenKindInt,
-		token.TokenKindIdent:
+		token.TokenKindFloat, token.TokenKindString, token.TokenKindChar,
+		token.TokenKindIdent, token.TokenKindFix, token.TokenKindNil,
+		token.TokenKindInl, token.TokenKindInr, token.TokenKindCase,
+		token.TokenKindLBrace, token.TokenKindLBracket, token.TokenKindMatch,
+		token.TokenKindFold, token.TokenKindUnfold:
+		return true
+	default:
+		return false
+	}
+}
+
+// startsTypeArg reports whether the current '[' after a function starts a type argument rather than a list argument.
+// It does if the next token can start a type, so a list argument whose first element starts with
+// a variable, '(', '{' or '<' must be parenthesized.
+func (p *parser) startsTypeArg() bool {
+	return startsType(p.peekToken.Kind)
+}
+
+// startsType reports whether a token of kind can start a base type.
+func startsType(kind token.TokenKind) bool {
+	switch kind {
+	case token.TokenKindIdent, token.TokenKindLParen, token.TokenKindLBrace, token.TokenKindLAngle,
+		token.TokenKindForall, token.TokenKindMu, token.TokenKindBoolType, token.TokenKindIntType,
+		token.TokenKindUnitType, token.TokenKindStringType, token.TokenKindCharType,
+		token.TokenKindFloatType, token.TokenKindListType:
 		return true
 	default:
 		return false
 	}
 }
 
+// parsePostfix parses a primary expression followed by any number of projections
+func (p *parser) parsePostfix() (ast.Expr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.curToken.Kind == token.TokenKindDot && !p.atDeclBoundary() {
+		// Consume '.'
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Parse record label
+		if p.curToken.Kind == token.TokenKindIdent {
+			label := p.curToken.Value
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			expr = &ast.RecordProjExpr{
+				Pos:    expr.Position(),
+				End:    p.prevEnd,
+				Record: expr,
+				Label:  label,
+			}
+			continue
+		}
+
+		// Parse tuple index
+		if p.curToken.Kind != token.TokenKindInt {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected tuple index or label after '.': %v", p.curToken.Kind))
+		}
+		index, err := strconv.Atoi(p.curToken.Value)
+		if err != nil || index < 1 {
+			return nil, newParseError(p.curToken, fmt.Sprintf("invalid tuple index: %v", p.curToken.Value))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		expr = &ast.ProjExpr{
+			Pos:   expr.Position(),
+			End:   p.prevEnd,
+			Tuple: expr,
+			Index: index,
+		}
+	}
+
+	return expr, nil
+}
+
 // parsePrimary parses a primary expression (non-application)
 func (p *parser) parsePrimary() (ast.Expr, error) {
 	switch p.curToken.Kind {
 	case token.TokenKindLambda:
 		return p.parseAbstraction()
+	case token.TokenKindTyLambda:
+		return p.parseTyAbstraction()
 	case token.TokenKindLParen:
 		return p.parseGrouping()
 	case token.TokenKindTrue:
+		pos := p.curToken.Pos
 		if err := p.nextToken(); err != nil {
 			return nil, err
 		}
 		return &ast.BoolExpr{
-			Pos:   p.curToken.Pos,
+			Pos:   pos,
+			End:   p.prevEnd,
 			Value: true,
 		}, nil
 	case token.TokenKindFalse:
+		pos := p.curToken.Pos
 		if err := p.nextToken(); err != nil {
 			return nil, err
 		}
 		return &ast.BoolExpr{
-			Pos:   p.curToken.Pos,
+			Pos:   pos,
+			End:   p.prevEnd,
 			Value: false,
 		}, nil
 	case token.TokenKindIf:
 		return p.parseIfExpr()
+	case token.TokenKindLet, token.TokenKindLetrec:
+		return p.parseLetExpr()
+	case token.TokenKindFix:
+		return p.parseFixExpr()
+	case token.TokenKindInl, token.TokenKindInr:
+		return p.parseInjExpr()
+	case token.TokenKindFold, token.TokenKindUnfold:
+		return p.parseFoldExpr()
+	case token.TokenKindCase:
+		return p.parseCaseExpr()
+	case token.TokenKindMatch:
+		return p.parseMatchExpr()
+	case token.TokenKindLBrace:
+		return p.parseRecordExpr()
+	case token.TokenKindLAngle:
+		return p.parseVariantExpr()
+	case token.TokenKindNil:
+		return p.parseNilExpr()
+	case token.TokenKindLBracket:
+		return p.parseListExpr()
 	case token.TokenKindInt:
 		value := p.curToken.Value
 		pos := p.curToken.Pos
@@ -125,13 +564,49 @@ func (p *parser) parsePrimary() (ast.Expr, error) {
 			return nil, err
 		}
 		intVal, err := strconv.ParseInt(value, 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			// The value of a literal out of the range of Int is kept for arbitrary-precision integers
+			bigVal, ok := new(big.Int).SetString(value, 10)
+			if ok {
+				return &ast.IntExpr{Pos: pos, End: p.prevEnd, Big: bigVal}, nil
+			}
+		}
 		if err != nil {
 			return nil, newParseError(p.curToken, fmt.Sprintf("invalid integer literal: %v", value))
 		}
 		return &ast.IntExpr{
 			Pos:   pos,
+			End:   p.prevEnd,
 			Value: int(intVal),
 		}, nil
+	case token.TokenKindFloat:
+		value := p.curToken.Value
+		pos := p.curToken.Pos
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, newParseError(p.curToken, fmt.Sprintf("invalid float literal: %v", value))
+		}
+		return &ast.FloatExpr{
+			Pos:   pos,
+			End:   p.prevEnd,
+			Value: floatVal,
+		}, nil
+	case token.TokenKindString, token.TokenKindChar:
+		// The lexer has checked the escape sequences of the literal
+		kind := p.curToken.Kind
+		value, _ := token.Unquote(p.curToken.Value)
+		pos := p.curToken.Pos
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		if kind == token.TokenKindChar {
+			r, _ := utf8.DecodeRuneInString(value)
+			return &ast.CharExpr{Pos: pos, End: p.prevEnd, Value: r}, nil
+		}
+		return &ast.StringExpr{Pos: pos, End: p.prevEnd, Value: value}, nil
 	case token.TokenKindIdent:
 		pos := p.curToken.Pos
 		name := p.curToken.Value
@@ -140,6 +615,7 @@ func (p *parser) parsePrimary() (ast.Expr, error) {
 		}
 		return &ast.VarExpr{
 			Pos:  pos,
+			End:  p.prevEnd,
 			Name: name,
 		}, nil
 	default:
@@ -166,23 +642,64 @@ func (p *parser) parseAbstraction() (ast.Expr, error) {
 		return nil, err
 	}
 
-	// Expect ':'
-	if p.curToken.Kind != token.TokenKindColon {
-		return nil, newParseError(p.curToken, fmt.Sprintf("expected ':' after parameter name: %v", p.curToken.Kind))
+	// Parse optional parameter type
+	var paramType ast.Type
+	if p.curToken.Kind == token.TokenKindColon {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		paramType = t
+	}
+
+	// Expect '.'
+	if p.curToken.Kind != token.TokenKindDot {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '.' after parameter: %v", p.curToken.Kind))
 	}
 	if err := p.nextToken(); err != nil {
 		return nil, err
 	}
 
-	// Parse parameter type
-	paramType, err := p.parseType()
+	// Parse body
+	body, err := p.parseExpr()
 	if err != nil {
 		return nil, err
 	}
 
+	return &ast.AbsExpr{
+		Pos:       pos,
+		End:       p.prevEnd,
+		Param:     param,
+		ParamType: paramType,
+		Body:      body,
+	}, nil
+}
+
+// parseTyAbstraction parses a type abstraction: /\var. expr
+func (p *parser) parseTyAbstraction() (ast.Expr, error) {
+	// Save position of type lambda
+	pos := p.curToken.Pos
+
+	// Consume '/\'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse type variable
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected type variable after '/\\': %v", p.curToken.Kind))
+	}
+	typeVar := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
 	// Expect '.'
 	if p.curToken.Kind != token.TokenKindDot {
-		return nil, newParseError(p.curToken, fmt.Sprintf("expected '.' after parameter type: %v", p.curToken.Kind))
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '.' after type variable: %v", p.curToken.Kind))
 	}
 	if err := p.nextToken(); err != nil {
 		return nil, err
@@ -194,105 +711,1126 @@ func (p *parser) parseAbstraction() (ast.Expr, error) {
 		return nil, err
 	}
 
-	return &ast.AbsExpr{
-		Pos:       pos,
-		Param:     param,
-		ParamType: paramType,
-		Body:      body,
+	return &ast.TyAbsExpr{
+		Pos:     pos,
+		End:     p.prevEnd,
+		TypeVar: typeVar,
+		Body:    body,
 	}, nil
 }
 
-// parseGrouping parses a parenthesized expression
-func (p *parser) parseGrouping() (ast.Expr, error) {
-	// Consume '('
+// parseTypeArg parses the type argument of a type application: [type]
+func (p *parser) parseTypeArg() (ast.Type, error) {
+	// Consume '['
 	if err := p.nextToken(); err != nil {
 		return nil, err
 	}
 
-	expr, err := p.parseExpr()
+	typ, err := p.parseType()
 	if err != nil {
 		return nil, err
 	}
 
-	// Expect ')'
-	if p.curToken.Kind != token.TokenKindRParen {
-		return nil, newParseError(p.curToken, fmt.Sprintf("expected ')': %v", p.curToken.Kind))
+	// Expect ']'
+	if p.curToken.Kind != token.TokenKindRBracket {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected ']' after type argument: %v", p.curToken.Kind))
 	}
 	if err := p.nextToken(); err != nil {
 		return nil, err
 	}
 
-	return expr, nil
+	return typ, nil
 }
 
-// parseIfExpr parses a conditional expression
-func (p *parser) parseIfExpr() (ast.Expr, error) {
-	// Save position of 'if'
+// parseFixExpr parses a fixed point expression: fix expr
+func (p *parser) parseFixExpr() (ast.Expr, error) {
+	// Save position of 'fix'
 	pos := p.curToken.Pos
 
-	// Consume 'if'
+	// Consume 'fix'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse the function whose fixed point is taken
+	fn, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.FixExpr{
+		Pos:  pos,
+		End:  p.prevEnd,
+		Func: fn,
+	}, nil
+}
+
+// parseFoldExpr parses a fold or an unfold of a recursive type: fold [type] expr or unfold [type] expr
+func (p *parser) parseFoldExpr() (ast.Expr, error) {
+	// Save position and keyword of 'fold' or 'unfold'
+	pos := p.curToken.Pos
+	keyword := p.curToken
+
+	// Consume 'fold' or 'unfold'
 	if err := p.nextToken(); err != nil {
 		return nil, err
 	}
 
-	// Parse condition
-	cond, err := p.parseExpr()
+	// Parse the recursive type in brackets
+	if p.curToken.Kind != token.TokenKindLBracket {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '[' after '%s': %v", keyword.Value, p.curToken.Kind))
+	}
+	typ, err := p.parseTypeArg()
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the value folded or unfolded
+	value, err := p.parsePostfix()
 	if err != nil {
 		return nil, err
 	}
 
-	// Expect 'then'
-	if p.curToken.Kind != token.TokenKindThen {
-		return nil, newParseError(p.curToken, fmt.Sprintf("expected 'then': %v", p.curToken.Kind))
+	if keyword.Kind == token.TokenKindUnfold {
+		return &ast.UnfoldExpr{
+			Pos:   pos,
+			End:   p.prevEnd,
+			Type:  typ,
+			Value: value,
+		}, nil
 	}
+	return &ast.FoldExpr{
+		Pos:   pos,
+		End:   p.prevEnd,
+		Type:  typ,
+		Value: value,
+	}, nil
+}
+
+// parseInjExpr parses an injection: inl expr as type or inr expr as type
+func (p *parser) parseInjExpr() (ast.Expr, error) {
+	// Save position and side of 'inl' or 'inr'
+	pos := p.curToken.Pos
+	left := p.curToken.Kind == token.TokenKindInl
+
+	// Consume 'inl' or 'inr'
 	if err := p.nextToken(); err != nil {
 		return nil, err
 	}
 
-	// Parse then branch
-	thenExpr, err := p.parseExpr()
+	// Parse injected value
+	value, err := p.parsePostfix()
 	if err != nil {
 		return nil, err
 	}
 
-	// Expect 'else'
-	if p.curToken.Kind != token.TokenKindElse {
-		return nil, newParseError(p.curToken, fmt.Sprintf("expected 'else': %v", p.curToken.Kind))
+	// Expect 'as'
+	if p.curToken.Kind != token.TokenKindAs {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected 'as' after injected value: %v", p.curToken.Kind))
 	}
 	if err := p.nextToken(); err != nil {
 		return nil, err
 	}
 
-	// Parse else branch
-	elseExpr, err := p.parseExpr()
+	// Parse sum type
+	typ, err := p.parseType()
 	if err != nil {
 		return nil, err
 	}
 
-	return &ast.IfExpr{
-		Pos:  pos,
-		Cond: cond,
-		Then: thenExpr,
-		Else: elseExpr,
+	return &ast.InjExpr{
+		Pos:   pos,
+		End:   p.prevEnd,
+		Left:  left,
+		Value: value,
+		Type:  typ,
 	}, nil
 }
 
-// parseType parses a type with right-associative arrow
-func (p *parser) parseType() (ast.Type, error) {
-	baseType, err := p.parseBaseType()
+// parseCaseExpr parses a case analysis: case expr of inl var => expr | inr var => expr
+func (p *parser) parseCaseExpr() (ast.Expr, error) {
+	// Save position of 'case'
+	pos := p.curToken.Pos
+
+	// Consume 'case'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse scrutinee
+	scrutinee, err := p.parseExpr()
 	if err != nil {
 		return nil, err
 	}
 
-	// Check for function type (right-associative)
-	if p.curToken.Kind == token.TokenKindArrow {
-		if err := p.nextToken(); err != nil {
-			return nil, err
-		}
-		toType, err := p.parseType()
-		if err != nil {
-			return nil, err
-		}
+	// Expect 'of'
+	if p.curToken.Kind != token.TokenKindOf {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected 'of': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// A branch starting with '<' introduces a variant case analysis, and one starting with '[' a list case analysis
+	if p.curToken.Kind == token.TokenKindLAngle {
+		return p.parseVariantCaseBranches(pos, scrutinee)
+	}
+	if p.curToken.Kind == token.TokenKindLBracket {
+		return p.parseListCaseBranches(pos, scrutinee)
+	}
+
+	// Parse left branch
+	leftVar, left, err := p.parseSumBranch(token.TokenKindInl, "inl")
+	if err != nil {
+		return nil, err
+	}
+
+	// Expect '|'
+	if p.curToken.Kind != token.TokenKindBar {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '|': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse right branch
+	rightVar, right, err := p.parseSumBranch(token.TokenKindInr, "inr")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.CaseExpr{
+		Pos:       pos,
+		End:       p.prevEnd,
+		Scrutinee: scrutinee,
+		LeftVar:   leftVar,
+		Left:      left,
+		RightVar:  rightVar,
+		Right:     right,
+	}, nil
+}
+
+// parseMatchExpr parses a pattern match: match expr with | pattern -> expr | pattern -> expr ...
+func (p *parser) parseMatchExpr() (ast.Expr, error) {
+	// Save position of 'match'
+	pos := p.curToken.Pos
+
+	// Consume 'match'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse scrutinee
+	scrutinee, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	// Expect 'with'
+	if p.curToken.Kind != token.TokenKindWith {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected 'with': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// The first arm may be preceded by '|'
+	if p.curToken.Kind == token.TokenKindBar {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	var arms []ast.MatchArm
+	for {
+		armPos := p.curToken.Pos
+
+		// Parse pattern
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		// Expect '->'
+		if p.curToken.Kind != token.TokenKindArrow {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected '->' after pattern: %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Parse arm body
+		body, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		arms = append(arms, ast.MatchArm{Pos: armPos, End: p.prevEnd, Pattern: pattern, Body: body})
+
+		// Another arm follows '|'
+		if p.curToken.Kind != token.TokenKindBar {
+			break
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.MatchExpr{
+		Pos:       pos,
+		End:       p.prevEnd,
+		Scrutinee: scrutinee,
+		Arms:      arms,
+	}, nil
+}
+
+// parsePattern parses a pattern: a constructor applied to argument patterns, or an atomic pattern
+func (p *parser) parsePattern() (ast.Pattern, error) {
+	if p.curToken.Kind != token.TokenKindIdent || !isConstructorName(p.curToken.Value) {
+		return p.parseAtomicPattern()
+	}
+
+	pos := p.curToken.Pos
+	name := p.curToken.Value
+
+	// Consume constructor name
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse argument patterns
+	var args []ast.Pattern
+	for startsPattern(p.curToken.Kind) {
+		arg, err := p.parseAtomicPattern()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	return &ast.ConstructorPattern{Pos: pos, End: p.prevEnd, Name: name, Args: args}, nil
+}
+
+// parseAtomicPattern parses a wildcard, a variable, a literal, a constructor without arguments
+// or a parenthesized pattern
+func (p *parser) parseAtomicPattern() (ast.Pattern, error) {
+	switch p.curToken.Kind {
+	case token.TokenKindIdent:
+		pos := p.curToken.Pos
+		name := p.curToken.Value
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		switch {
+		case name == "_":
+			return &ast.WildcardPattern{Pos: pos, End: p.prevEnd}, nil
+		case isConstructorName(name):
+			return &ast.ConstructorPattern{Pos: pos, End: p.prevEnd, Name: name}, nil
+		default:
+			return &ast.VarPattern{Pos: pos, End: p.prevEnd, Name: name}, nil
+		}
+	case token.TokenKindTrue, token.TokenKindFalse, token.TokenKindInt,
+		token.TokenKindString, token.TokenKindChar:
+		value, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.LiteralPattern{Value: value}, nil
+	case token.TokenKindLParen:
+		pos := p.curToken.Pos
+
+		// Consume '('
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// () is the unit literal
+		if p.curToken.Kind == token.TokenKindRParen {
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			return &ast.LiteralPattern{Value: &ast.UnitExpr{Pos: pos, End: p.prevEnd}}, nil
+		}
+
+		// Parse grouped pattern
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		// Expect ')'
+		if p.curToken.Kind != token.TokenKindRParen {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected ')' in pattern: %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		return pattern, nil
+	default:
+		return nil, newParseError(p.curToken, fmt.Sprintf("unexpected token in pattern: %v", p.curToken.Kind))
+	}
+}
+
+// startsPattern reports whether a token of kind can start an atomic pattern.
+func startsPattern(kind token.TokenKind) bool {
+	switch kind {
+	case token.TokenKindIdent, token.TokenKindLParen, token.TokenKindTrue, token.TokenKindFalse,
+		token.TokenKindInt, token.TokenKindString, token.TokenKindChar:
+		return true
+	default:
+		return false
+	}
+}
+
+// isConstructorName reports whether name is the name of a constructor, which starts with an uppercase letter.
+func isConstructorName(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// parseSumBranch parses a branch of a case analysis: inl var => expr or inr var => expr
+func (p *parser) parseSumBranch(kind token.TokenKind, keyword string) (string, ast.Expr, error) {
+	// Expect 'inl' or 'inr'
+	if p.curToken.Kind != kind {
+		return "", nil, newParseError(p.curToken, fmt.Sprintf("expected '%s': %v", keyword, p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return "", nil, err
+	}
+
+	// Parse bound name
+	if p.curToken.Kind != token.TokenKindIdent {
+		return "", nil, newParseError(p.curToken, fmt.Sprintf("expected identifier after '%s': %v", keyword, p.curToken.Kind))
+	}
+	name := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return "", nil, err
+	}
+
+	// Expect '=>'
+	if p.curToken.Kind != token.TokenKindFatArrow {
+		return "", nil, newParseError(p.curToken, fmt.Sprintf("expected '=>': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return "", nil, err
+	}
+
+	// Parse branch body
+	body, err := p.parseExpr()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return name, body, nil
+}
+
+// parseListCaseBranches parses the branches of a list case analysis: [] => expr | var :: var => expr
+func (p *parser) parseListCaseBranches(pos token.Position, scrutinee ast.Expr) (ast.Expr, error) {
+	// Consume '['
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect ']'
+	if p.curToken.Kind != token.TokenKindRBracket {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected ']' in empty list pattern: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect '=>'
+	if p.curToken.Kind != token.TokenKindFatArrow {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '=>': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse empty list branch
+	nilBody, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	// Expect '|'
+	if p.curToken.Kind != token.TokenKindBar {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '|': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse head name
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected identifier in cons pattern: %v", p.curToken.Kind))
+	}
+	headVar := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect '::'
+	if p.curToken.Kind != token.TokenKindColonColon {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '::' in cons pattern: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse tail name
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected identifier after '::': %v", p.curToken.Kind))
+	}
+	tailVar := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect '=>'
+	if p.curToken.Kind != token.TokenKindFatArrow {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '=>': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse cons branch
+	consBody, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ListCaseExpr{
+		Pos:       pos,
+		End:       p.prevEnd,
+		Scrutinee: scrutinee,
+		Nil:       nilBody,
+		HeadVar:   headVar,
+		TailVar:   tailVar,
+		Cons:      consBody,
+	}, nil
+}
+
+// parseVariantCaseBranches parses the branches of a variant case analysis:
+// <label = var> => expr | <label = var> => expr | ...
+func (p *parser) parseVariantCaseBranches(pos token.Position, scrutinee ast.Expr) (ast.Expr, error) {
+	var branches []ast.VariantBranch
+	seen := map[string]bool{}
+	for {
+		// Save position of '<'
+		branchPos := p.curToken.Pos
+
+		// Expect '<'
+		if p.curToken.Kind != token.TokenKindLAngle {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected '<': %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Parse label
+		label, err := p.parseLabel(seen)
+		if err != nil {
+			return nil, err
+		}
+
+		// Expect '='
+		if p.curToken.Kind != token.TokenKindEqual {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected '=' after label: %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Parse bound name
+		if p.curToken.Kind != token.TokenKindIdent {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected identifier after '=': %v", p.curToken.Kind))
+		}
+		name := p.curToken.Value
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Expect '>'
+		if p.curToken.Kind != token.TokenKindRAngle {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected '>': %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Expect '=>'
+		if p.curToken.Kind != token.TokenKindFatArrow {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected '=>': %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Parse branch body
+		body, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		branches = append(branches, ast.VariantBranch{
+			Pos:   branchPos,
+			End:   p.prevEnd,
+			Label: label,
+			Var:   name,
+			Body:  body,
+		})
+
+		// Continue with next branch after '|'
+		if p.curToken.Kind != token.TokenKindBar {
+			break
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.VariantCaseExpr{
+		Pos:       pos,
+		End:       p.prevEnd,
+		Scrutinee: scrutinee,
+		Branches:  branches,
+	}, nil
+}
+
+// parseRecordExpr parses a record: {label = expr, ...}
+func (p *parser) parseRecordExpr() (ast.Expr, error) {
+	// Save position of '{'
+	pos := p.curToken.Pos
+
+	// Consume '{'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	var fields []ast.RecordField
+	seen := map[string]bool{}
+	for p.curToken.Kind != token.TokenKindRBrace {
+		if len(fields) > 0 {
+			// Expect ','
+			if p.curToken.Kind != token.TokenKindComma {
+				return nil, newParseError(p.curToken, fmt.Sprintf("expected ',' or '}': %v", p.curToken.Kind))
+			}
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+		}
+
+		// Parse label
+		label, err := p.parseLabel(seen)
+		if err != nil {
+			return nil, err
+		}
+
+		// Expect '='
+		if p.curToken.Kind != token.TokenKindEqual {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected '=' after label: %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Parse field value
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, ast.RecordField{
+			Label: label,
+			Value: value,
+		})
+	}
+
+	// Consume '}'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	return &ast.RecordExpr{
+		Pos:    pos,
+		End:    p.prevEnd,
+		Fields: fields,
+	}, nil
+}
+
+// parseNilExpr parses the empty list of a given element type: nil[type]
+func (p *parser) parseNilExpr() (ast.Expr, error) {
+	// Save position of 'nil'
+	pos := p.curToken.Pos
+
+	// Consume 'nil'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect '['
+	if p.curToken.Kind != token.TokenKindLBracket {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '[' after 'nil': %v", p.curToken.Kind))
+	}
+	elemType, err := p.parseTypeArg()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.NilExpr{
+		Pos:      pos,
+		End:      p.prevEnd,
+		ElemType: elemType,
+	}, nil
+}
+
+// parseListExpr parses a list literal: [expr, expr, ...]
+func (p *parser) parseListExpr() (ast.Expr, error) {
+	// Save position of '['
+	pos := p.curToken.Pos
+
+	// Consume '['
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// A '>' in brackets compares even in the value of a variant
+	variant := p.variant
+	p.variant = false
+	defer func() { p.variant = variant }()
+
+	var elems []ast.Expr
+	for p.curToken.Kind != token.TokenKindRBracket {
+		if len(elems) > 0 {
+			// Expect ','
+			if p.curToken.Kind != token.TokenKindComma {
+				return nil, newParseError(p.curToken, fmt.Sprintf("expected ',' or ']': %v", p.curToken.Kind))
+			}
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+		}
+
+		elem, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+
+	// Consume ']'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	return &ast.ListExpr{
+		Pos:   pos,
+		End:   p.prevEnd,
+		Elems: elems,
+	}, nil
+}
+
+// parseVariantExpr parses a variant: <label = expr> as type
+func (p *parser) parseVariantExpr() (ast.Expr, error) {
+	// Save position of '<'
+	pos := p.curToken.Pos
+
+	// Consume '<'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse label
+	label, err := p.parseLabel(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expect '='
+	if p.curToken.Kind != token.TokenKindEqual {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '=' after label: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse variant value, which a '>' ends
+	variant := p.variant
+	p.variant = true
+	value, err := p.parseExpr()
+	p.variant = variant
+	if err != nil {
+		return nil, err
+	}
+
+	// Expect '>'
+	if p.curToken.Kind != token.TokenKindRAngle {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '>': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect 'as'
+	if p.curToken.Kind != token.TokenKindAs {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected 'as' after variant: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse variant type
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.VariantExpr{
+		Pos:   pos,
+		End:   p.prevEnd,
+		Label: label,
+		Value: value,
+		Type:  typ,
+	}, nil
+}
+
+// parseLabel parses a label of a record or variant.
+// If seen is not nil, the label must not be in seen and is added to it.
+func (p *parser) parseLabel(seen map[string]bool) (string, error) {
+	if p.curToken.Kind != token.TokenKindIdent {
+		return "", newParseError(p.curToken, fmt.Sprintf("expected label: %v", p.curToken.Kind))
+	}
+	label := p.curToken.Value
+	if seen != nil {
+		if seen[label] {
+			return "", newParseError(p.curToken, fmt.Sprintf("duplicate label: %s", label))
+		}
+		seen[label] = true
+	}
+	if err := p.nextToken(); err != nil {
+		return "", err
+	}
+	return label, nil
+}
+
+// parseGrouping parses a parenthesized expression, a tuple or an operator section
+func (p *parser) parseGrouping() (ast.Expr, error) {
+	// Save position of '('
+	pos := p.curToken.Pos
+
+	// Consume '('
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// () is the unit value
+	if p.curToken.Kind == token.TokenKindRParen {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		return &ast.UnitExpr{Pos: pos, End: p.prevEnd}, nil
+	}
+
+	// A '>' in parentheses compares even in the value of a variant
+	variant := p.variant
+	p.variant = false
+	elems, err := p.parseGroupingElems()
+	p.variant = variant
+	if err != nil {
+		// Resume after the closing ')'
+		if err := p.synchronize(err, token.TokenKindRParen); err != nil {
+			return nil, err
+		}
+		elems = []ast.Expr{&ast.ErrorExpr{Pos: pos, End: p.prevEnd}}
+	}
+
+	// Consume ')'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	if len(elems) > 1 {
+		return &ast.TupleExpr{
+			Pos:   pos,
+			End:   p.prevEnd,
+			Elems: elems,
+		}, nil
+	}
+	return elems[0], nil
+}
+
+// parseGroupingElems parses the comma separated expressions of a grouping up to the closing ')'
+func (p *parser) parseGroupingElems() ([]ast.Expr, error) {
+	if op, ok := p.binaryOperator(); ok {
+		// Only (-) and (<) are sections of '-' and '<', which otherwise start a negation or a variant
+		prefix := p.curToken.Kind == token.TokenKindMinus || p.curToken.Kind == token.TokenKindLAngle
+		if !prefix || p.peekToken.Kind == token.TokenKindRParen {
+			expr, err := p.parseSection(op)
+			if err != nil {
+				return nil, err
+			}
+			return []ast.Expr{expr}, nil
+		}
+	}
+
+	expr, err := p.parseBinary(1, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse remaining tuple elements
+	elems := []ast.Expr{expr}
+	for p.curToken.Kind == token.TokenKindComma {
+		// Consume ','
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+
+	// Expect ')'
+	if p.curToken.Kind != token.TokenKindRParen {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected ')': %v", p.curToken.Kind))
+	}
+	return elems, nil
+}
+
+// parseSection parses a section starting with the operator op up to the closing ')':
+// (+) is add, and the right section (+ e) is \#. add # e, where # is ast.SectionParam.
+func (p *parser) parseSection(op ast.Operator) (ast.Expr, error) {
+	// Consume the operator
+	opVar, err := p.parseOperator(op)
+	if err != nil {
+		return nil, err
+	}
+	if p.curToken.Kind == token.TokenKindRParen {
+		return opVar, nil
+	}
+
+	// Parse the right operand as in an operator application
+	rightPrec := op.Prec + 1
+	if op.Assoc == ast.AssocRight {
+		rightPrec = op.Prec
+	}
+	right, err := p.parseBinary(rightPrec, false)
+	if err != nil {
+		return nil, err
+	}
+	if p.curToken.Kind != token.TokenKindRParen {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected ')' after section: %v", p.curToken.Kind))
+	}
+
+	// The parameter has an empty span so that it is not found at the position of the operator
+	param := &ast.VarExpr{Pos: opVar.Pos, End: opVar.Pos, Name: ast.SectionParam}
+	return &ast.AbsExpr{
+		Pos:   opVar.Pos,
+		End:   p.prevEnd,
+		Param: ast.SectionParam,
+		Body: &ast.AppExpr{
+			Pos:  opVar.Pos,
+			End:  p.prevEnd,
+			Func: &ast.AppExpr{Pos: opVar.Pos, End: opVar.End, Func: opVar, Arg: param},
+			Arg:  right,
+		},
+	}, nil
+}
+
+// parseIfExpr parses a conditional expression
+func (p *parser) parseIfExpr() (ast.Expr, error) {
+	// Save position of 'if'
+	pos := p.curToken.Pos
+
+	// Consume 'if'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse condition and 'then'
+	cond, err := p.parseIfPart(token.TokenKindThen, "then")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse then branch and 'else'
+	thenExpr, err := p.parseIfPart(token.TokenKindElse, "else")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse else branch
+	elseExpr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.IfExpr{
+		Pos:  pos,
+		End:  p.prevEnd,
+		Cond: cond,
+		Then: thenExpr,
+		Else: elseExpr,
+	}, nil
+}
+
+// parseIfPart parses an expression followed by the given keyword of a conditional.
+// After a syntax error, it resumes at the keyword with an ast.ErrorExpr in place of the expression.
+func (p *parser) parseIfPart(kind token.TokenKind, keyword string) (ast.Expr, error) {
+	pos := p.curToken.Pos
+	expr, err := p.parseExpr()
+	if err == nil && p.curToken.Kind != kind {
+		err = newParseError(p.curToken, fmt.Sprintf("expected '%s': %v", keyword, p.curToken.Kind))
+	}
+	if err != nil {
+		if err := p.synchronize(err, kind); err != nil {
+			return nil, err
+		}
+		expr = &ast.ErrorExpr{Pos: pos, End: p.prevEnd}
+	}
+
+	// Consume keyword
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// parseLetExpr parses a let expression: let var [: type] = expr in expr
+// or a recursive let expression: letrec var [: type] = expr in expr
+func (p *parser) parseLetExpr() (ast.Expr, error) {
+	decl, err := p.parseBinding()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseLetBody(decl)
+}
+
+// parseBinding parses a binding: let var [: type] = expr
+// or a recursive binding: letrec var [: type] = expr
+func (p *parser) parseBinding() (*ast.Decl, error) {
+	// Save position and kind of 'let' or 'letrec'
+	pos := p.curToken.Pos
+	keyword := p.curToken.Value
+	rec := p.curToken.Kind == token.TokenKindLetrec
+
+	// Consume 'let' or 'letrec'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse bound name
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected identifier after '%s': %v", keyword, p.curToken.Kind))
+	}
+	name := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse optional type annotation
+	var typ ast.Type
+	if p.curToken.Kind == token.TokenKindColon {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		typ = t
+	}
+
+	// Expect '='
+	if p.curToken.Kind != token.TokenKindEqual {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '=' after bound name: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse bound expression
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	// letrec f [: T] = e is sugar for let f [: T] = fix (\f[:T]. e)
+	if rec {
+		value = &ast.FixExpr{
+			Pos: pos,
+			End: p.prevEnd,
+			Func: &ast.AbsExpr{
+				Pos:       pos,
+				End:       p.prevEnd,
+				Param:     name,
+				ParamType: typ,
+				Body:      value,
+			},
+		}
+	}
+
+	return &ast.Decl{
+		Pos:   pos,
+		End:   p.prevEnd,
+		Name:  name,
+		Type:  typ,
+		Value: value,
+	}, nil
+}
+
+// parseLetBody parses the 'in' part of a let expression for the given binding.
+func (p *parser) parseLetBody(decl *ast.Decl) (ast.Expr, error) {
+	// Expect 'in'
+	if p.curToken.Kind != token.TokenKindIn {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected 'in': %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse body
+	body, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.LetExpr{
+		Pos:   decl.Pos,
+		End:   p.prevEnd,
+		Name:  decl.Name,
+		Type:  decl.Type,
+		Value: decl.Value,
+		Body:  body,
+	}, nil
+}
+
+// parseType parses a type with right-associative arrow
+func (p *parser) parseType() (ast.Type, error) {
+	if p.curToken.Kind == token.TokenKindForall {
+		return p.parseForallType()
+	}
+	if p.curToken.Kind == token.TokenKindMu {
+		return p.parseRecType()
+	}
+
+	baseType, err := p.parseSumType()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for function type (right-associative)
+	if p.curToken.Kind == token.TokenKindArrow {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		toType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
 		return &ast.FuncType{
 			From: baseType,
 			To:   toType,
@@ -302,6 +1840,127 @@ func (p *parser) parseType() (ast.Type, error) {
 	return baseType, nil
 }
 
+// parseForallType parses a universal type, whose body extends as far right as possible: forall var. type
+func (p *parser) parseForallType() (ast.Type, error) {
+	// Consume 'forall'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse bound type variable
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected type variable after 'forall': %v", p.curToken.Kind))
+	}
+	typeVar := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect '.'
+	if p.curToken.Kind != token.TokenKindDot {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '.' after type variable: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ForallType{
+		Var:  typeVar,
+		Body: body,
+	}, nil
+}
+
+// parseRecType parses a recursive type, whose body extends as far right as possible: mu var. type
+func (p *parser) parseRecType() (ast.Type, error) {
+	// Consume 'mu'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse bound type variable
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected type variable after 'mu': %v", p.curToken.Kind))
+	}
+	typeVar := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect '.'
+	if p.curToken.Kind != token.TokenKindDot {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '.' after type variable: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.RecType{
+		Var:  typeVar,
+		Body: body,
+	}, nil
+}
+
+// parseSumType parses a left-associative sum type, which binds tighter than arrow
+func (p *parser) parseSumType() (ast.Type, error) {
+	typ, err := p.parseProductType()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.curToken.Kind == token.TokenKindPlus {
+		// Consume '+'
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseProductType()
+		if err != nil {
+			return nil, err
+		}
+		typ = &ast.SumType{
+			Left:  typ,
+			Right: right,
+		}
+	}
+
+	return typ, nil
+}
+
+// parseProductType parses a product type, which binds tighter than sum
+func (p *parser) parseProductType() (ast.Type, error) {
+	baseType, err := p.parseBaseType()
+	if err != nil {
+		return nil, err
+	}
+
+	elems := []ast.Type{baseType}
+	for p.curToken.Kind == token.TokenKindStar {
+		// Consume '*'
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseBaseType()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+
+	if len(elems) > 1 {
+		return &ast.ProductType{Elems: elems}, nil
+	}
+	return baseType, nil
+}
+
 // parseBaseType parses a base type or grouped type
 func (p *parser) parseBaseType() (ast.Type, error) {
 	switch p.curToken.Kind {
@@ -315,6 +1974,46 @@ func (p *parser) parseBaseType() (ast.Type, error) {
 			return nil, err
 		}
 		return &ast.IntType{}, nil
+	case token.TokenKindUnitType:
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		return &ast.UnitType{}, nil
+	case token.TokenKindStringType:
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		return &ast.StringType{}, nil
+	case token.TokenKindCharType:
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		return &ast.CharType{}, nil
+	case token.TokenKindFloatType:
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		return &ast.FloatType{}, nil
+	case token.TokenKindListType:
+		// The element type binds as tightly as a base type: List Int * Bool is (List Int) * Bool
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseBaseType()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ListType{Elem: elem}, nil
+	case token.TokenKindIdent:
+		name := p.curToken.Value
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		return &ast.TypeVar{Name: name}, nil
+	case token.TokenKindLBrace:
+		return p.parseFieldTypes(token.TokenKindLBrace, token.TokenKindRBrace)
+	case token.TokenKindLAngle:
+		return p.parseFieldTypes(token.TokenKindLAngle, token.TokenKindRAngle)
 	case token.TokenKindLParen:
 		// Grouped type
 		if err := p.nextToken(); err != nil {
@@ -335,3 +2034,152 @@ func (p *parser) parseBaseType() (ast.Type, error) {
 		return nil, newParseError(p.curToken, fmt.Sprintf("unexpected token in type: %v", p.curToken.Kind))
 	}
 }
+
+// parseTypeDecl parses an algebraic data type declaration: type Name = C type ... | C type ... | ...
+func (p *parser) parseTypeDecl() (*ast.TypeDecl, error) {
+	// Save position of 'type'
+	pos := p.curToken.Pos
+
+	// Consume 'type'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse type name
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected type name after 'type': %v", p.curToken.Kind))
+	}
+	name := p.curToken.Value
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Expect '='
+	if p.curToken.Kind != token.TokenKindEqual {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected '=' after type name: %v", p.curToken.Kind))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// The first constructor may be preceded by '|'
+	if p.curToken.Kind == token.TokenKindBar {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	var constructors []*ast.Constructor
+	for {
+		constructor, err := p.parseConstructor()
+		if err != nil {
+			return nil, err
+		}
+		constructors = append(constructors, constructor)
+
+		// Another constructor follows '|'
+		if p.curToken.Kind != token.TokenKindBar {
+			break
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.TypeDecl{
+		Pos:          pos,
+		End:          p.prevEnd,
+		Name:         name,
+		Constructors: constructors,
+	}, nil
+}
+
+// parseConstructor parses a constructor of a data type followed by the types of its arguments: C type ...
+func (p *parser) parseConstructor() (*ast.Constructor, error) {
+	pos := p.curToken.Pos
+
+	// Parse constructor name
+	if p.curToken.Kind != token.TokenKindIdent {
+		return nil, newParseError(p.curToken, fmt.Sprintf("expected constructor name: %v", p.curToken.Kind))
+	}
+	name := p.curToken.Value
+	if !isConstructorName(name) {
+		return nil, newParseError(p.curToken, fmt.Sprintf("constructor name must start with an uppercase letter: %s", name))
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	// Parse argument types, which end at the next definition
+	var params []ast.Type
+	for startsType(p.curToken.Kind) && p.curToken.Kind != token.TokenKindForall && p.curToken.Kind != token.TokenKindMu && !p.atDeclBoundary() {
+		param, err := p.parseBaseType()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+	}
+
+	return &ast.Constructor{Pos: pos, End: p.prevEnd, Name: name, Params: params}, nil
+}
+
+// parseFieldTypes parses a record type {label: type, ...} or a variant type <label: type, ...>
+func (p *parser) parseFieldTypes(open, close token.TokenKind) (ast.Type, error) {
+	// Consume '{' or '<'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	var fields []ast.Field
+	seen := map[string]bool{}
+	for p.curToken.Kind != close {
+		if len(fields) > 0 {
+			// Expect ','
+			if p.curToken.Kind != token.TokenKindComma {
+				return nil, newParseError(p.curToken, fmt.Sprintf("expected ',' or closing bracket: %v", p.curToken.Kind))
+			}
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+		}
+
+		// Parse label
+		label, err := p.parseLabel(seen)
+		if err != nil {
+			return nil, err
+		}
+
+		// Expect ':'
+		if p.curToken.Kind != token.TokenKindColon {
+			return nil, newParseError(p.curToken, fmt.Sprintf("expected ':' after label: %v", p.curToken.Kind))
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		// Parse field type
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, ast.Field{
+			Label: label,
+			Type:  typ,
+		})
+	}
+
+	if open == token.TokenKindLAngle && len(fields) == 0 {
+		return nil, newParseError(p.curToken, "variant type must have at least one label")
+	}
+
+	// Consume '}' or '>'
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+
+	if open == token.TokenKindLAngle {
+		return &ast.VariantType{Fields: fields}, nil
+	}
+	return &ast.RecordType{Fields: fields}, nil
+}
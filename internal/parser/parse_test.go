@@ -1,10 +1,14 @@
 package parser_test
 
 import (
+	"errors"
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/shota3506/gostlc/internal/ast"
 	"github.com/shota3506/gostlc/internal/parser"
+	"github.com/shota3506/gostlc/internal/token"
 )
 
 func TestParser(t *testing.T) {
@@ -154,6 +158,613 @@ func TestParser(t *testing.T) {
 			input:    `-456`,
 			expected: &ast.IntExpr{Value: -456},
 		},
+		{
+			name:  "Let expression",
+			input: `let x = 1 in x`,
+			expected: &ast.LetExpr{
+				Name:  "x",
+				Value: &ast.IntExpr{Value: 1},
+				Body:  &ast.VarExpr{Name: "x"},
+			},
+		},
+		{
+			name:  "Let expression with type annotation",
+			input: `let f : Int -> Int = \x:Int. x in f 1`,
+			expected: &ast.LetExpr{
+				Name: "f",
+				Type: &ast.FuncType{
+					From: &ast.IntType{},
+					To:   &ast.IntType{},
+				},
+				Value: &ast.AbsExpr{
+					Param:     "x",
+					ParamType: &ast.IntType{},
+					Body:      &ast.VarExpr{Name: "x"},
+				},
+				Body: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "f"},
+					Arg:  &ast.IntExpr{Value: 1},
+				},
+			},
+		},
+		{
+			name:  "Fixed point",
+			input: `fix (\f:Int->Int. f) 1`,
+			expected: &ast.AppExpr{
+				Func: &ast.FixExpr{
+					Func: &ast.AbsExpr{
+						Param: "f",
+						ParamType: &ast.FuncType{
+							From: &ast.IntType{},
+							To:   &ast.IntType{},
+						},
+						Body: &ast.VarExpr{Name: "f"},
+					},
+				},
+				Arg: &ast.IntExpr{Value: 1},
+			},
+		},
+		{
+			name:  "Tuple",
+			input: `(1, true, x)`,
+			expected: &ast.TupleExpr{
+				Elems: []ast.Expr{
+					&ast.IntExpr{Value: 1},
+					&ast.BoolExpr{Value: true},
+					&ast.VarExpr{Name: "x"},
+				},
+			},
+		},
+		{
+			name:  "Tuple projection",
+			input: `\p:Int*(Bool*Int). f p.2.1`,
+			expected: &ast.AbsExpr{
+				Param: "p",
+				ParamType: &ast.ProductType{
+					Elems: []ast.Type{
+						&ast.IntType{},
+						&ast.ProductType{
+							Elems: []ast.Type{&ast.BoolType{}, &ast.IntType{}},
+						},
+					},
+				},
+				Body: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "f"},
+					Arg: &ast.ProjExpr{
+						Tuple: &ast.ProjExpr{
+							Tuple: &ast.VarExpr{Name: "p"},
+							Index: 2,
+						},
+						Index: 1,
+					},
+				},
+			},
+		},
+		{
+			name:  "Product type binds tighter than arrow",
+			input: `\f:Int*Int->Int. f`,
+			expected: &ast.AbsExpr{
+				Param: "f",
+				ParamType: &ast.FuncType{
+					From: &ast.ProductType{
+						Elems: []ast.Type{&ast.IntType{}, &ast.IntType{}},
+					},
+					To: &ast.IntType{},
+				},
+				Body: &ast.VarExpr{Name: "f"},
+			},
+		},
+		{
+			name:  "Injection",
+			input: `inl 1 as Int + Bool * Int`,
+			expected: &ast.InjExpr{
+				Left:  true,
+				Value: &ast.IntExpr{Value: 1},
+				Type: &ast.SumType{
+					Left: &ast.IntType{},
+					Right: &ast.ProductType{
+						Elems: []ast.Type{&ast.BoolType{}, &ast.IntType{}},
+					},
+				},
+			},
+		},
+		{
+			name:  "Case analysis",
+			input: `case s of inl x => x | inr y => f y`,
+			expected: &ast.CaseExpr{
+				Scrutinee: &ast.VarExpr{Name: "s"},
+				LeftVar:   "x",
+				Left:      &ast.VarExpr{Name: "x"},
+				RightVar:  "y",
+				Right: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "f"},
+					Arg:  &ast.VarExpr{Name: "y"},
+				},
+			},
+		},
+		{
+			name:  "Record and projection",
+			input: `{x = 1, y = true}.y`,
+			expected: &ast.RecordProjExpr{
+				Record: &ast.RecordExpr{
+					Fields: []ast.RecordField{
+						{Label: "x", Value: &ast.IntExpr{Value: 1}},
+						{Label: "y", Value: &ast.BoolExpr{Value: true}},
+					},
+				},
+				Label: "y",
+			},
+		},
+		{
+			name:  "Record type",
+			input: `\r:{x:Int, y:Bool}. r.x`,
+			expected: &ast.AbsExpr{
+				Param: "r",
+				ParamType: &ast.RecordType{
+					Fields: []ast.Field{
+						{Label: "x", Type: &ast.IntType{}},
+						{Label: "y", Type: &ast.BoolType{}},
+					},
+				},
+				Body: &ast.RecordProjExpr{
+					Record: &ast.VarExpr{Name: "r"},
+					Label:  "x",
+				},
+			},
+		},
+		{
+			name:  "Variant",
+			input: `<some = f 1> as <some:Int, none:Bool>`,
+			expected: &ast.VariantExpr{
+				Label: "some",
+				Value: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "f"},
+					Arg:  &ast.IntExpr{Value: 1},
+				},
+				Type: &ast.VariantType{
+					Fields: []ast.Field{
+						{Label: "some", Type: &ast.IntType{}},
+						{Label: "none", Type: &ast.BoolType{}},
+					},
+				},
+			},
+		},
+		{
+			name:  "Variant case analysis",
+			input: `case v of <some = n> => n | <none = u> => 0`,
+			expected: &ast.VariantCaseExpr{
+				Scrutinee: &ast.VarExpr{Name: "v"},
+				Branches: []ast.VariantBranch{
+					{Label: "some", Var: "n", Body: &ast.VarExpr{Name: "n"}},
+					{Label: "none", Var: "u", Body: &ast.IntExpr{Value: 0}},
+				},
+			},
+		},
+		{
+			name:  "Recursive let expression",
+			input: `letrec f : Int -> Int = \x:Int. f x in f 0`,
+			expected: &ast.LetExpr{
+				Name: "f",
+				Type: &ast.FuncType{
+					From: &ast.IntType{},
+					To:   &ast.IntType{},
+				},
+				Value: &ast.FixExpr{
+					Func: &ast.AbsExpr{
+						Param: "f",
+						ParamType: &ast.FuncType{
+							From: &ast.IntType{},
+							To:   &ast.IntType{},
+						},
+						Body: &ast.AbsExpr{
+							Param:     "x",
+							ParamType: &ast.IntType{},
+							Body: &ast.AppExpr{
+								Func: &ast.VarExpr{Name: "f"},
+								Arg:  &ast.VarExpr{Name: "x"},
+							},
+						},
+					},
+				},
+				Body: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "f"},
+					Arg:  &ast.IntExpr{Value: 0},
+				},
+			},
+		},
+		{
+			name:  "Type abstraction",
+			input: `/\A. \x:A. x`,
+			expected: &ast.TyAbsExpr{
+				TypeVar: "A",
+				Body: &ast.AbsExpr{
+					Param:     "x",
+					ParamType: &ast.TypeVar{Name: "A"},
+					Body:      &ast.VarExpr{Name: "x"},
+				},
+			},
+		},
+		{
+			name:  "Fold and unfold",
+			input: `unfold [mu X. Int -> X] (fold [T] f) 1`,
+			expected: &ast.AppExpr{
+				Func: &ast.UnfoldExpr{
+					Type: &ast.RecType{
+						Var: "X",
+						Body: &ast.FuncType{
+							From: &ast.IntType{},
+							To:   &ast.TypeVar{Name: "X"},
+						},
+					},
+					Value: &ast.FoldExpr{
+						Type:  &ast.TypeVar{Name: "T"},
+						Value: &ast.VarExpr{Name: "f"},
+					},
+				},
+				Arg: &ast.IntExpr{Value: 1},
+			},
+		},
+		{
+			name:  "Type application followed by application",
+			input: `id [Int] 3`,
+			expected: &ast.AppExpr{
+				Func: &ast.TyAppExpr{
+					Func:    &ast.VarExpr{Name: "id"},
+					TypeArg: &ast.IntType{},
+				},
+				Arg: &ast.IntExpr{Value: 3},
+			},
+		},
+		{
+			name:  "Universal type annotation",
+			input: `\f:forall A. A -> A. f [Bool -> Bool] f`,
+			expected: &ast.AbsExpr{
+				Param: "f",
+				ParamType: &ast.ForallType{
+					Var: "A",
+					Body: &ast.FuncType{
+						From: &ast.TypeVar{Name: "A"},
+						To:   &ast.TypeVar{Name: "A"},
+					},
+				},
+				Body: &ast.AppExpr{
+					Func: &ast.TyAppExpr{
+						Func: &ast.VarExpr{Name: "f"},
+						TypeArg: &ast.FuncType{
+							From: &ast.BoolType{},
+							To:   &ast.BoolType{},
+						},
+					},
+					Arg: &ast.VarExpr{Name: "f"},
+				},
+			},
+		},
+		{
+			name:  "Unannotated abstraction",
+			input: `\x. add x 1`,
+			expected: &ast.AbsExpr{
+				Param: "x",
+				Body: &ast.AppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "add"},
+						Arg:  &ast.VarExpr{Name: "x"},
+					},
+					Arg: &ast.IntExpr{Value: 1},
+				},
+			},
+		},
+		{
+			name:  "Unannotated recursive let expression",
+			input: `letrec f = \x. f x in f`,
+			expected: &ast.LetExpr{
+				Name: "f",
+				Value: &ast.FixExpr{
+					Func: &ast.AbsExpr{
+						Param: "f",
+						Body: &ast.AbsExpr{
+							Param: "x",
+							Body: &ast.AppExpr{
+								Func: &ast.VarExpr{Name: "f"},
+								Arg:  &ast.VarExpr{Name: "x"},
+							},
+						},
+					},
+				},
+				Body: &ast.VarExpr{Name: "f"},
+			},
+		},
+		{
+			name:  "Operator precedence",
+			input: `1 + 2 * 3 == 7`,
+			expected: &ast.AppExpr{
+				Func: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "eq", Op: "=="},
+					Arg: &ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "add", Op: "+"},
+							Arg:  &ast.IntExpr{Value: 1},
+						},
+						Arg: &ast.AppExpr{
+							Func: &ast.AppExpr{
+								Func: &ast.VarExpr{Name: "mul", Op: "*"},
+								Arg:  &ast.IntExpr{Value: 2},
+							},
+							Arg: &ast.IntExpr{Value: 3},
+						},
+					},
+				},
+				Arg: &ast.IntExpr{Value: 7},
+			},
+		},
+		{
+			name:  "Left-associative subtraction",
+			input: `a - b-1`,
+			expected: &ast.AppExpr{
+				Func: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "sub", Op: "-"},
+					Arg: &ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "sub", Op: "-"},
+							Arg:  &ast.VarExpr{Name: "a"},
+						},
+						Arg: &ast.VarExpr{Name: "b"},
+					},
+				},
+				Arg: &ast.IntExpr{Value: 1},
+			},
+		},
+		{
+			name:  "Right-associative conjunction",
+			input: `a && b && c || d`,
+			expected: &ast.AppExpr{
+				Func: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "or", Op: "||"},
+					Arg: &ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "and", Op: "&&"},
+							Arg:  &ast.VarExpr{Name: "a"},
+						},
+						Arg: &ast.AppExpr{
+							Func: &ast.AppExpr{
+								Func: &ast.VarExpr{Name: "and", Op: "&&"},
+								Arg:  &ast.VarExpr{Name: "b"},
+							},
+							Arg: &ast.VarExpr{Name: "c"},
+						},
+					},
+				},
+				Arg: &ast.VarExpr{Name: "d"},
+			},
+		},
+		{
+			name:  "Prefix operators",
+			input: `!f x && -y < 1`,
+			expected: &ast.AppExpr{
+				Func: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "and", Op: "&&"},
+					Arg: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "not", Op: "!"},
+						Arg: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "f"},
+							Arg:  &ast.VarExpr{Name: "x"},
+						},
+					},
+				},
+				Arg: &ast.AppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "lt", Op: "<"},
+						Arg: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "neg", Op: "-"},
+							Arg:  &ast.VarExpr{Name: "y"},
+						},
+					},
+					Arg: &ast.IntExpr{Value: 1},
+				},
+			},
+		},
+		{
+			name:  "Negative literal argument",
+			input: `f -1 - 2`,
+			expected: &ast.AppExpr{
+				Func: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "sub", Op: "-"},
+					Arg: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "f"},
+						Arg:  &ast.IntExpr{Value: -1},
+					},
+				},
+				Arg: &ast.IntExpr{Value: 2},
+			},
+		},
+		{
+			name:  "Unit, string, character and float literals",
+			input: `f () "a\tb\u{3bb}" '\n' -1.25`,
+			expected: &ast.AppExpr{
+				Func: &ast.AppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "f"},
+							Arg:  &ast.UnitExpr{},
+						},
+						Arg: &ast.StringExpr{Value: "a\tbλ"},
+					},
+					Arg: &ast.CharExpr{Value: '\n'},
+				},
+				Arg: &ast.FloatExpr{Value: -1.25},
+			},
+		},
+		{
+			name:  "Base types",
+			input: `\x:Unit -> String * Char * Float. x`,
+			expected: &ast.AbsExpr{
+				Param: "x",
+				ParamType: &ast.FuncType{
+					From: &ast.UnitType{},
+					To:   &ast.ProductType{Elems: []ast.Type{&ast.StringType{}, &ast.CharType{}, &ast.FloatType{}}},
+				},
+				Body: &ast.VarExpr{Name: "x"},
+			},
+		},
+		{
+			name:  "Integer literals out of the range of Int",
+			input: `(9223372036854775807, 9223372036854775808, -9223372036854775809)`,
+			expected: &ast.TupleExpr{Elems: []ast.Expr{
+				&ast.IntExpr{Value: 9223372036854775807},
+				&ast.IntExpr{Big: new(big.Int).Lsh(big.NewInt(1), 63)},
+				&ast.IntExpr{Big: new(big.Int).Sub(new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 63)), big.NewInt(1))},
+			}},
+		},
+		{
+			name:  "Operator sections",
+			input: `((+), (x *), (/ 2), (-), (- x))`,
+			expected: &ast.TupleExpr{Elems: []ast.Expr{
+				&ast.VarExpr{Name: "add", Op: "+"},
+				&ast.AppExpr{
+					Func: &ast.VarExpr{Name: "mul", Op: "*"},
+					Arg:  &ast.VarExpr{Name: "x"},
+				},
+				&ast.AbsExpr{
+					Param: ast.SectionParam,
+					Body: &ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "div", Op: "/"},
+							Arg:  &ast.VarExpr{Name: ast.SectionParam},
+						},
+						Arg: &ast.IntExpr{Value: 2},
+					},
+				},
+				&ast.VarExpr{Name: "sub", Op: "-"},
+				&ast.AppExpr{
+					Func: &ast.VarExpr{Name: "neg", Op: "-"},
+					Arg:  &ast.VarExpr{Name: "x"},
+				},
+			}},
+		},
+		{
+			name:  "Lists",
+			input: `(nil[List Int], [], [1, 2 + 3], f [1] [x], x :: y :: xs)`,
+			expected: &ast.TupleExpr{Elems: []ast.Expr{
+				&ast.NilExpr{ElemType: &ast.ListType{Elem: &ast.IntType{}}},
+				&ast.ListExpr{},
+				&ast.ListExpr{Elems: []ast.Expr{
+					&ast.IntExpr{Value: 1},
+					&ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "add", Op: "+"},
+							Arg:  &ast.IntExpr{Value: 2},
+						},
+						Arg: &ast.IntExpr{Value: 3},
+					},
+				}},
+				&ast.TyAppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "f"},
+						Arg:  &ast.ListExpr{Elems: []ast.Expr{&ast.IntExpr{Value: 1}}},
+					},
+					TypeArg: &ast.TypeVar{Name: "x"},
+				},
+				&ast.AppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "cons", Op: "::"},
+						Arg:  &ast.VarExpr{Name: "x"},
+					},
+					Arg: &ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "cons", Op: "::"},
+							Arg:  &ast.VarExpr{Name: "y"},
+						},
+						Arg: &ast.VarExpr{Name: "xs"},
+					},
+				},
+			}},
+		},
+		{
+			name:  "Cons binds looser than addition",
+			input: `x + 1 :: xs`,
+			expected: &ast.AppExpr{
+				Func: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "cons", Op: "::"},
+					Arg: &ast.AppExpr{
+						Func: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "add", Op: "+"},
+							Arg:  &ast.VarExpr{Name: "x"},
+						},
+						Arg: &ast.IntExpr{Value: 1},
+					},
+				},
+				Arg: &ast.VarExpr{Name: "xs"},
+			},
+		},
+		{
+			name:  "List case analysis",
+			input: `case xs of [] => 0 | h :: t => h`,
+			expected: &ast.ListCaseExpr{
+				Scrutinee: &ast.VarExpr{Name: "xs"},
+				Nil:       &ast.IntExpr{Value: 0},
+				HeadVar:   "h",
+				TailVar:   "t",
+				Cons:      &ast.VarExpr{Name: "h"},
+			},
+		},
+		{
+			name:  "List types",
+			input: `\x:List List Int * List (Int -> Bool). x`,
+			expected: &ast.AbsExpr{
+				Param: "x",
+				ParamType: &ast.ProductType{Elems: []ast.Type{
+					&ast.ListType{Elem: &ast.ListType{Elem: &ast.IntType{}}},
+					&ast.ListType{Elem: &ast.FuncType{From: &ast.IntType{}, To: &ast.BoolType{}}},
+				}},
+				Body: &ast.VarExpr{Name: "x"},
+			},
+		},
+		{
+			name:  "Pattern match",
+			input: `match s with | Circle r -> r | Rect (Some w) _ -> w | Dot -1 "a" () -> 0 | x -> 1`,
+			expected: &ast.MatchExpr{
+				Scrutinee: &ast.VarExpr{Name: "s"},
+				Arms: []ast.MatchArm{
+					{
+						Pattern: &ast.ConstructorPattern{Name: "Circle", Args: []ast.Pattern{&ast.VarPattern{Name: "r"}}},
+						Body:    &ast.VarExpr{Name: "r"},
+					},
+					{
+						Pattern: &ast.ConstructorPattern{Name: "Rect", Args: []ast.Pattern{
+							&ast.ConstructorPattern{Name: "Some", Args: []ast.Pattern{&ast.VarPattern{Name: "w"}}},
+							&ast.WildcardPattern{},
+						}},
+						Body: &ast.VarExpr{Name: "w"},
+					},
+					{
+						Pattern: &ast.ConstructorPattern{Name: "Dot", Args: []ast.Pattern{
+							&ast.LiteralPattern{Value: &ast.IntExpr{Value: -1}},
+							&ast.LiteralPattern{Value: &ast.StringExpr{Value: "a"}},
+							&ast.LiteralPattern{Value: &ast.UnitExpr{}},
+						}},
+						Body: &ast.IntExpr{Value: 0},
+					},
+					{
+						Pattern: &ast.VarPattern{Name: "x"},
+						Body:    &ast.IntExpr{Value: 1},
+					},
+				},
+			},
+		},
+		{
+			name:  "Comparison in variant",
+			input: `<a = (x > 1)> as <a: Bool>`,
+			expected: &ast.VariantExpr{
+				Label: "a",
+				Value: &ast.AppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "gt", Op: ">"},
+						Arg:  &ast.VarExpr{Name: "x"},
+					},
+					Arg: &ast.IntExpr{Value: 1},
+				},
+				Type: &ast.VariantType{Fields: []ast.Field{{Label: "a", Type: &ast.BoolType{}}}},
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			result, err := parser.Parse(tt.input)
@@ -168,6 +779,380 @@ func TestParser(t *testing.T) {
 	}
 }
 
+func TestParseProgram(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected *ast.Program
+	}{
+		{
+			name:  "Main expression only",
+			input: `42`,
+			expected: &ast.Program{
+				Main: &ast.IntExpr{Value: 42},
+			},
+		},
+		{
+			name: "Definitions followed by main expression",
+			input: `let one = 1
+let inc : Int -> Int = add one
+inc 41`,
+			expected: &ast.Program{
+				Decls: []*ast.Decl{
+					{
+						Name:  "one",
+						Value: &ast.IntExpr{Value: 1},
+					},
+					{
+						Name: "inc",
+						Type: &ast.FuncType{
+							From: &ast.IntType{},
+							To:   &ast.IntType{},
+						},
+						Value: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "add"},
+							Arg:  &ast.VarExpr{Name: "one"},
+						},
+					},
+				},
+				Main: &ast.AppExpr{
+					Func: &ast.VarExpr{Name: "inc"},
+					Arg:  &ast.IntExpr{Value: 41},
+				},
+			},
+		},
+		{
+			name: "Main expression across lines",
+			input: `(\x:Int. x)
+42`,
+			expected: &ast.Program{
+				Main: &ast.AppExpr{
+					Func: &ast.AbsExpr{Param: "x", ParamType: &ast.IntType{}, Body: &ast.VarExpr{Name: "x"}},
+					Arg:  &ast.IntExpr{Value: 42},
+				},
+			},
+		},
+		{
+			name: "Main expression across lines after a definition",
+			input: `let one = 1
+add
+one
+1`,
+			expected: &ast.Program{
+				Decls: []*ast.Decl{
+					{
+						Name:  "one",
+						Value: &ast.IntExpr{Value: 1},
+					},
+				},
+				Main: &ast.AppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "add"},
+						Arg:  &ast.VarExpr{Name: "one"},
+					},
+					Arg: &ast.IntExpr{Value: 1},
+				},
+			},
+		},
+		{
+			name: "Definition continued on an indented line",
+			input: `let two = add 1
+  1
+two`,
+			expected: &ast.Program{
+				Decls: []*ast.Decl{
+					{
+						Name: "two",
+						Value: &ast.AppExpr{
+							Func: &ast.AppExpr{
+								Func: &ast.VarExpr{Name: "add"},
+								Arg:  &ast.IntExpr{Value: 1},
+							},
+							Arg: &ast.IntExpr{Value: 1},
+						},
+					},
+				},
+				Main: &ast.VarExpr{Name: "two"},
+			},
+		},
+		{
+			name: "Definition followed by let expression",
+			input: `let x = 1
+let y = 2 in x`,
+			expected: &ast.Program{
+				Decls: []*ast.Decl{
+					{
+						Name:  "x",
+						Value: &ast.IntExpr{Value: 1},
+					},
+				},
+				Main: &ast.LetExpr{
+					Name:  "y",
+					Value: &ast.IntExpr{Value: 2},
+					Body:  &ast.VarExpr{Name: "x"},
+				},
+			},
+		},
+		{
+			name: "Data type declarations",
+			input: `type Shape = Circle Int | Rect Int Int
+type Tree =
+  | Leaf
+  | Node Tree (List Int) Tree
+let unit = Circle 1
+unit`,
+			expected: &ast.Program{
+				Types: []*ast.TypeDecl{
+					{
+						Name: "Shape",
+						Constructors: []*ast.Constructor{
+							{Name: "Circle", Params: []ast.Type{&ast.IntType{}}},
+							{Name: "Rect", Params: []ast.Type{&ast.IntType{}, &ast.IntType{}}},
+						},
+					},
+					{
+						Name: "Tree",
+						Constructors: []*ast.Constructor{
+							{Name: "Leaf"},
+							{Name: "Node", Params: []ast.Type{
+								&ast.TypeVar{Name: "Tree"},
+								&ast.ListType{Elem: &ast.IntType{}},
+								&ast.TypeVar{Name: "Tree"},
+							}},
+						},
+					},
+				},
+				Decls: []*ast.Decl{
+					{
+						Name: "unit",
+						Value: &ast.AppExpr{
+							Func: &ast.VarExpr{Name: "Circle"},
+							Arg:  &ast.IntExpr{Value: 1},
+						},
+					},
+				},
+				Main: &ast.VarExpr{Name: "unit"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.ParseProgram(tt.input)
+			if err != nil {
+				t.Fatalf("ParseProgram() error = %v", err)
+			}
+			if len(result.Types) != len(tt.expected.Types) {
+				t.Fatalf("ParseProgram() got %d type decls, want %d", len(result.Types), len(tt.expected.Types))
+			}
+			for i, decl := range result.Types {
+				if want := tt.expected.Types[i]; !equalTypeDecl(decl, want) {
+					t.Errorf("ParseProgram() type decl %d = %v, want %v", i, decl, want)
+				}
+			}
+			if len(result.Decls) != len(tt.expected.Decls) {
+				t.Fatalf("ParseProgram() got %d decls, want %d", len(result.Decls), len(tt.expected.Decls))
+			}
+			for i, decl := range result.Decls {
+				want := tt.expected.Decls[i]
+				if decl.Name != want.Name || !equalType(decl.Type, want.Type) || !equalAST(decl.Value, want.Value) {
+					t.Errorf("ParseProgram() decl %d = %v, want %v", i, decl, want)
+				}
+			}
+			if !equalAST(result.Main, tt.expected.Main) {
+				t.Errorf("ParseProgram() main = %v, want %v", result.Main, tt.expected.Main)
+			}
+		})
+	}
+}
+
+func TestParseProgramErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name:          "Missing main expression",
+			input:         `let x = 1`,
+			expectedError: "1:10: unexpected token: EOF",
+		},
+		{
+			name:          "Missing equal sign",
+			input:         `let x 1`,
+			expectedError: "1:7: expected '=' after bound name: Int",
+		},
+		{
+			name:          "Chained comparison",
+			input:         `1 < x < 3`,
+			expectedError: "1:7: operator < cannot follow < without parentheses",
+		},
+		{
+			name:          "Left section of looser operator",
+			input:         `(1 + 2 *)`,
+			expectedError: "1:9: unexpected token: RParen",
+		},
+		{
+			name:          "Missing element type of nil",
+			input:         `nil`,
+			expectedError: "1:4: expected '[' after 'nil': EOF",
+		},
+		{
+			name:          "Missing cons pattern",
+			input:         `case xs of [] => 0 | h => h`,
+			expectedError: "1:24: expected '::' in cons pattern: FatArrow",
+		},
+		{
+			name:          "Duplicate record label",
+			input:         `{x = 1, x = 2}`,
+			expectedError: "1:9: duplicate label: x",
+		},
+		{
+			name:          "Duplicate case branch",
+			input:         `case v of <a = x> => x | <a = y> => y`,
+			expectedError: "1:27: duplicate label: a",
+		},
+		{
+			name:          "Empty variant type",
+			input:         `\x:<>. x`,
+			expectedError: "1:5: variant type must have at least one label",
+		},
+		{
+			name:          "Trailing tokens",
+			input:         "let x = 1\nx )",
+			expectedError: "2:3: unexpected token after main expression: RParen",
+		},
+		{
+			name:          "Unclosed type argument",
+			input:         `id [Int 3`,
+			expectedError: "1:9: expected ']' after type argument: Int",
+		},
+		{
+			name:          "Type abstraction without type variable",
+			input:         `/\. x`,
+			expectedError: "1:3: expected type variable after '/\\': Dot",
+		},
+		{
+			name:          "Errors in separate groupings",
+			input:         `(1 ,) (\. x)`,
+			expectedError: "1:5: unexpected token: RParen\n1:9: expected identifier after '\\': Dot",
+		},
+		{
+			name:          "Errors in condition and branches",
+			input:         `if ) then \x then 1 else 2`,
+			expectedError: "1:4: unexpected token: RParen\n1:14: expected '.' after parameter: Then",
+		},
+		{
+			name:          "Errors in separate definitions",
+			input:         "let x = )\nlet y Int = 1\nlet z = 2\nz",
+			expectedError: "1:9: unexpected token: RParen\n2:7: expected '=' after bound name: IntType",
+		},
+		{
+			name:          "Unclosed grouping",
+			input:         "let x = (1\nx",
+			expectedError: "2:1: expected ')': Ident",
+		},
+		{
+			name:          "Lowercase constructor",
+			input:         "type T = A | b Int\nA",
+			expectedError: "1:14: constructor name must start with an uppercase letter: b",
+		},
+		{
+			name:          "Missing arrow in match arm",
+			input:         "match x with A => 1",
+			expectedError: "1:16: expected '->' after pattern: FatArrow",
+		},
+		{
+			name:          "Float pattern",
+			input:         "match x with 1.5 -> 1",
+			expectedError: "1:14: unexpected token in pattern: Float",
+		},
+		{
+			name:          "Fold without type",
+			input:         "fold x",
+			expectedError: "1:6: expected '[' after 'fold': Ident",
+		},
+		{
+			name:          "Recursive type without type variable",
+			input:         `\x:mu. x`,
+			expectedError: "1:6: expected type variable after 'mu': Dot",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.ParseProgram(tt.input)
+			if err == nil {
+				t.Fatalf("ParseProgram() expected error")
+			}
+			if err.Error() != tt.expectedError {
+				t.Errorf("ParseProgram() error = %q, want %q", err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestParseProgramRecovery(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected *ast.Program
+	}{
+		{
+			name:  "Erroneous definition is skipped",
+			input: "let x = )\nlet y = 1\ny",
+			expected: &ast.Program{
+				Decls: []*ast.Decl{
+					{Name: "y", Value: &ast.IntExpr{Value: 1}},
+				},
+				Main: &ast.VarExpr{Name: "y"},
+			},
+		},
+		{
+			name:  "Erroneous grouping is replaced",
+			input: `f (1 2 ,) 3`,
+			expected: &ast.Program{
+				Main: &ast.AppExpr{
+					Func: &ast.AppExpr{
+						Func: &ast.VarExpr{Name: "f"},
+						Arg:  &ast.ErrorExpr{},
+					},
+					Arg: &ast.IntExpr{Value: 3},
+				},
+			},
+		},
+		{
+			name:  "Erroneous condition is replaced",
+			input: `if ) then 1 else 2`,
+			expected: &ast.Program{
+				Main: &ast.IfExpr{
+					Cond: &ast.ErrorExpr{},
+					Then: &ast.IntExpr{Value: 1},
+					Else: &ast.IntExpr{Value: 2},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.ParseProgram(tt.input)
+			if err == nil {
+				t.Fatalf("ParseProgram() expected error")
+			}
+			if result == nil {
+				t.Fatalf("ParseProgram() returned no program")
+			}
+			if len(result.Decls) != len(tt.expected.Decls) {
+				t.Fatalf("ParseProgram() got %d decls, want %d", len(result.Decls), len(tt.expected.Decls))
+			}
+			for i, decl := range result.Decls {
+				want := tt.expected.Decls[i]
+				if decl.Name != want.Name || !equalType(decl.Type, want.Type) || !equalAST(decl.Value, want.Value) {
+					t.Errorf("ParseProgram() decl %d = %v, want %v", i, decl, want)
+				}
+			}
+			if !equalAST(result.Main, tt.expected.Main) {
+				t.Errorf("ParseProgram() main = %v, want %v", result.Main, tt.expected.Main)
+			}
+		})
+	}
+}
+
 func equalAST(a, b ast.Expr) bool {
 	if a == nil && b == nil {
 		return true
@@ -179,7 +1164,7 @@ func equalAST(a, b ast.Expr) bool {
 	switch x := a.(type) {
 	case *ast.VarExpr:
 		y, ok := b.(*ast.VarExpr)
-		return ok && x.Name == y.Name
+		return ok && x.Name == y.Name && x.Op == y.Op
 
 	case *ast.AbsExpr:
 		y, ok := b.(*ast.AbsExpr)
@@ -195,17 +1180,202 @@ func equalAST(a, b ast.Expr) bool {
 
 	case *ast.IntExpr:
 		y, ok := b.(*ast.IntExpr)
-		return ok && x.Value == y.Value
+		return ok && x.Value == y.Value && (x.Big == nil) == (y.Big == nil) && (x.Big == nil || x.Big.Cmp(y.Big) == 0)
 
 	case *ast.IfExpr:
 		y, ok := b.(*ast.IfExpr)
 		return ok && equalAST(x.Cond, y.Cond) && equalAST(x.Then, y.Then) && equalAST(x.Else, y.Else)
 
+	case *ast.LetExpr:
+		y, ok := b.(*ast.LetExpr)
+		return ok && x.Name == y.Name && equalType(x.Type, y.Type) && equalAST(x.Value, y.Value) && equalAST(x.Body, y.Body)
+
+	case *ast.FixExpr:
+		y, ok := b.(*ast.FixExpr)
+		return ok && equalAST(x.Func, y.Func)
+
+	case *ast.TupleExpr:
+		y, ok := b.(*ast.TupleExpr)
+		if !ok || len(x.Elems) != len(y.Elems) {
+			return false
+		}
+		for i := range x.Elems {
+			if !equalAST(x.Elems[i], y.Elems[i]) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.ProjExpr:
+		y, ok := b.(*ast.ProjExpr)
+		return ok && x.Index == y.Index && equalAST(x.Tuple, y.Tuple)
+
+	case *ast.InjExpr:
+		y, ok := b.(*ast.InjExpr)
+		return ok && x.Left == y.Left && equalAST(x.Value, y.Value) && equalType(x.Type, y.Type)
+
+	case *ast.RecordExpr:
+		y, ok := b.(*ast.RecordExpr)
+		if !ok || len(x.Fields) != len(y.Fields) {
+			return false
+		}
+		for i := range x.Fields {
+			if x.Fields[i].Label != y.Fields[i].Label || !equalAST(x.Fields[i].Value, y.Fields[i].Value) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.RecordProjExpr:
+		y, ok := b.(*ast.RecordProjExpr)
+		return ok && x.Label == y.Label && equalAST(x.Record, y.Record)
+
+	case *ast.VariantExpr:
+		y, ok := b.(*ast.VariantExpr)
+		return ok && x.Label == y.Label && equalAST(x.Value, y.Value) && equalType(x.Type, y.Type)
+
+	case *ast.VariantCaseExpr:
+		y, ok := b.(*ast.VariantCaseExpr)
+		if !ok || !equalAST(x.Scrutinee, y.Scrutinee) || len(x.Branches) != len(y.Branches) {
+			return false
+		}
+		for i := range x.Branches {
+			bx, by := x.Branches[i], y.Branches[i]
+			if bx.Label != by.Label || bx.Var != by.Var || !equalAST(bx.Body, by.Body) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.CaseExpr:
+		y, ok := b.(*ast.CaseExpr)
+		return ok && equalAST(x.Scrutinee, y.Scrutinee) &&
+			x.LeftVar == y.LeftVar && equalAST(x.Left, y.Left) &&
+			x.RightVar == y.RightVar && equalAST(x.Right, y.Right)
+
+	case *ast.TyAbsExpr:
+		y, ok := b.(*ast.TyAbsExpr)
+		return ok && x.TypeVar == y.TypeVar && equalAST(x.Body, y.Body)
+
+	case *ast.TyAppExpr:
+		y, ok := b.(*ast.TyAppExpr)
+		return ok && equalAST(x.Func, y.Func) && equalType(x.TypeArg, y.TypeArg)
+
+	case *ast.FoldExpr:
+		y, ok := b.(*ast.FoldExpr)
+		return ok && equalType(x.Type, y.Type) && equalAST(x.Value, y.Value)
+
+	case *ast.UnfoldExpr:
+		y, ok := b.(*ast.UnfoldExpr)
+		return ok && equalType(x.Type, y.Type) && equalAST(x.Value, y.Value)
+
+	case *ast.UnitExpr:
+		_, ok := b.(*ast.UnitExpr)
+		return ok
+
+	case *ast.StringExpr:
+		y, ok := b.(*ast.StringExpr)
+		return ok && x.Value == y.Value
+
+	case *ast.CharExpr:
+		y, ok := b.(*ast.CharExpr)
+		return ok && x.Value == y.Value
+
+	case *ast.FloatExpr:
+		y, ok := b.(*ast.FloatExpr)
+		return ok && x.Value == y.Value
+
+	case *ast.NilExpr:
+		y, ok := b.(*ast.NilExpr)
+		return ok && equalType(x.ElemType, y.ElemType)
+
+	case *ast.ListExpr:
+		y, ok := b.(*ast.ListExpr)
+		if !ok || len(x.Elems) != len(y.Elems) {
+			return false
+		}
+		for i := range x.Elems {
+			if !equalAST(x.Elems[i], y.Elems[i]) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.ListCaseExpr:
+		y, ok := b.(*ast.ListCaseExpr)
+		return ok && equalAST(x.Scrutinee, y.Scrutinee) && equalAST(x.Nil, y.Nil) &&
+			x.HeadVar == y.HeadVar && x.TailVar == y.TailVar && equalAST(x.Cons, y.Cons)
+
+	case *ast.MatchExpr:
+		y, ok := b.(*ast.MatchExpr)
+		if !ok || !equalAST(x.Scrutinee, y.Scrutinee) || len(x.Arms) != len(y.Arms) {
+			return false
+		}
+		for i := range x.Arms {
+			if !equalPattern(x.Arms[i].Pattern, y.Arms[i].Pattern) || !equalAST(x.Arms[i].Body, y.Arms[i].Body) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.ErrorExpr:
+		_, ok := b.(*ast.ErrorExpr)
+		return ok
+
+	default:
+		return false
+	}
+}
+
+func equalPattern(a, b ast.Pattern) bool {
+	switch x := a.(type) {
+	case *ast.WildcardPattern:
+		_, ok := b.(*ast.WildcardPattern)
+		return ok
+
+	case *ast.VarPattern:
+		y, ok := b.(*ast.VarPattern)
+		return ok && x.Name == y.Name
+
+	case *ast.LiteralPattern:
+		y, ok := b.(*ast.LiteralPattern)
+		return ok && equalAST(x.Value, y.Value)
+
+	case *ast.ConstructorPattern:
+		y, ok := b.(*ast.ConstructorPattern)
+		if !ok || x.Name != y.Name || len(x.Args) != len(y.Args) {
+			return false
+		}
+		for i := range x.Args {
+			if !equalPattern(x.Args[i], y.Args[i]) {
+				return false
+			}
+		}
+		return true
+
 	default:
 		return false
 	}
 }
 
+func equalTypeDecl(a, b *ast.TypeDecl) bool {
+	if a.Name != b.Name || len(a.Constructors) != len(b.Constructors) {
+		return false
+	}
+	for i := range a.Constructors {
+		x, y := a.Constructors[i], b.Constructors[i]
+		if x.Name != y.Name || len(x.Params) != len(y.Params) {
+			return false
+		}
+		for j := range x.Params {
+			if !equalType(x.Params[j], y.Params[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func equalType(a, b ast.Type) bool {
 	if a == nil && b == nil {
 		return true
@@ -219,15 +1389,144 @@ func equalType(a, b ast.Type) bool {
 		_, ok := b.(*ast.BoolType)
 		return ok
 
-	case *ast.IntType:
-		_, ok := b.(*ast.IntType)
-		return ok
+	case *ast.IntType, *ast.UnitType, *ast.StringType, *ast.CharType, *ast.FloatType:
+		return x.Equal(b)
 
 	case *ast.FuncType:
 		y, ok := b.(*ast.FuncType)
 		return ok && equalType(x.From, y.From) && equalType(x.To, y.To)
 
+	case *ast.ProductType:
+		y, ok := b.(*ast.ProductType)
+		if !ok || len(x.Elems) != len(y.Elems) {
+			return false
+		}
+		for i := range x.Elems {
+			if !equalType(x.Elems[i], y.Elems[i]) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.SumType:
+		y, ok := b.(*ast.SumType)
+		return ok && equalType(x.Left, y.Left) && equalType(x.Right, y.Right)
+
+	case *ast.ListType:
+		y, ok := b.(*ast.ListType)
+		return ok && equalType(x.Elem, y.Elem)
+
+	case *ast.RecordType:
+		y, ok := b.(*ast.RecordType)
+		return ok && equalFields(x.Fields, y.Fields)
+
+	case *ast.VariantType:
+		y, ok := b.(*ast.VariantType)
+		return ok && equalFields(x.Fields, y.Fields)
+
+	case *ast.TypeVar:
+		y, ok := b.(*ast.TypeVar)
+		return ok && x.Name == y.Name
+
+	case *ast.ForallType:
+		y, ok := b.(*ast.ForallType)
+		return ok && x.Var == y.Var && equalType(x.Body, y.Body)
+
+	case *ast.RecType:
+		y, ok := b.(*ast.RecType)
+		return ok && x.Var == y.Var && equalType(x.Body, y.Body)
+
 	default:
 		return false
 	}
 }
+
+func equalFields(a, b []ast.Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Label != b[i].Label || !equalType(a[i].Type, b[i].Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseSpans(t *testing.T) {
+	// span returns the span between two positions, each given by its line, column and byte offset.
+	span := func(startLine, startColumn, startOffset, endLine, endColumn, endOffset int) token.Span {
+		return token.Span{
+			Start: token.Position{Offset: startOffset, Line: startLine, Column: startColumn},
+			End:   token.Position{Offset: endOffset, Line: endLine, Column: endColumn},
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		input    string
+		node     func(ast.Expr) ast.Expr
+		expected token.Span
+	}{
+		{
+			name:     "Application",
+			input:    `f (g 1) true`,
+			node:     func(e ast.Expr) ast.Expr { return e },
+			expected: span(1, 1, 0, 1, 13, 12),
+		},
+		{
+			name:     "Abstraction in parentheses",
+			input:    `(\x:Int. x) true`,
+			node:     func(e ast.Expr) ast.Expr { return e.(*ast.AppExpr).Func },
+			expected: span(1, 2, 1, 1, 11, 10),
+		},
+		{
+			name:     "Argument",
+			input:    `(\x:Int. x) true`,
+			node:     func(e ast.Expr) ast.Expr { return e.(*ast.AppExpr).Arg },
+			expected: span(1, 13, 12, 1, 17, 16),
+		},
+		{
+			name:     "Multi-line conditional",
+			input:    "if true\nthen 1\nelse 20",
+			node:     func(e ast.Expr) ast.Expr { return e },
+			expected: span(1, 1, 0, 3, 8, 22),
+		},
+		{
+			name:     "Tuple",
+			input:    `(1, (true, 2))`,
+			node:     func(e ast.Expr) ast.Expr { return e.(*ast.TupleExpr).Elems[1] },
+			expected: span(1, 5, 4, 1, 14, 13),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := tt.node(result).Span(); got != tt.expected {
+				t.Errorf("Span() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseProgramReader(t *testing.T) {
+	prog, err := parser.ParseProgramReader(strings.NewReader("let x = 1\nx"), "main.stlc")
+	if err != nil {
+		t.Fatalf("ParseProgramReader() error = %v", err)
+	}
+	expected := token.Position{Filename: "main.stlc", Offset: 10, Line: 2, Column: 1}
+	if got := prog.Main.Position(); got != expected {
+		t.Errorf("Position() = %+v, want %+v", got, expected)
+	}
+
+	_, err = parser.ParseProgramReader(strings.NewReader("let x = )\nx"), "main.stlc")
+	var parseErr *parser.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *parser.ParseError, got %T", err)
+	}
+	if parseErr.Pos.Filename != "main.stlc" || parseErr.Pos.Offset != 8 {
+		t.Errorf("ParseError.Pos = %+v, want offset 8 in main.stlc", parseErr.Pos)
+	}
+}
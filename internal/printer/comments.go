@@ -0,0 +1,77 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/shota3506/gostlc/internal/lexer"
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+// scanComments returns the comments of src in source order.
+func scanComments(src string) ([]token.Token, error) {
+	var comments []token.Token
+	l := lexer.New(src)
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, tok.Trivia...)
+		if tok.Kind == token.TokenKindEOF {
+			return comments, nil
+		}
+	}
+}
+
+func (p *printer) hasCommentsBefore(pos token.Position) bool {
+	return len(p.comments) > 0 && p.comments[0].Pos.Before(pos)
+}
+
+// commentsBefore returns the document of the comments before pos and removes them from the pending comments.
+// A comment is followed by a line break where the source has one, and always after a line comment.
+func (p *printer) commentsBefore(pos token.Position) doc {
+	var docs concat
+	for p.hasCommentsBefore(pos) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+
+		next := pos
+		if p.hasCommentsBefore(pos) {
+			next = p.comments[0].Pos
+		}
+		docs = append(docs, comment(c))
+		switch end := c.End(); {
+		case next.Line > end.Line+1:
+			docs = append(docs, hardline{}, hardline{})
+		case next.Line > end.Line, strings.HasPrefix(c.Value, "--"):
+			docs = append(docs, hardline{})
+		default:
+			docs = append(docs, text(" "))
+		}
+	}
+	return docs
+}
+
+// trailingComments returns the document of the comments starting on the line of end after it,
+// and the end of the last one, or end if there are none.
+func (p *printer) trailingComments(end token.Position) (doc, token.Position) {
+	var docs concat
+	line := end.Line
+	for len(p.comments) > 0 && p.comments[0].Pos.Line == line && !p.comments[0].Pos.Before(end) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		docs = append(docs, text(" "), comment(c))
+		end = c.End()
+	}
+	return docs, end
+}
+
+// comment returns the document of a comment kept verbatim.
+func comment(c token.Token) doc {
+	lines := strings.Split(c.Value, "\n")
+	docs := make([]doc, len(lines))
+	for i, l := range lines {
+		docs[i] = text(l)
+	}
+	return join(docs, rawline{})
+}
@@ -0,0 +1,173 @@
+package printer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// doc is a document of the layout algebra from Wadler's "A prettier printer":
+// text laid out as is, line breaks, nesting and groups laid out on a single line if they fit.
+type doc interface {
+	docNode()
+}
+
+// text is laid out as is. It must not contain a newline.
+type text string
+
+// line is a line break, or a space in a group laid out on a single line.
+// A soft line is empty instead of a space.
+type line struct {
+	soft bool
+}
+
+// hardline is a line break even in a group, which is then never laid out on a single line.
+type hardline struct{}
+
+// rawline is a hard line break without indentation, for text such as block comments kept verbatim.
+type rawline struct{}
+
+type concat []doc
+
+// nest indents the lines broken in doc by indent more spaces.
+type nest struct {
+	indent int
+	doc    doc
+}
+
+// groupDoc is laid out on a single line if it fits in the remaining width, and broken otherwise.
+type groupDoc struct {
+	doc doc
+}
+
+func (text) docNode()     {}
+func (line) docNode()     {}
+func (hardline) docNode() {}
+func (rawline) docNode()  {}
+func (concat) docNode()   {}
+func (nest) docNode()     {}
+func (groupDoc) docNode() {}
+
+var (
+	space    = line{}
+	softline = line{soft: true}
+)
+
+func group(d doc) doc {
+	return groupDoc{doc: d}
+}
+
+func cat(docs ...doc) doc {
+	return concat(docs)
+}
+
+// join places sep between docs.
+func join(docs []doc, sep doc) doc {
+	joined := make(concat, 0, 2*len(docs))
+	for i, d := range docs {
+		if i > 0 {
+			joined = append(joined, sep)
+		}
+		joined = append(joined, d)
+	}
+	return joined
+}
+
+// layoutItem is a document to lay out with its indentation and whether it is on a single line.
+type layoutItem struct {
+	indent int
+	flat   bool
+	doc    doc
+}
+
+// layout renders d, breaking the outermost groups that do not fit in width.
+func layout(d doc, width int) string {
+	var b strings.Builder
+	column := 0
+	// Indentation is written with the first text of a line, so that blank lines have no trailing spaces
+	indent := 0
+	stack := []layoutItem{{doc: d}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := item.doc.(type) {
+		case text:
+			if d == "" {
+				continue
+			}
+			b.WriteString(strings.Repeat(" ", indent))
+			indent = 0
+			b.WriteString(string(d))
+			column += utf8.RuneCountInString(string(d))
+		case line:
+			if item.flat {
+				if !d.soft {
+					b.WriteByte(' ')
+					column++
+				}
+				continue
+			}
+			b.WriteByte('\n')
+			indent = item.indent
+			column = item.indent
+		case hardline:
+			b.WriteByte('\n')
+			indent = item.indent
+			column = item.indent
+		case rawline:
+			b.WriteByte('\n')
+			indent = 0
+			column = 0
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, layoutItem{indent: item.indent, flat: item.flat, doc: d[i]})
+			}
+		case nest:
+			stack = append(stack, layoutItem{indent: item.indent + d.indent, flat: item.flat, doc: d.doc})
+		case groupDoc:
+			flat := item.flat || fits(layoutItem{indent: item.indent, flat: true, doc: d.doc}, stack, width-column)
+			stack = append(stack, layoutItem{indent: item.indent, flat: flat, doc: d.doc})
+		}
+	}
+	return b.String()
+}
+
+// fits reports whether item, followed by rest up to its next line break, fits in width.
+func fits(item layoutItem, rest []layoutItem, width int) bool {
+	stack := []layoutItem{item}
+	for width >= 0 {
+		if len(stack) == 0 {
+			if len(rest) == 0 {
+				return true
+			}
+			stack = append(stack, rest[len(rest)-1])
+			rest = rest[:len(rest)-1]
+		}
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := item.doc.(type) {
+		case text:
+			width -= utf8.RuneCountInString(string(d))
+		case line:
+			if !item.flat {
+				return true
+			}
+			if !d.soft {
+				width--
+			}
+		case hardline, rawline:
+			// A group with a forced line break is never on a single line
+			return !item.flat
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, layoutItem{indent: item.indent, flat: item.flat, doc: d[i]})
+			}
+		case nest:
+			stack = append(stack, layoutItem{indent: item.indent + d.indent, flat: item.flat, doc: d.doc})
+		case groupDoc:
+			stack = append(stack, layoutItem{indent: item.indent, flat: item.flat, doc: d.doc})
+		}
+	}
+	return false
+}
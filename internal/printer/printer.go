@@ -0,0 +1,731 @@
+// Package printer prints STLC programs, expressions and types as source code.
+//
+// Expressions are printed with as few parentheses as the grammar allows,
+// and laid out with the algorithm of Wadler's "A prettier printer":
+// a construct stays on a single line if it fits in the line width, and is broken and indented otherwise.
+package printer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/parser"
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+// Config controls the layout of printed code.
+type Config struct {
+	// Width is the line width that the layout tries to fit in.
+	Width int
+	// Indent is the number of spaces of each level of indentation.
+	Indent int
+	// Unicode selects the Unicode symbols λ, Λ, → and ∀ instead of \, /\, -> and forall.
+	Unicode bool
+}
+
+// DefaultConfig is the configuration used by Fprint and Format.
+var DefaultConfig = Config{Width: 80, Indent: 2}
+
+// Fprint prints node to w.
+// The node must be an ast.Expr, ast.Type, *ast.Program, ast.TypedExpr or *ast.TypedProgram.
+// A typed tree is printed with the inferred types of its abstractions and injections.
+// A program is followed by a newline.
+func (c *Config) Fprint(w io.Writer, node any) error {
+	p := &printer{indent: c.Indent, unicode: c.Unicode}
+
+	var d doc
+	switch n := node.(type) {
+	case *ast.Program:
+		d = cat(p.program(n), hardline{})
+	case *ast.TypedProgram:
+		d = cat(p.program(untypeProgram(n)), hardline{})
+	case ast.TypedExpr:
+		// Checked before ast.Expr, which some typed expressions also implement by embedding
+		d = p.expr(untype(n), topContext)
+	case ast.Expr:
+		d = p.expr(n, topContext)
+	case ast.Type:
+		d = text(p.typeString(n, typePrecForall))
+	default:
+		return fmt.Errorf("printer: unsupported node type %T", node)
+	}
+
+	_, err := io.WriteString(w, layout(d, c.Width))
+	return err
+}
+
+// Fprint prints node to w with DefaultConfig.
+func Fprint(w io.Writer, node any) error {
+	return DefaultConfig.Fprint(w, node)
+}
+
+// Format parses a program and returns it printed with DefaultConfig, keeping its comments.
+// Formatting is idempotent: formatting the result again returns it unchanged.
+func Format(src string) (string, error) {
+	return DefaultConfig.Format(src)
+}
+
+// Format parses a program and returns it printed with c, keeping its comments.
+func (c *Config) Format(src string) (string, error) {
+	prog, err := parser.ParseProgram(src)
+	if err != nil {
+		return "", err
+	}
+	comments, err := scanComments(src)
+	if err != nil {
+		return "", err
+	}
+
+	p := &printer{indent: c.Indent, unicode: c.Unicode, comments: comments}
+	return layout(cat(p.program(prog), hardline{}), c.Width), nil
+}
+
+// Precedence levels of expressions, from the loosest to the tightest
+const (
+	precOpen = iota // abstractions, let, if, case, injections and variants, extending as far right as possible
+	// Operators of precedence n in ast are at the level precOpen+n, between open expressions and application
+	precApp     = precOpen + ast.PrefixPrec + iota // application and type application
+	precFix                                        // fix e
+	precPostfix                                    // projections
+	precAtom                                       // variables, literals, tuples, records and sections
+)
+
+// context describes where an expression is printed, to decide whether it needs parentheses.
+type context struct {
+	// prec is the lowest precedence of a closed expression printed without parentheses.
+	prec int
+	// trailing reports that nothing of the enclosing expression follows,
+	// so an open expression may extend to the right.
+	trailing bool
+	// branch reports that another case branch follows, which a trailing case analysis would take.
+	branch bool
+}
+
+// topContext is the context of an expression delimited by keywords or brackets.
+var topContext = context{prec: precOpen, trailing: true}
+
+// tail returns the context of the last part of an open expression printed in ctx.
+func (ctx context) tail() context {
+	return context{prec: precOpen, trailing: ctx.trailing, branch: ctx.branch}
+}
+
+type printer struct {
+	indent  int
+	unicode bool
+	// comments are the comments of the source not printed yet, in source order.
+	comments []token.Token
+}
+
+func (p *printer) program(prog *ast.Program) doc {
+	var docs concat
+	var prevEnd token.Position
+	typeDecls, decls := prog.Types, prog.Decls
+	for i := 0; len(typeDecls) > 0 || len(decls) > 0; i++ {
+		// Type declarations and definitions are printed in source order
+		var pos, end token.Position
+		var typeDecl *ast.TypeDecl
+		var decl *ast.Decl
+		if len(typeDecls) > 0 && (len(decls) == 0 || !decls[0].Pos.Before(typeDecls[0].Pos)) {
+			typeDecl, typeDecls = typeDecls[0], typeDecls[1:]
+			pos, end = typeDecl.Pos, typeDecl.End
+		} else {
+			decl, decls = decls[0], decls[1:]
+			pos, end = decl.Pos, decl.End
+		}
+
+		if i > 0 {
+			docs = append(docs, p.separator(prevEnd, pos))
+		}
+		docs = append(docs, p.commentsBefore(pos))
+		if typeDecl != nil {
+			docs = append(docs, p.typeDecl(typeDecl))
+		} else {
+			docs = append(docs, p.binding(decl.Pos, decl.Name, decl.Type, decl.Value))
+		}
+		trailing, end := p.trailingComments(end)
+		docs = append(docs, trailing)
+		prevEnd = end
+	}
+	if len(prog.Types) > 0 || len(prog.Decls) > 0 {
+		docs = append(docs, p.separator(prevEnd, prog.Main.Position()))
+	}
+	main := p.expr(prog.Main, topContext)
+	trailing, end := p.trailingComments(prog.Main.Span().End)
+	docs = append(docs, main, trailing)
+
+	// Comments after the main expression
+	for len(p.comments) > 0 {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		docs = append(docs, p.separator(end, c.Pos), comment(c))
+		end = c.End()
+	}
+	return docs
+}
+
+// typeDecl returns the document of a data type declaration with its constructors separated by '|'.
+func (p *printer) typeDecl(decl *ast.TypeDecl) doc {
+	var body concat
+	for i, con := range decl.Constructors {
+		body = append(body, space)
+		if i > 0 {
+			body = append(body, text("| "))
+		}
+		s := con.Name
+		for _, param := range con.Params {
+			s += " " + p.typeString(param, typePrecAtom)
+		}
+		body = append(body, text(s))
+	}
+	return group(cat(text("type "+decl.Name+" ="), nest{p.indent, body}))
+}
+
+// separator breaks the line between top-level definitions and comments,
+// keeping a single blank line where the source had blank lines.
+func (p *printer) separator(prevEnd, next token.Position) doc {
+	if len(p.comments) > 0 && p.comments[0].Pos.Before(next) {
+		next = p.comments[0].Pos
+	}
+	if next.Line > prevEnd.Line+1 {
+		return cat(hardline{}, hardline{})
+	}
+	return hardline{}
+}
+
+func (p *printer) expr(e ast.Expr, ctx context) doc {
+	comments := p.commentsBefore(e.Position())
+	if needsParens(e, ctx) {
+		return cat(comments, text("("), p.bare(e, topContext), text(")"))
+	}
+	return cat(comments, p.bare(e, ctx))
+}
+
+func needsParens(e ast.Expr, ctx context) bool {
+	// An open expression may appear anywhere it extends to the end, and a closed one anywhere its precedence is high enough
+	if prec := exprPrec(e); prec == precOpen {
+		if !ctx.trailing {
+			return true
+		}
+	} else if prec < ctx.prec {
+		return true
+	}
+
+	switch e.(type) {
+	case *ast.CaseExpr, *ast.ListCaseExpr, *ast.VariantCaseExpr, *ast.MatchExpr:
+		return ctx.branch
+	}
+	return false
+}
+
+func exprPrec(e ast.Expr) int {
+	switch e := e.(type) {
+	case *ast.AppExpr:
+		if op, operands, ok := operatorApp(e); ok {
+			if leftSection(op, operands) {
+				return precAtom
+			}
+			return precOpen + op.Prec
+		}
+		return precApp
+	case *ast.AbsExpr:
+		if _, _, ok := rightSection(e); ok {
+			return precAtom
+		}
+		return precOpen
+	case *ast.TyAppExpr:
+		return precApp
+	case *ast.FixExpr, *ast.FoldExpr, *ast.UnfoldExpr:
+		return precFix
+	case *ast.ListExpr:
+		// A list literal is parenthesized as an argument where '[' would start a type argument
+		if len(e.Elems) > 0 && !isLiteral(e.Elems[0]) {
+			return precFix
+		}
+		return precAtom
+	case *ast.ProjExpr, *ast.RecordProjExpr:
+		return precPostfix
+	case *ast.VarExpr, *ast.BoolExpr, *ast.IntExpr, *ast.UnitExpr, *ast.StringExpr, *ast.CharExpr, *ast.FloatExpr, *ast.NilExpr, *ast.TupleExpr, *ast.RecordExpr, *ast.ErrorExpr:
+		return precAtom
+	default:
+		return precOpen
+	}
+}
+
+// bare returns the document of e without enclosing parentheses.
+func (p *printer) bare(e ast.Expr, ctx context) doc {
+	switch e := e.(type) {
+	case *ast.VarExpr:
+		if e.Op != "" {
+			return text("(" + e.Op + ")")
+		}
+		return text(e.Name)
+	case *ast.BoolExpr, *ast.IntExpr, *ast.UnitExpr, *ast.StringExpr, *ast.CharExpr, *ast.FloatExpr:
+		return text(literal(e))
+	case *ast.AbsExpr:
+		if op, operand, ok := rightSection(e); ok {
+			return cat(text("("+op.Symbol+" "), p.expr(operand, rightOperand(op)), text(")"))
+		}
+		head := p.symbol(`\`, "λ") + e.Param
+		if e.ParamType != nil {
+			head += ":" + p.typeString(e.ParamType, typePrecForall)
+		}
+		return p.abstraction(head+".", e.Body, ctx)
+	case *ast.TyAbsExpr:
+		return p.abstraction(p.symbol(`/\`, "Λ")+e.TypeVar+".", e.Body, ctx)
+	case *ast.AppExpr:
+		if op, operands, ok := operatorApp(e); ok {
+			return p.operator(op, operands)
+		}
+		return p.app(e)
+	case *ast.TyAppExpr:
+		return p.app(e)
+	case *ast.IfExpr:
+		return group(cat(
+			text("if "), p.expr(e.Cond, topContext),
+			nest{p.indent, cat(
+				space, text("then "), p.expr(e.Then, topContext),
+				space, text("else "), p.expr(e.Else, ctx.tail()),
+			)},
+		))
+	case *ast.LetExpr:
+		return group(cat(
+			p.binding(e.Pos, e.Name, e.Type, e.Value), text(" in"),
+			space, p.expr(e.Body, ctx.tail()),
+		))
+	case *ast.FixExpr:
+		return cat(text("fix "), p.expr(e.Func, context{prec: precPostfix}))
+	case *ast.FoldExpr:
+		return p.fold("fold", e.Type, e.Value)
+	case *ast.UnfoldExpr:
+		return p.fold("unfold", e.Type, e.Value)
+	case *ast.TupleExpr:
+		elems := make([]doc, len(e.Elems))
+		for i, elem := range e.Elems {
+			elems[i] = p.expr(elem, topContext)
+		}
+		return p.bracket("(", elems, ")")
+	case *ast.ProjExpr:
+		return cat(p.expr(e.Tuple, context{prec: precPostfix}), text("."+strconv.Itoa(e.Index)))
+	case *ast.InjExpr:
+		keyword := "inr "
+		if e.Left {
+			keyword = "inl "
+		}
+		return group(cat(
+			text(keyword), p.expr(e.Value, context{prec: precPostfix}),
+			nest{p.indent, cat(space, text("as "+p.typeString(e.Type, typePrecForall)))},
+		))
+	case *ast.CaseExpr:
+		return p.caseAnalysis("case", e.Scrutinee, "of", []doc{
+			p.branch("inl "+e.LeftVar, "=>", e.Left, context{prec: precOpen, trailing: true, branch: true}),
+			p.branch("inr "+e.RightVar, "=>", e.Right, ctx.tail()),
+		})
+	case *ast.NilExpr:
+		return text("nil[" + p.typeString(e.ElemType, typePrecForall) + "]")
+	case *ast.ListExpr:
+		elems := make([]doc, len(e.Elems))
+		for i, elem := range e.Elems {
+			elems[i] = p.expr(elem, topContext)
+		}
+		return p.bracket("[", elems, "]")
+	case *ast.ListCaseExpr:
+		return p.caseAnalysis("case", e.Scrutinee, "of", []doc{
+			p.branch("[]", "=>", e.Nil, context{prec: precOpen, trailing: true, branch: true}),
+			p.branch(e.HeadVar+" :: "+e.TailVar, "=>", e.Cons, ctx.tail()),
+		})
+	case *ast.RecordExpr:
+		fields := make([]doc, len(e.Fields))
+		for i, field := range e.Fields {
+			fields[i] = group(cat(text(field.Label+" ="), nest{p.indent, cat(space, p.expr(field.Value, topContext))}))
+		}
+		return p.bracket("{", fields, "}")
+	case *ast.RecordProjExpr:
+		return cat(p.expr(e.Record, context{prec: precPostfix}), text("."+e.Label))
+	case *ast.VariantExpr:
+		return group(cat(
+			text("<"+e.Label+" = "), p.expr(e.Value, topContext), text(">"),
+			nest{p.indent, cat(space, text("as "+p.typeString(e.Type, typePrecForall)))},
+		))
+	case *ast.VariantCaseExpr:
+		branches := make([]doc, len(e.Branches))
+		for i, b := range e.Branches {
+			bctx := context{prec: precOpen, trailing: true, branch: true}
+			if i == len(e.Branches)-1 {
+				bctx = ctx.tail()
+			}
+			branches[i] = p.branch("<"+b.Label+" = "+b.Var+">", "=>", b.Body, bctx)
+		}
+		return p.caseAnalysis("case", e.Scrutinee, "of", branches)
+	case *ast.MatchExpr:
+		arms := make([]doc, len(e.Arms))
+		for i, arm := range e.Arms {
+			actx := context{prec: precOpen, trailing: true, branch: true}
+			if i == len(e.Arms)-1 {
+				actx = ctx.tail()
+			}
+			arms[i] = p.branch(patternString(arm.Pattern, false), p.symbol("->", "→"), arm.Body, actx)
+		}
+		return p.caseAnalysis("match", e.Scrutinee, "with", arms)
+	case *ast.ErrorExpr:
+		return text("<error>")
+	default:
+		panic(fmt.Sprintf("printer: unexpected expression %T", e))
+	}
+}
+
+// abstraction returns the document of an abstraction with the given head, breaking before its body.
+func (p *printer) abstraction(head string, body ast.Expr, ctx context) doc {
+	return group(cat(text(head), nest{p.indent, cat(space, p.expr(body, ctx.tail()))}))
+}
+
+// fold returns the document of a fold or an unfold of value with the recursive type t.
+func (p *printer) fold(keyword string, t ast.Type, value ast.Expr) doc {
+	return cat(text(keyword+" ["+p.typeString(t, typePrecForall)+"] "), p.expr(value, context{prec: precPostfix}))
+}
+
+// app returns the document of a spine of applications and type applications,
+// breaking between the arguments.
+func (p *printer) app(e ast.Expr) doc {
+	// Collect the spine from the last argument
+	var spine []ast.Expr
+	head := e
+loop:
+	for {
+		// An operator application is the head of the applications of further arguments
+		if _, _, ok := operatorApp(head); ok {
+			break
+		}
+		switch a := head.(type) {
+		case *ast.AppExpr:
+			spine = append(spine, a)
+			head = a.Func
+		case *ast.TyAppExpr:
+			spine = append(spine, a)
+			head = a.Func
+		default:
+			break loop
+		}
+	}
+
+	var args concat
+	for i := len(spine) - 1; i >= 0; i-- {
+		switch a := spine[i].(type) {
+		case *ast.AppExpr:
+			// The last argument could extend to the right, but is parenthesized for readability
+			args = append(args, space, p.expr(a.Arg, context{prec: precPostfix}))
+		case *ast.TyAppExpr:
+			args = append(args, space, text("["+p.typeString(a.TypeArg, typePrecForall)+"]"))
+		}
+	}
+
+	return group(cat(p.expr(head, context{prec: precFix}), nest{p.indent, args}))
+}
+
+// operator returns the document of the operator op applied to operands:
+// a prefix operator applied to its operand, an infix operator applied to both operands, or a left section.
+func (p *printer) operator(op ast.Operator, operands []ast.Expr) doc {
+	if op.Prec == ast.PrefixPrec {
+		operand := p.expr(operands[0], context{prec: precOpen + ast.PrefixPrec})
+		// '-' followed by '-' would start a comment
+		if op.Symbol == "-" && startsWithMinus(operands[0]) {
+			operand = cat(text("("), p.expr(operands[0], topContext), text(")"))
+		}
+		// '-' directly followed by a digit would be read as the sign of a negative literal
+		if op.Symbol == "-" && isNumber(operands[0]) && !startsWithMinus(operands[0]) {
+			return cat(text(op.Symbol+" "), operand)
+		}
+		return cat(text(op.Symbol), operand)
+	}
+
+	left := p.expr(operands[0], leftOperand(op))
+	if leftSection(op, operands) {
+		return cat(text("("), left, text(" "+op.Symbol+")"))
+	}
+	return group(cat(left, nest{p.indent, cat(space, text(op.Symbol+" "), p.expr(operands[1], rightOperand(op)))}))
+}
+
+// leftOperand returns the context of the left operand of the infix operator op.
+// Open expressions are parenthesized in operands, as in arguments.
+func leftOperand(op ast.Operator) context {
+	if op.Assoc == ast.AssocLeft {
+		return context{prec: precOpen + op.Prec}
+	}
+	return context{prec: precOpen + op.Prec + 1}
+}
+
+// rightOperand returns the context of the right operand of the infix operator op.
+func rightOperand(op ast.Operator) context {
+	if op.Assoc == ast.AssocRight {
+		return context{prec: precOpen + op.Prec}
+	}
+	return context{prec: precOpen + op.Prec + 1}
+}
+
+// operatorApp returns the operator and operands of e if e is desugared from an operator application:
+// a prefix operator applied to one operand, or an infix operator applied to two or, as a left section, one.
+func operatorApp(e ast.Expr) (ast.Operator, []ast.Expr, bool) {
+	app, ok := e.(*ast.AppExpr)
+	if !ok {
+		return ast.Operator{}, nil, false
+	}
+	if v, ok := app.Func.(*ast.VarExpr); ok && v.Op != "" {
+		if op, ok := ast.UnaryOperators[v.Op]; ok && op.Builtin == v.Name {
+			return op, []ast.Expr{app.Arg}, true
+		}
+		if op, ok := ast.BinaryOperators[v.Op]; ok && op.Builtin == v.Name {
+			return op, []ast.Expr{app.Arg}, true
+		}
+		return ast.Operator{}, nil, false
+	}
+	if inner, ok := app.Func.(*ast.AppExpr); ok {
+		if v, ok := inner.Func.(*ast.VarExpr); ok && v.Op != "" {
+			if op, ok := ast.BinaryOperators[v.Op]; ok && op.Builtin == v.Name {
+				return op, []ast.Expr{inner.Arg, app.Arg}, true
+			}
+		}
+	}
+	return ast.Operator{}, nil, false
+}
+
+// leftSection reports whether the operator application of op to operands is a left section such as (a +).
+func leftSection(op ast.Operator, operands []ast.Expr) bool {
+	return op.Prec != ast.PrefixPrec && len(operands) == 1
+}
+
+// rightSection returns the operator and operand of e if e is desugared from a right section such as (+ 1).
+func rightSection(e *ast.AbsExpr) (ast.Operator, ast.Expr, bool) {
+	if e.Param != ast.SectionParam {
+		return ast.Operator{}, nil, false
+	}
+	op, operands, ok := operatorApp(e.Body)
+	if !ok || len(operands) != 2 {
+		return ast.Operator{}, nil, false
+	}
+	if v, ok := operands[0].(*ast.VarExpr); !ok || v.Name != ast.SectionParam {
+		return ast.Operator{}, nil, false
+	}
+	return op, operands[1], true
+}
+
+// isLiteral reports whether e is a literal other than (), which is printed starting with a token that cannot start a type.
+func isLiteral(e ast.Expr) bool {
+	switch e.(type) {
+	case *ast.BoolExpr, *ast.IntExpr, *ast.StringExpr, *ast.CharExpr, *ast.FloatExpr, *ast.NilExpr, *ast.ListExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNumber reports whether e is an integer or float literal.
+func isNumber(e ast.Expr) bool {
+	switch e.(type) {
+	case *ast.IntExpr, *ast.FloatExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+// startsWithMinus reports whether e is printed starting with '-'.
+func startsWithMinus(e ast.Expr) bool {
+	if i, ok := e.(*ast.IntExpr); ok {
+		return i.Value < 0 || i.Big != nil && i.Big.Sign() < 0
+	}
+	if f, ok := e.(*ast.FloatExpr); ok {
+		return math.Signbit(f.Value)
+	}
+	op, _, ok := operatorApp(e)
+	return ok && op.Symbol == "-" && op.Prec == ast.PrefixPrec
+}
+
+// binding returns the document of let name [: type] = value,
+// or letrec name [: type] = body if value is desugared from a recursive binding at pos.
+func (p *printer) binding(pos token.Position, name string, typ ast.Type, value ast.Expr) doc {
+	keyword := "let "
+	if abs, ok := letrec(pos, name, typ, value); ok {
+		keyword = "letrec "
+		value = abs.Body
+	}
+
+	head := keyword + name
+	if typ != nil {
+		head += " : " + p.typeString(typ, typePrecForall)
+	}
+	head += " ="
+
+	switch value.(type) {
+	case *ast.AbsExpr, *ast.TyAbsExpr:
+		if p.hasCommentsBefore(value.Position()) {
+			break
+		}
+		// An abstraction starts on the line of its name and breaks before its body instead
+		return cat(text(head+" "), p.expr(value, topContext))
+	}
+	return group(cat(text(head), nest{p.indent, cat(space, p.expr(value, topContext))}))
+}
+
+// letrec returns the function of a binding desugared from letrec name [: type] = body,
+// which binds fix (\name[:type]. body) at the position of the binding.
+func letrec(pos token.Position, name string, typ ast.Type, value ast.Expr) (*ast.AbsExpr, bool) {
+	fix, ok := value.(*ast.FixExpr)
+	if !ok || fix.Pos != pos {
+		return nil, false
+	}
+	abs, ok := fix.Func.(*ast.AbsExpr)
+	if !ok || abs.Pos != pos || abs.Param != name || abs.ParamType != typ {
+		return nil, false
+	}
+	return abs, true
+}
+
+// caseAnalysis returns the document of a case analysis or match with its branches separated by '|',
+// where keyword and of surround the scrutinee.
+func (p *printer) caseAnalysis(keyword string, scrutinee ast.Expr, of string, branches []doc) doc {
+	var body concat
+	for i, b := range branches {
+		body = append(body, space)
+		if i > 0 {
+			body = append(body, text("| "))
+		}
+		body = append(body, b)
+	}
+	return group(cat(text(keyword+" "), p.expr(scrutinee, topContext), text(" "+of), nest{p.indent, body}))
+}
+
+func (p *printer) branch(pattern, arrow string, body ast.Expr, ctx context) doc {
+	return group(cat(text(pattern+" "+arrow), nest{p.indent, cat(space, p.expr(body, ctx))}))
+}
+
+// patternString returns pattern printed, parenthesized if it is a constructor applied to arguments
+// and arg reports that it is itself an argument.
+func patternString(pattern ast.Pattern, arg bool) string {
+	switch pt := pattern.(type) {
+	case *ast.WildcardPattern:
+		return "_"
+	case *ast.VarPattern:
+		return pt.Name
+	case *ast.LiteralPattern:
+		return literal(pt.Value)
+	case *ast.ConstructorPattern:
+		s := pt.Name
+		for _, a := range pt.Args {
+			s += " " + patternString(a, true)
+		}
+		if arg && len(pt.Args) > 0 {
+			return "(" + s + ")"
+		}
+		return s
+	default:
+		panic(fmt.Sprintf("printer: unexpected pattern %T", pattern))
+	}
+}
+
+// literal returns the source form of a literal expression.
+func literal(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.BoolExpr:
+		return strconv.FormatBool(e.Value)
+	case *ast.IntExpr:
+		if e.Big != nil {
+			return e.Big.String()
+		}
+		return strconv.Itoa(e.Value)
+	case *ast.StringExpr:
+		return token.Quote(e.Value)
+	case *ast.CharExpr:
+		return token.QuoteChar(e.Value)
+	case *ast.FloatExpr:
+		return ast.FormatFloat(e.Value)
+	default:
+		return "()"
+	}
+}
+
+// bracket returns the document of comma-separated elements between brackets,
+// one per line if they do not fit on a single line.
+func (p *printer) bracket(open string, elems []doc, close string) doc {
+	if len(elems) == 0 {
+		return text(open + close)
+	}
+	return group(cat(
+		text(open),
+		nest{p.indent, cat(softline, join(elems, cat(text(","), space)))},
+		softline, text(close),
+	))
+}
+
+// symbol returns the ASCII or Unicode form of a symbol depending on the style.
+func (p *printer) symbol(ascii, unicode string) string {
+	if p.unicode {
+		return unicode
+	}
+	return ascii
+}
+
+// Precedence levels of types, from the loosest to the tightest
+const (
+	typePrecForall  = iota // forall A. T and mu X. T, extending as far right as possible
+	typePrecArrow          // right-associative T -> T
+	typePrecSum            // left-associative T + T
+	typePrecProduct        // T * T * ...
+	typePrecList           // List T
+	typePrecAtom           // base types, type variables, records and variants
+)
+
+// typeString returns t printed with as few parentheses as needed at the precedence prec.
+func (p *printer) typeString(t ast.Type, prec int) string {
+	var s string
+	var tprec int
+	switch t := t.(type) {
+	case *ast.ForallType:
+		s = p.symbol("forall ", "∀") + t.Var + ". " + p.typeString(t.Body, typePrecForall)
+		tprec = typePrecForall
+	case *ast.RecType:
+		s = "mu " + t.Var + ". " + p.typeString(t.Body, typePrecForall)
+		tprec = typePrecForall
+	case *ast.FuncType:
+		s = p.typeString(t.From, typePrecSum) + p.symbol(" -> ", " → ") + p.typeString(t.To, typePrecForall)
+		tprec = typePrecArrow
+	case *ast.SumType:
+		s = p.typeString(t.Left, typePrecSum) + " + " + p.typeString(t.Right, typePrecProduct)
+		tprec = typePrecSum
+	case *ast.ProductType:
+		elems := make([]string, len(t.Elems))
+		for i, elem := range t.Elems {
+			elems[i] = p.typeString(elem, typePrecList)
+		}
+		s = strings.Join(elems, " * ")
+		tprec = typePrecProduct
+	case *ast.ListType:
+		s = "List " + p.typeString(t.Elem, typePrecAtom)
+		tprec = typePrecList
+	case *ast.RecordType:
+		s = "{" + p.fieldTypesString(t.Fields) + "}"
+		tprec = typePrecAtom
+	case *ast.VariantType:
+		s = "<" + p.fieldTypesString(t.Fields) + ">"
+		tprec = typePrecAtom
+	default:
+		// Base types, type variables, meta variables and the error type
+		s = t.String()
+		tprec = typePrecAtom
+	}
+
+	if tprec < prec {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func (p *printer) fieldTypesString(fields []ast.Field) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Label + ": " + p.typeString(f.Type, typePrecForall)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,450 @@
+package printer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/parser"
+	"github.com/shota3506/gostlc/internal/printer"
+	"github.com/shota3506/gostlc/internal/types"
+)
+
+func TestFprintExpr(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "redundant parentheses",
+			input:    `((\x:Int. x) (f (g 1))) ((h))`,
+			expected: `(\x:Int. x) (f (g 1)) h`,
+		},
+		{
+			name:     "abstraction arguments",
+			input:    `f (\x. x) (\y. y)`,
+			expected: `f (\x. x) (\y. y)`,
+		},
+		{
+			name:     "open expression argument",
+			input:    `f (if true then 1 else 2) (if false then 3 else 4)`,
+			expected: `f (if true then 1 else 2) (if false then 3 else 4)`,
+		},
+		{
+			name:     "let argument",
+			input:    `f (let x = 1 in x)`,
+			expected: `f (let x = 1 in x)`,
+		},
+		{
+			name:     "variant argument",
+			input:    `f (<a = 1> as <a: Int>)`,
+			expected: `f (<a = 1> as <a: Int>)`,
+		},
+		{
+			name:     "fix",
+			input:    `(fix f) (fix (g x))`,
+			expected: `fix f (fix (g x))`,
+		},
+		{
+			name:     "projections",
+			input:    `((1, (2, 3)).2).1 ((fix f).1) ((f x).y)`,
+			expected: `(1, (2, 3)).2.1 (fix f).1 (f x).y`,
+		},
+		{
+			name:     "type application",
+			input:    `((/\A. \x:A. x) [Int]) 1`,
+			expected: `(/\A. \x:A. x) [Int] 1`,
+		},
+		{
+			name:     "abstraction before type application",
+			input:    `f (\x. x) [Int]`,
+			expected: `f (\x. x) [Int]`,
+		},
+		{
+			name:     "injection",
+			input:    `(inl (f x) as Int + Bool).1`,
+			expected: `(inl (f x) as Int + Bool).1`,
+		},
+		{
+			name:  "case in case branch",
+			input: `case x of inl a => (\y. case a of inl b => b | inr c => c) | inr d => (case d of inl e => e | inr g => g)`,
+			expected: `case x of
+  inl a => \y. (case a of inl b => b | inr c => c)
+  | inr d => case d of inl e => e | inr g => g`,
+		},
+		{
+			name:  "variant case",
+			input: `case v of <a = x> => (case x of <b = y> => y) | <c = z> => f (case z of <d = w> => w)`,
+			expected: `case v of
+  <a = x> => (case x of <b = y> => y)
+  | <c = z> => f (case z of <d = w> => w)`,
+		},
+		{
+			name:     "letrec",
+			input:    `letrec f : Int -> Int = \n:Int. f n in f 1`,
+			expected: `letrec f : Int -> Int = \n:Int. f n in f 1`,
+		},
+		{
+			name:     "fix of abstraction",
+			input:    `let f = fix (\f:Int -> Int. f) in f`,
+			expected: `let f = fix (\f:Int -> Int. f) in f`,
+		},
+		{
+			name:     "operators",
+			input:    `((1 + 2) * 3) - (4 - (5 - 6)) == (add 1 2) && (!(a || b) || (c && d))`,
+			expected: `(1 + 2) * 3 - (4 - (5 - 6)) == add 1 2 && (!(a || b) || c && d)`,
+		},
+		{
+			name:     "operator operands",
+			input:    `(f x) + (\y. y) 1 + (if c then 1 else 2) + -(g 1) + (- (-1)) + -(-x)`,
+			expected: `f x + (\y. y) 1 + (if c then 1 else 2) + -g 1 + -(-1) + -(-x)`,
+		},
+		{
+			name:     "base type literals",
+			input:    `(\u:Unit. u) () (concat "a\tb\u{7f}" (show '\'')) (-1.50) 2.0`,
+			expected: `(\u:Unit. u) () (concat "a\tb\u{7f}" (show '\'')) -1.5 2.0`,
+		},
+		{
+			name:     "integer literals out of the range of Int",
+			input:    `f 99999999999999999999 - (-99999999999999999999)`,
+			expected: `f 99999999999999999999 - -99999999999999999999`,
+		},
+		{
+			name:     "operator sections",
+			input:    `((+), (1 +), (- 1), (-), (* (2 + 3)), (<) 1, (+ 1) 2)`,
+			expected: `((+), (1 +), - 1, (-), (* (2 + 3)), (1 <), (+ 1) 2)`,
+		},
+		{
+			name:     "operator applied to more arguments",
+			input:    `(+) 1 2 3`,
+			expected: `(1 + 2) 3`,
+		},
+		{
+			name:     "lists",
+			input:    `((nil[Int]), [1, (2 + 3)], f ([x]) [1], ((x :: y) :: xs), x :: (y :: xs), 1 + 2 :: [])`,
+			expected: `(nil[Int], [1, 2 + 3], f ([x]) [1], (x :: y) :: xs, x :: y :: xs, 1 + 2 :: [])`,
+		},
+		{
+			name:     "list case analysis",
+			input:    `f (case xs of [] => 0 | h :: t => h)`,
+			expected: `f (case xs of [] => 0 | h :: t => h)`,
+		},
+		{
+			name:     "pattern match",
+			input:    `f (match s with | Circle r -> r | Rect (Some w) _ -> case x of inl a => a | inr b => b | _ -> -1)`,
+			expected: "f\n  (match s with\n    Circle r -> r\n    | Rect (Some w) _ -> (case x of inl a => a | inr b => b)\n    | _ -> -1)",
+		},
+		{
+			name:     "fold and unfold",
+			input:    `(unfold [(mu X. Int -> X)] (fold [mu X. (Int -> X)] (f))) 1`,
+			expected: `unfold [mu X. Int -> X] (fold [mu X. Int -> X] f) 1`,
+		},
+		{
+			name:     "records and tuples",
+			input:    `let r = {x = 1, y = (true, -2)} in r.x`,
+			expected: `let r = {x = 1, y = (true, -2)} in r.x`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := sprint(t, &printer.DefaultConfig, expr); got != tt.expected {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFprintType(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		input    ast.Type
+		expected string
+	}{
+		{
+			name: "right-associative arrow",
+			input: &ast.FuncType{
+				From: &ast.FuncType{From: &ast.IntType{}, To: &ast.IntType{}},
+				To:   &ast.FuncType{From: &ast.IntType{}, To: &ast.BoolType{}},
+			},
+			expected: "(Int -> Int) -> Int -> Bool",
+		},
+		{
+			name: "left-associative sum",
+			input: &ast.SumType{
+				Left:  &ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+				Right: &ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+			},
+			expected: "Int + Bool + (Int + Bool)",
+		},
+		{
+			name: "product of sum and product",
+			input: &ast.ProductType{Elems: []ast.Type{
+				&ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+				&ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.IntType{}}},
+				&ast.IntType{},
+			}},
+			expected: "(Int + Bool) * (Int * Int) * Int",
+		},
+		{
+			name: "sum of products to function",
+			input: &ast.FuncType{
+				From: &ast.SumType{
+					Left:  &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.IntType{}}},
+					Right: &ast.BoolType{},
+				},
+				To: &ast.IntType{},
+			},
+			expected: "Int * Int + Bool -> Int",
+		},
+		{
+			name: "forall",
+			input: &ast.FuncType{
+				From: &ast.ForallType{Var: "A", Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "A"}}},
+				To:   &ast.ForallType{Var: "B", Body: &ast.TypeVar{Name: "B"}},
+			},
+			expected: "(forall A. A -> A) -> forall B. B",
+		},
+		{
+			name: "recursive type",
+			input: &ast.FuncType{
+				From: &ast.RecType{Var: "S", Body: &ast.FuncType{From: &ast.UnitType{}, To: &ast.TypeVar{Name: "S"}}},
+				To:   &ast.RecType{Var: "T", Body: &ast.SumType{Left: &ast.IntType{}, Right: &ast.TypeVar{Name: "T"}}},
+			},
+			expected: "(mu S. Unit -> S) -> mu T. Int + T",
+		},
+		{
+			name: "lists",
+			input: &ast.ProductType{Elems: []ast.Type{
+				&ast.ListType{Elem: &ast.ListType{Elem: &ast.IntType{}}},
+				&ast.ListType{Elem: &ast.FuncType{From: &ast.IntType{}, To: &ast.BoolType{}}},
+			}},
+			expected: "List (List Int) * List (Int -> Bool)",
+		},
+		{
+			name: "records and variants",
+			input: &ast.RecordType{Fields: []ast.Field{
+				{Label: "f", Type: &ast.FuncType{From: &ast.IntType{}, To: &ast.IntType{}}},
+				{Label: "v", Type: &ast.VariantType{Fields: []ast.Field{{Label: "a", Type: &ast.IntType{}}}}},
+			}},
+			expected: "{f: Int -> Int, v: <a: Int>}",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sprint(t, &printer.DefaultConfig, tt.input); got != tt.expected {
+				t.Errorf("got %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFprintWidth(t *testing.T) {
+	input := `letrec fib : Int -> Int = \n:Int. if lt n 2 then n else add (fib (sub n 1)) (fib (sub n 2))
+let pair = {first = case inl 1 as Int + Bool of inl a => a | inr b => 0, second = (1, 2)}
+let x = 1 in fib x`
+
+	for _, tt := range []struct {
+		name     string
+		config   printer.Config
+		expected string
+	}{
+		{
+			name:   "width 80",
+			config: printer.Config{Width: 80, Indent: 2},
+			expected: `letrec fib : Int -> Int = \n:Int.
+  if lt n 2 then n else add (fib (sub n 1)) (fib (sub n 2))
+let pair =
+  {first = case inl 1 as Int + Bool of inl a => a | inr b => 0, second = (1, 2)}
+let x = 1 in fib x
+`,
+		},
+		{
+			name:   "width 30",
+			config: printer.Config{Width: 30, Indent: 4},
+			expected: `letrec fib : Int -> Int = \n:Int.
+    if lt n 2
+        then n
+        else add
+            (fib (sub n 1))
+            (fib (sub n 2))
+let pair =
+    {
+        first =
+            case inl 1
+                as Int + Bool of
+                inl a => a
+                | inr b => 0,
+        second = (1, 2)
+    }
+let x = 1 in fib x
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := parser.ParseProgram(input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := sprint(t, &tt.config, prog); got != tt.expected {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFprintTyped(t *testing.T) {
+	prog, err := parser.ParseProgram("let inc = \\n. add n 1\n(\\f. \\x. f (f x)) inc 0")
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	typedProg, err := types.CheckProgram(prog)
+	if err != nil {
+		t.Fatalf("type error: %v", err)
+	}
+
+	expected := `let inc = \n:Int. add n 1
+(\f:Int -> Int. \x:Int. f (f x)) inc 0
+`
+	if got := sprint(t, &printer.DefaultConfig, typedProg); got != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "definitions",
+			input:    "let inc:Int->Int=add 1\n\n\n\nlet twice = \\f:Int->Int.\\x:Int.f(f x)\ntwice   (inc) 1",
+			expected: "let inc : Int -> Int = add 1\n\nlet twice = \\f:Int -> Int. \\x:Int. f (f x)\ntwice inc 1\n",
+		},
+		{
+			name:     "let expressions",
+			input:    "let x = 1 in let y = case inl 1 as Int + Bool of inl a => add a 100000000000 | inr b => if b then 1111111111111 else 2 in add x y",
+			expected: "let x = 1 in\nlet y =\n  case inl 1 as Int + Bool of\n    inl a => add a 100000000000\n    | inr b => if b then 1111111111111 else 2 in\nadd x y\n",
+		},
+		{
+			name:     "data types",
+			input:    "type Shape = | Circle Int | Rect Int Int\nlet c = Circle 1\ntype Tree = Leaf | Node Tree (List Int) (Int -> Int) Tree | Labelled String Shape Tree Tree Tree\nmatch c with Circle 0 -> \"zero\" | Circle r -> \"circle\" | Rect w h -> \"rect\"",
+			expected: "type Shape = Circle Int | Rect Int Int\nlet c = Circle 1\ntype Tree =\n  Leaf\n  | Node Tree (List Int) (Int -> Int) Tree\n  | Labelled String Shape Tree Tree Tree\nmatch c with Circle 0 -> \"zero\" | Circle r -> \"circle\" | Rect w h -> \"rect\"\n",
+		},
+		{
+			name:     "negation of literals",
+			input:    "f (- 1) (-(2.5)) (-1) (- -1)",
+			expected: "f (- 1) (- 2.5) -1 (-(-1))\n",
+		},
+		{
+			name: "comments",
+			input: `-- Numbers
+{- A block
+   comment -}
+let inc : Int -> Int = add 1 -- increment
+
+
+-- twice applies f two times
+let twice = \f:Int->Int. {- body -} \x:Int. f (f x)
+let f = -- a function
+  \x. x
+
+twice inc (f 1) -- result
+-- end
+`,
+			expected: `-- Numbers
+{- A block
+   comment -}
+let inc : Int -> Int = add 1 -- increment
+
+-- twice applies f two times
+let twice = \f:Int -> Int. {- body -} \x:Int. f (f x)
+let f =
+  -- a function
+  \x. x
+
+twice inc (f 1) -- result
+-- end
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := printer.Format(tt.input)
+			if err != nil {
+				t.Fatalf("format error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+
+			again, err := printer.Format(got)
+			if err != nil {
+				t.Fatalf("format error on formatted code: %v", err)
+			}
+			if again != got {
+				t.Errorf("formatting is not idempotent:\n%s\nthen:\n%s", got, again)
+			}
+		})
+	}
+}
+
+func TestFormatUnicode(t *testing.T) {
+	ascii := "let id : forall A. A -> A = /\\A. \\x:A. x\nlet f' = \\x₁:Int -> Int. x₁\nf' (id [Int -> Int] (\\α. α)) 1\n"
+	unicode := "let id : ∀A. A → A = ΛA. λx:A. x\nlet f' = λx₁:Int → Int. x₁\nf' (id [Int → Int] (λα. α)) 1\n"
+
+	for _, tt := range []struct {
+		name     string
+		config   printer.Config
+		input    string
+		expected string
+	}{
+		{
+			name:     "ascii to unicode",
+			config:   printer.Config{Width: 80, Indent: 2, Unicode: true},
+			input:    ascii,
+			expected: unicode,
+		},
+		{
+			name:     "unicode to ascii",
+			config:   printer.DefaultConfig,
+			input:    unicode,
+			expected: ascii,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.Format(tt.input)
+			if err != nil {
+				t.Fatalf("format error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+
+			again, err := tt.config.Format(got)
+			if err != nil {
+				t.Fatalf("format error on formatted code: %v", err)
+			}
+			if again != got {
+				t.Errorf("formatting is not idempotent:\n%s\nthen:\n%s", got, again)
+			}
+		})
+	}
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	if _, err := printer.Format(`let x = in x`); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func sprint(t *testing.T, config *printer.Config, node any) string {
+	t.Helper()
+	var b strings.Builder
+	if err := config.Fprint(&b, node); err != nil {
+		t.Fatalf("print error: %v", err)
+	}
+	return b.String()
+}
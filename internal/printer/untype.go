@@ -0,0 +1,124 @@
+package printer
+
+import (
+	"fmt"
+
+	"github.com/shota3506/gostlc/internal/ast"
+)
+
+// untypeProgram returns the source form of a type-checked program.
+func untypeProgram(prog *ast.TypedProgram) *ast.Program {
+	decls := make([]*ast.Decl, len(prog.Decls))
+	for i, decl := range prog.Decls {
+		decls[i] = &ast.Decl{
+			Pos:   decl.Pos,
+			End:   decl.End,
+			Name:  decl.Name,
+			Value: untype(decl.Value),
+		}
+	}
+	return &ast.Program{Types: prog.Types, Decls: decls, Main: untype(prog.Main)}
+}
+
+// untype returns the source form of a typed expression.
+// Abstractions keep their inferred parameter types, and injections and variants their types.
+func untype(e ast.TypedExpr) ast.Expr {
+	switch e := e.(type) {
+	case *ast.TypedVarExpr:
+		return &e.VarExpr
+	case *ast.TypedBoolExpr:
+		return &e.BoolExpr
+	case *ast.TypedIntExpr:
+		return &e.IntExpr
+	case *ast.TypedUnitExpr:
+		return &e.UnitExpr
+	case *ast.TypedStringExpr:
+		return &e.StringExpr
+	case *ast.TypedCharExpr:
+		return &e.CharExpr
+	case *ast.TypedFloatExpr:
+		return &e.FloatExpr
+	case *ast.TypedAbsExpr:
+		return &ast.AbsExpr{Pos: e.Pos, End: e.End, Param: e.Param, ParamType: e.ParamType, Body: untype(e.Body)}
+	case *ast.TypedAppExpr:
+		return &ast.AppExpr{Pos: e.Pos, End: e.End, Func: untype(e.Func), Arg: untype(e.Arg)}
+	case *ast.TypedIfExpr:
+		return &ast.IfExpr{Pos: e.Pos, End: e.End, Cond: untype(e.Cond), Then: untype(e.Then), Else: untype(e.Else)}
+	case *ast.TypedLetExpr:
+		return &ast.LetExpr{Pos: e.Pos, End: e.End, Name: e.Name, Value: untype(e.Value), Body: untype(e.Body)}
+	case *ast.TypedFixExpr:
+		return &ast.FixExpr{Pos: e.Pos, End: e.End, Func: untype(e.Func)}
+	case *ast.TypedTupleExpr:
+		elems := make([]ast.Expr, len(e.Elems))
+		for i, elem := range e.Elems {
+			elems[i] = untype(elem)
+		}
+		return &ast.TupleExpr{Pos: e.Pos, End: e.End, Elems: elems}
+	case *ast.TypedProjExpr:
+		return &ast.ProjExpr{Pos: e.Pos, End: e.End, Tuple: untype(e.Tuple), Index: e.Index}
+	case *ast.TypedInjExpr:
+		return &ast.InjExpr{Pos: e.Pos, End: e.End, Left: e.Left, Value: untype(e.Value), Type: e.Type()}
+	case *ast.TypedCaseExpr:
+		return &ast.CaseExpr{
+			Pos:       e.Pos,
+			End:       e.End,
+			Scrutinee: untype(e.Scrutinee),
+			LeftVar:   e.LeftVar,
+			Left:      untype(e.Left),
+			RightVar:  e.RightVar,
+			Right:     untype(e.Right),
+		}
+	case *ast.TypedNilExpr:
+		return &ast.NilExpr{Pos: e.Pos, End: e.End, ElemType: e.Type().(*ast.ListType).Elem}
+	case *ast.TypedListExpr:
+		elems := make([]ast.Expr, len(e.Elems))
+		for i, elem := range e.Elems {
+			elems[i] = untype(elem)
+		}
+		return &ast.ListExpr{Pos: e.Pos, End: e.End, Elems: elems}
+	case *ast.TypedListCaseExpr:
+		return &ast.ListCaseExpr{
+			Pos:       e.Pos,
+			End:       e.End,
+			Scrutinee: untype(e.Scrutinee),
+			Nil:       untype(e.Nil),
+			HeadVar:   e.HeadVar,
+			TailVar:   e.TailVar,
+			Cons:      untype(e.Cons),
+		}
+	case *ast.TypedRecordExpr:
+		fields := make([]ast.RecordField, len(e.Fields))
+		for i, field := range e.Fields {
+			fields[i] = ast.RecordField{Label: field.Label, Value: untype(field.Value)}
+		}
+		return &ast.RecordExpr{Pos: e.Pos, End: e.End, Fields: fields}
+	case *ast.TypedRecordProjExpr:
+		return &ast.RecordProjExpr{Pos: e.Pos, End: e.End, Record: untype(e.Record), Label: e.Label}
+	case *ast.TypedVariantExpr:
+		return &ast.VariantExpr{Pos: e.Pos, End: e.End, Label: e.Label, Value: untype(e.Value), Type: e.Type()}
+	case *ast.TypedVariantCaseExpr:
+		branches := make([]ast.VariantBranch, len(e.Branches))
+		for i, b := range e.Branches {
+			branches[i] = ast.VariantBranch{Pos: b.Pos, End: b.End, Label: b.Label, Var: b.Var, Body: untype(b.Body)}
+		}
+		return &ast.VariantCaseExpr{Pos: e.Pos, End: e.End, Scrutinee: untype(e.Scrutinee), Branches: branches}
+	case *ast.TypedMatchExpr:
+		arms := make([]ast.MatchArm, len(e.Arms))
+		for i, arm := range e.Arms {
+			arms[i] = ast.MatchArm{Pos: arm.Pos, End: arm.End, Pattern: arm.Pattern, Body: untype(arm.Body)}
+		}
+		return &ast.MatchExpr{Pos: e.Pos, End: e.End, Scrutinee: untype(e.Scrutinee), Arms: arms}
+	case *ast.TypedTyAbsExpr:
+		return &ast.TyAbsExpr{Pos: e.Pos, End: e.End, TypeVar: e.TypeVar, Body: untype(e.Body)}
+	case *ast.TypedTyAppExpr:
+		return &ast.TyAppExpr{Pos: e.Pos, End: e.End, Func: untype(e.Func), TypeArg: e.TypeArg}
+	case *ast.TypedFoldExpr:
+		return &ast.FoldExpr{Pos: e.Pos, End: e.End, Type: e.Type(), Value: untype(e.Value)}
+	case *ast.TypedUnfoldExpr:
+		return &ast.UnfoldExpr{Pos: e.Pos, End: e.End, Type: e.RecType, Value: untype(e.Value)}
+	case *ast.TypedErrorExpr:
+		return &ast.ErrorExpr{Pos: e.Pos, End: e.End}
+	default:
+		panic(fmt.Sprintf("printer: unexpected typed expression %T", e))
+	}
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// escapes maps the character after a backslash in a string or character literal to the character it stands for.
+// Any other character may be written as \u{hex} with its code point in hexadecimal.
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+}
+
+// Quote returns the string literal of s, in double quotes.
+func Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		writeEscaped(&b, r, '"')
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// QuoteChar returns the character literal of r, in single quotes.
+func QuoteChar(r rune) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	writeEscaped(&b, r, '\'')
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func writeEscaped(b *strings.Builder, r rune, quote rune) {
+	switch {
+	case r == quote || r == '\\':
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	case r == '\n':
+		b.WriteString(`\n`)
+	case r == '\t':
+		b.WriteString(`\t`)
+	case r == '\r':
+		b.WriteString(`\r`)
+	case r == 0:
+		b.WriteString(`\0`)
+	case !unicode.IsPrint(r):
+		fmt.Fprintf(b, `\u{%x}`, r)
+	default:
+		b.WriteRune(r)
+	}
+}
+
+// Unquote returns the characters of the string or character literal lit, written as in source code with its quotes.
+func Unquote(lit string) (string, error) {
+	if len(lit) < 2 || lit[0] != lit[len(lit)-1] || (lit[0] != '"' && lit[0] != '\'') {
+		return "", errors.New("missing quotes")
+	}
+	s := lit[1 : len(lit)-1]
+
+	var b strings.Builder
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		if r != '\\' {
+			b.WriteRune(r)
+			continue
+		}
+		r, n, err := unescapePrefix(s)
+		if err != nil {
+			return "", err
+		}
+		s = s[n:]
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+// unescapePrefix returns the character of the escape sequence at the start of s after its backslash,
+// and the length of the sequence in bytes.
+func unescapePrefix(s string) (rune, int, error) {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return 0, 0, errors.New("unterminated escape sequence")
+	}
+	if e, ok := escapes[r]; ok {
+		return e, size, nil
+	}
+	if r != 'u' {
+		return 0, 0, fmt.Errorf("unknown escape sequence: \\%c", r)
+	}
+
+	// \u{hex}
+	end := strings.IndexByte(s, '}')
+	if !strings.HasPrefix(s, "u{") || end < 0 {
+		return 0, 0, errors.New("malformed escape sequence: expected \\u{hex}")
+	}
+	code, err := strconv.ParseUint(s[2:end], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, 0, fmt.Errorf("invalid code point in escape sequence: \\%s", s[:end+1])
+	}
+	return rune(code), end + 1, nil
+}
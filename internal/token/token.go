@@ -1,24 +1,80 @@
 package token
 
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
 type TokenKind int
 
 const (
-	TokenKindEOF      TokenKind = iota
-	TokenKindIdent              // x, y, foo
-	TokenKindInt                // 42, 0
-	TokenKindTrue               // true
-	TokenKindFalse              // false
-	TokenKindIf                 // if
-	TokenKindThen               // then
-	TokenKindElse               // else
-	TokenKindBoolType           // Bool (type)
-	TokenKindIntType            // Int (type)
-	TokenKindLambda             // \
-	TokenKindDot                // .
-	TokenKindColon              // :
-	TokenKindArrow              // ->
-	TokenKindLParen             // (
-	TokenKindRParen             // )
+	TokenKindEOF          TokenKind = iota
+	TokenKindIdent                  // x, y, foo
+	TokenKindInt                    // 42, 0
+	TokenKindFloat                  // 1.5, 0.0
+	TokenKindString                 // "hello\n"
+	TokenKindChar                   // 'a', '\n'
+	TokenKindTrue                   // true
+	TokenKindFalse                  // false
+	TokenKindIf                     // if
+	TokenKindThen                   // then
+	TokenKindElse                   // else
+	TokenKindLet                    // let
+	TokenKindIn                     // in
+	TokenKindLetrec                 // letrec
+	TokenKindFix                    // fix
+	TokenKindInl                    // inl
+	TokenKindInr                    // inr
+	TokenKindAs                     // as
+	TokenKindCase                   // case
+	TokenKindOf                     // of
+	TokenKindForall                 // forall
+	TokenKindNil                    // nil
+	TokenKindType                   // type
+	TokenKindMatch                  // match
+	TokenKindWith                   // with
+	TokenKindMu                     // mu
+	TokenKindFold                   // fold
+	TokenKindUnfold                 // unfold
+	TokenKindBoolType               // Bool (type)
+	TokenKindIntType                // Int (type)
+	TokenKindUnitType               // Unit (type)
+	TokenKindStringType             // String (type)
+	TokenKindCharType               // Char (type)
+	TokenKindFloatType              // Float (type)
+	TokenKindListType               // List (type)
+	TokenKindLambda                 // \
+	TokenKindTyLambda               // /\
+	TokenKindDot                    // .
+	TokenKindColon                  // :
+	TokenKindColonColon             // ::
+	TokenKindArrow                  // ->
+	TokenKindEqual                  // =
+	TokenKindStar                   // *
+	TokenKindComma                  // ,
+	TokenKindPlus                   // +
+	TokenKindBar                    // |
+	TokenKindFatArrow               // =>
+	TokenKindLBrace                 // {
+	TokenKindRBrace                 // }
+	TokenKindLAngle                 // <
+	TokenKindRAngle                 // >
+	TokenKindLBracket               // [
+	TokenKindRBracket               // ]
+	TokenKindLParen                 // (
+	TokenKindRParen                 // )
+	TokenKindMinus                  // -
+	TokenKindSlash                  // /
+	TokenKindPercent                // %
+	TokenKindEqualEqual             // ==
+	TokenKindNotEqual               // !=
+	TokenKindLessEqual              // <=
+	TokenKindGreaterEqual           // >=
+	TokenKindAndAnd                 // &&
+	TokenKindOrOr                   // ||
+	TokenKindBang                   // !
+	TokenKindComment                // -- comment or {- comment -}
 )
 
 func (k TokenKind) String() string {
@@ -29,6 +85,12 @@ func (k TokenKind) String() string {
 		return "Ident"
 	case TokenKindInt:
 		return "Int"
+	case TokenKindFloat:
+		return "Float"
+	case TokenKindString:
+		return "String"
+	case TokenKindChar:
+		return "Char"
 	case TokenKindTrue:
 		return "True"
 	case TokenKindFalse:
@@ -39,22 +101,116 @@ func (k TokenKind) String() string {
 		return "Then"
 	case TokenKindElse:
 		return "Else"
+	case TokenKindLet:
+		return "Let"
+	case TokenKindIn:
+		return "In"
+	case TokenKindLetrec:
+		return "Letrec"
+	case TokenKindFix:
+		return "Fix"
+	case TokenKindInl:
+		return "Inl"
+	case TokenKindInr:
+		return "Inr"
+	case TokenKindAs:
+		return "As"
+	case TokenKindCase:
+		return "Case"
+	case TokenKindOf:
+		return "Of"
+	case TokenKindForall:
+		return "Forall"
+	case TokenKindNil:
+		return "Nil"
+	case TokenKindType:
+		return "Type"
+	case TokenKindMatch:
+		return "Match"
+	case TokenKindWith:
+		return "With"
+	case TokenKindMu:
+		return "Mu"
+	case TokenKindFold:
+		return "Fold"
+	case TokenKindUnfold:
+		return "Unfold"
 	case TokenKindBoolType:
 		return "BoolType"
 	case TokenKindIntType:
 		return "IntType"
+	case TokenKindUnitType:
+		return "UnitType"
+	case TokenKindStringType:
+		return "StringType"
+	case TokenKindCharType:
+		return "CharType"
+	case TokenKindFloatType:
+		return "FloatType"
+	case TokenKindListType:
+		return "ListType"
 	case TokenKindLambda:
 		return "Lambda"
+	case TokenKindTyLambda:
+		return "TyLambda"
 	case TokenKindDot:
 		return "Dot"
 	case TokenKindColon:
 		return "Colon"
+	case TokenKindColonColon:
+		return "ColonColon"
 	case TokenKindArrow:
 		return "Arrow"
+	case TokenKindEqual:
+		return "Equal"
+	case TokenKindStar:
+		return "Star"
+	case TokenKindComma:
+		return "Comma"
+	case TokenKindPlus:
+		return "Plus"
+	case TokenKindBar:
+		return "Bar"
+	case TokenKindFatArrow:
+		return "FatArrow"
+	case TokenKindLBrace:
+		return "LBrace"
+	case TokenKindRBrace:
+		return "RBrace"
+	case TokenKindLAngle:
+		return "LAngle"
+	case TokenKindRAngle:
+		return "RAngle"
+	case TokenKindLBracket:
+		return "LBracket"
+	case TokenKindRBracket:
+		return "RBracket"
 	case TokenKindLParen:
 		return "LParen"
 	case TokenKindRParen:
 		return "RParen"
+	case TokenKindMinus:
+		return "Minus"
+	case TokenKindSlash:
+		return "Slash"
+	case TokenKindPercent:
+		return "Percent"
+	case TokenKindEqualEqual:
+		return "EqualEqual"
+	case TokenKindNotEqual:
+		return "NotEqual"
+	case TokenKindLessEqual:
+		return "LessEqual"
+	case TokenKindGreaterEqual:
+		return "GreaterEqual"
+	case TokenKindAndAnd:
+		return "AndAnd"
+	case TokenKindOrOr:
+		return "OrOr"
+	case TokenKindBang:
+		return "Bang"
+	case TokenKindComment:
+		return "Comment"
 	default:
 		return "Unknown"
 	}
@@ -64,9 +220,52 @@ type Token struct {
 	Kind  TokenKind
 	Value string
 	Pos   Position
+
+	// Trivia holds the comment tokens between the previous token and this one in source order.
+	// The parser ignores them; they are kept for tools such as formatters.
+	Trivia []Token
+}
+
+// End returns the position just after the last character of the token.
+func (t Token) End() Position {
+	end := t.Pos
+	end.Offset += len(t.Value)
+	i := strings.LastIndexByte(t.Value, '\n')
+	if i < 0 {
+		end.Column += utf8.RuneCountInString(t.Value)
+		return end
+	}
+	// A block comment may span several lines
+	end.Line += strings.Count(t.Value, "\n")
+	end.Column = 1 + utf8.RuneCountInString(t.Value[i+1:])
+	return end
 }
 
 type Position struct {
-	Line   int // 1 based line number
-	Column int // 1 based column number
+	Filename string // name of the source, empty if unknown
+	Offset   int    // 0 based byte offset
+	Line     int    // 1 based line number
+	Column   int    // 1 based column number
+}
+
+// String returns the position as file:line:column, or line:column if the file name is unknown.
+func (p Position) String() string {
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+// Before reports whether p comes before q in the source.
+func (p Position) Before(q Position) bool {
+	if p.Line != q.Line {
+		return p.Line < q.Line
+	}
+	return p.Column < q.Column
+}
+
+// Span is the range of source text from Start up to but not including End.
+type Span struct {
+	Start Position
+	End   Position
 }
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"github.com/shota3506/gostlc/internal/ast"
+)
+
+// CheckBidirectional performs bidirectional type checking and returns a typed AST.
+// Expected types are pushed into abstractions, conditionals, let bodies, tuples and lists,
+// so that a type error is reported at the innermost subexpression that disagrees
+// and abstractions in checking positions take their parameter types from the expected type.
+func CheckBidirectional(expr ast.Expr) (ast.TypedExpr, error) {
+	c := newChecker()
+	c.bidirectional = true
+	return c.checkRoot(expr)
+}
+
+// CheckProgramBidirectional performs bidirectional type checking of a program and returns a typed program.
+func CheckProgramBidirectional(prog *ast.Program) (*ast.TypedProgram, error) {
+	c := newChecker()
+	c.bidirectional = true
+	return c.checkProgram(prog)
+}
+
+// checkExpected checks expr against the type expected by the enclosing construct.
+// In bidirectional mode a mismatch is reported at the subexpression where it arises instead of at the construct.
+// A type error is recorded and expr is replaced by a placeholder.
+func (c *checker) checkExpected(exp expectation, expr ast.Expr, expected ast.Type, g *Gamma) ast.TypedExpr {
+	if c.bidirectional {
+		return c.check(expr, expected, exp, g)
+	}
+
+	typedExpr := c.checkTyped(expr, g)
+	if err := c.expect(exp, expr.Span(), expected, typedExpr.Type()); err != nil {
+		return c.report(expr, err)
+	}
+	return typedExpr
+}
+
+// check is the checking judgment: expr must have the expected type.
+// A type error is recorded and expr is replaced by a placeholder.
+func (c *checker) check(expr ast.Expr, expected ast.Type, exp expectation, g *Gamma) ast.TypedExpr {
+	typedExpr, err := c.checkAgainst(expr, expected, exp, g)
+	if err != nil {
+		return c.report(expr, err)
+	}
+	return typedExpr
+}
+
+// checkAgainst dispatches on the checking rules of expr.
+// Expressions without a checking rule are inferred and then unified with the expected type.
+func (c *checker) checkAgainst(expr ast.Expr, expected ast.Type, exp expectation, g *Gamma) (ast.TypedExpr, error) {
+	switch e := expr.(type) {
+	case *ast.AbsExpr:
+		if ft, ok := c.subst.resolve(expected).(*ast.FuncType); ok {
+			return c.checkAbsAgainst(e, ft, exp, g)
+		}
+	case *ast.IfExpr:
+		return c.checkIfAgainst(e, expected, exp, g)
+	case *ast.LetExpr:
+		return c.checkLetAgainst(e, expected, exp, g)
+	case *ast.TupleExpr:
+		if pt, ok := c.subst.resolve(expected).(*ast.ProductType); ok && len(pt.Elems) == len(e.Elems) {
+			return c.checkTupleAgainst(e, pt, exp, g)
+		}
+	case *ast.ListExpr:
+		if lt, ok := c.subst.resolve(expected).(*ast.ListType); ok {
+			return c.checkListAgainst(e, lt, exp, g)
+		}
+	}
+
+	typedExpr := c.checkTyped(expr, g)
+	if err := c.expect(exp.at(expr.Span()), expr.Span(), expected, typedExpr.Type()); err != nil {
+		return nil, err
+	}
+	return typedExpr, nil
+}
+
+func (c *checker) checkAbsAgainst(expr *ast.AbsExpr, expected *ast.FuncType, exp expectation, g *Gamma) (ast.TypedExpr, error) {
+	// An unannotated parameter takes the expected parameter type
+	paramType := expected.From
+	if expr.ParamType != nil {
+		t, err := c.paramType(expr)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.expect(exp.at(expr.Span()), expr.Span(), expected.From, t); err != nil {
+			return nil, err
+		}
+		paramType = t
+	}
+
+	typedBody := c.check(expr.Body, expected.To, exp, g.Bind(expr.Param, Mono(paramType)))
+
+	funcType := &ast.FuncType{
+		From: paramType,
+		To:   typedBody.Type(),
+	}
+	return ast.NewTypedAbsExpr(funcType, expr.Span(), expr.Param, paramType, typedBody), nil
+}
+
+func (c *checker) checkIfAgainst(expr *ast.IfExpr, expected ast.Type, exp expectation, g *Gamma) (ast.TypedExpr, error) {
+	typedCond := c.checkCond(expr, g)
+	typedThen := c.check(expr.Then, expected, exp, g)
+	typedElse := c.check(expr.Else, expected, exp, g)
+	return ast.NewTypedIfExpr(expr.Span(), typedCond, typedThen, typedElse), nil
+}
+
+func (c *checker) checkLetAgainst(expr *ast.LetExpr, expected ast.Type, exp expectation, g *Gamma) (ast.TypedExpr, error) {
+	typedValue := c.checkBinding(expr.Span(), expr.Type, expr.Value, g)
+	typedBody := c.check(expr.Body, expected, exp, g.Bind(expr.Name, c.generalize(typedValue.Type())))
+	return ast.NewTypedLetExpr(expr.Span(), expr.Name, typedValue, typedBody), nil
+}
+
+func (c *checker) checkTupleAgainst(expr *ast.TupleExpr, expected *ast.ProductType, exp expectation, g *Gamma) (ast.TypedExpr, error) {
+	typedElems := make([]ast.TypedExpr, len(expr.Elems))
+	elemTypes := make([]ast.Type, len(expr.Elems))
+	for i, elem := range expr.Elems {
+		typedElem := c.check(elem, expected.Elems[i], exp, g)
+		typedElems[i] = typedElem
+		elemTypes[i] = typedElem.Type()
+	}
+
+	return ast.NewTypedTupleExpr(&ast.ProductType{Elems: elemTypes}, expr.Span(), typedElems), nil
+}
+
+func (c *checker) checkListAgainst(expr *ast.ListExpr, expected *ast.ListType, exp expectation, g *Gamma) (ast.TypedExpr, error) {
+	typedElems := make([]ast.TypedExpr, len(expr.Elems))
+	for i, elem := range expr.Elems {
+		typedElems[i] = c.check(elem, expected.Elem, exp, g)
+	}
+
+	return ast.NewTypedListExpr(&ast.ListType{Elem: expected.Elem}, expr.Span(), typedElems), nil
+}
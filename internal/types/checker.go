@@ -1,126 +1,997 @@
 package types
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/shota3506/gostlc/internal/ast"
 	"github.com/shota3506/gostlc/internal/builtin"
+	"github.com/shota3506/gostlc/internal/token"
 )
 
-// Check performs type checking and returns a typed AST.
+// checker infers types with unification, accumulating solved type variables in subst.
+type checker struct {
+	subst  Subst
+	nextID int
+
+	// level is the let nesting depth, and levels records the level each type variable was introduced at.
+	// A type variable may be generalized only at a binding nested deeper than every reference to it.
+	level  int
+	levels map[int]int
+
+	// bidirectional pushes expected types into subexpressions instead of comparing after inference.
+	bidirectional bool
+
+	// intMode is the semantics of integers the program is checked for.
+	intMode builtin.IntMode
+
+	// typeVars is the stack of type variables bound by the enclosing type abstractions.
+	typeVars []typeVarBinding
+
+	// dataTypes and constructors are the algebraic data types declared in the program and their constructors.
+	dataTypes    map[string]*dataType
+	constructors map[string]*constructor
+
+	// errors collects the type errors found so far. Checking continues past an error
+	// with an expression of ErrorType in place of the one that failed.
+	errors ErrorList
+
+	// warnings collects the problems found that do not make the program ill-typed.
+	warnings ErrorList
+}
+
+// dataType is an algebraic data type declared in the program.
+type dataType struct {
+	name         string
+	constructors []*constructor
+}
+
+// constructor is a constructor of a data type with the types of its arguments.
+type constructor struct {
+	name   string
+	params []ast.Type
+	typ    *dataType
+}
+
+// typeVarBinding maps the name of a type variable in the source to the rigid type variable it stands for.
+// The two differ only when an inner type abstraction shadows an outer one of the same name.
+type typeVarBinding struct {
+	name  string
+	rigid string
+}
+
+func newChecker() *checker {
+	return &checker{
+		subst:        Subst{},
+		levels:       map[int]int{},
+		dataTypes:    map[string]*dataType{},
+		constructors: map[string]*constructor{},
+	}
+}
+
+// fresh returns a new unsolved type variable.
+func (c *checker) fresh() *ast.MetaVar {
+	m := &ast.MetaVar{ID: c.nextID}
+	c.levels[m.ID] = c.level
+	c.nextID++
+	return m
+}
+
+// Config controls type checking.
+type Config struct {
+	// IntMode is the semantics of integers the program is to be evaluated with.
+	// Integer literals out of the range of Int are type errors unless integers are arbitrary-precision.
+	IntMode builtin.IntMode
+
+	// Bidirectional selects bidirectional type checking, as CheckBidirectional and CheckProgramBidirectional do.
+	Bidirectional bool
+}
+
+func (c *Config) newChecker() *checker {
+	checker := newChecker()
+	checker.intMode = c.IntMode
+	checker.bidirectional = c.Bidirectional
+	return checker
+}
+
+// Check type checks expr as Check or CheckBidirectional does, for the integers of c.
+func (c *Config) Check(expr ast.Expr) (ast.TypedExpr, error) {
+	return c.newChecker().checkRoot(expr)
+}
+
+// CheckProgram type checks prog as CheckProgram or CheckProgramBidirectional does, for the integers of c.
+func (c *Config) CheckProgram(prog *ast.Program) (*ast.TypedProgram, error) {
+	return c.newChecker().checkProgram(prog)
+}
+
+// Check performs type inference and returns a typed AST.
+// Lambda parameters without type annotations are inferred.
+// All type errors found are returned as an ErrorList, along with a typed AST
+// in which the expressions that failed to type check have ErrorType.
 func Check(expr ast.Expr) (ast.TypedExpr, error) {
+	return newChecker().checkRoot(expr)
+}
+
+// CheckProgram performs type checking of a program and returns a typed program.
+// Each top-level definition is visible to the definitions following it and to the main expression,
+// and the data types and their constructors are visible throughout the program.
+// Warnings, such as redundant match arms, are returned in the Warnings of the typed program.
+func CheckProgram(prog *ast.Program) (*ast.TypedProgram, error) {
+	return newChecker().checkProgram(prog)
+}
+
+func (c *checker) checkRoot(expr ast.Expr) (ast.TypedExpr, error) {
+	typedExpr := c.checkTyped(expr, rootGamma())
+	return c.normalizeExprs(c.subst.ApplyExpr(typedExpr))[0], c.err()
+}
+
+func (c *checker) checkProgram(prog *ast.Program) (*ast.TypedProgram, error) {
+	g := c.declareTypes(prog.Types, rootGamma())
+
+	decls := make([]*ast.TypedDecl, 0, len(prog.Decls))
+	for _, decl := range prog.Decls {
+		typedValue := c.checkBinding(decl.Span(), decl.Type, decl.Value, g)
+		decls = append(decls, &ast.TypedDecl{
+			Pos:   decl.Pos,
+			End:   decl.End,
+			Name:  decl.Name,
+			Value: typedValue,
+		})
+		g = g.Bind(decl.Name, c.generalize(typedValue.Type()))
+	}
+
+	typedMain := c.checkTyped(prog.Main, g)
+
+	// The type variables are named first in the type of the main expression, which is printed with the result
+	exprs := []ast.TypedExpr{c.subst.ApplyExpr(typedMain)}
+	for _, decl := range decls {
+		exprs = append(exprs, c.subst.ApplyExpr(decl.Value))
+	}
+	exprs = c.normalizeExprs(exprs...)
+	for i, decl := range decls {
+		decl.Value = exprs[i+1]
+	}
+	var warnings []error
+	if len(c.warnings) > 0 {
+		warnings = c.warnings
+	}
+	return &ast.TypedProgram{
+		Types:    prog.Types,
+		Decls:    decls,
+		Main:     exprs[0],
+		Warnings: warnings,
+	}, c.err()
+}
+
+// declareTypes records the data types of a program and binds their constructors in g.
+// A constructor with arguments is a function from the types of its arguments to its data type.
+func (c *checker) declareTypes(decls []*ast.TypeDecl, g *Gamma) *Gamma {
+	// The data types are declared before their constructors so that they may refer to each other
+	for _, decl := range decls {
+		if _, ok := c.dataTypes[decl.Name]; ok {
+			c.errors = append(c.errors, &DuplicateDeclarationError{
+				Pos:  decl.Pos,
+				End:  decl.End,
+				Kind: "type",
+				Name: decl.Name,
+			})
+			continue
+		}
+		c.dataTypes[decl.Name] = &dataType{name: decl.Name}
+	}
+
+	for _, decl := range decls {
+		dt := c.dataTypes[decl.Name]
+		for _, con := range decl.Constructors {
+			if _, ok := c.constructors[con.Name]; ok {
+				c.errors = append(c.errors, &DuplicateDeclarationError{
+					Pos:  con.Pos,
+					End:  con.End,
+					Kind: "constructor",
+					Name: con.Name,
+				})
+				continue
+			}
+
+			params := make([]ast.Type, len(con.Params))
+			for i, param := range con.Params {
+				typ, err := c.resolveType(con.Span(), param)
+				if err != nil {
+					c.errors = append(c.errors, err)
+					typ = &ast.ErrorType{}
+				}
+				params[i] = typ
+			}
+
+			k := &constructor{name: con.Name, params: params, typ: dt}
+			dt.constructors = append(dt.constructors, k)
+			c.constructors[con.Name] = k
+
+			var typ ast.Type = &ast.DataType{Name: dt.name}
+			for i := len(params) - 1; i >= 0; i-- {
+				typ = &ast.FuncType{From: params[i], To: typ}
+			}
+			g = g.Bind(con.Name, Mono(typ))
+		}
+	}
+	return g
+}
+
+// report records a type error and returns a placeholder of ErrorType for expr.
+func (c *checker) report(expr ast.Expr, err error) ast.TypedExpr {
+	c.errors = append(c.errors, err)
+	return ast.NewTypedErrorExpr(expr.Span())
+}
+
+func (c *checker) err() error {
+	if len(c.errors) == 0 {
+		return nil
+	}
+	for _, err := range c.errors {
+		c.normalizeError(err)
+	}
+	return c.errors
+}
+
+// isError reports whether t is the type of an expression that failed to type check.
+func (c *checker) isError(t ast.Type) bool {
+	_, ok := c.subst.resolve(t).(*ast.ErrorType)
+	return ok
+}
+
+func rootGamma() *Gamma {
 	root := NewGamma()
 	for ident, typ := range builtin.FunctionTypes {
-		root = root.Bind(ident, typ)
+		root = root.Bind(ident, Generic(typ))
 	}
-	return checkTyped(expr, root)
+	return root
 }
 
-func checkTyped(expr ast.Expr, g *Gamma) (ast.TypedExpr, error) {
+// checkTyped infers the type of expr. A type error is recorded and expr is replaced by a placeholder.
+func (c *checker) checkTyped(expr ast.Expr, g *Gamma) ast.TypedExpr {
+	typedExpr, err := c.infer(expr, g)
+	if err != nil {
+		return c.report(expr, err)
+	}
+	return typedExpr
+}
+
+func (c *checker) infer(expr ast.Expr, g *Gamma) (ast.TypedExpr, error) {
 	switch e := expr.(type) {
 	case *ast.VarExpr:
-		return checkVar(e, g)
+		return c.checkVar(e, g)
 	case *ast.AbsExpr:
-		return checkAbs(e, g)
+		return c.checkAbs(e, g)
 	case *ast.AppExpr:
-		return checkApp(e, g)
+		return c.checkApp(e, g)
 	case *ast.BoolExpr:
 		return ast.NewTypedBoolExpr(e), nil
 	case *ast.IntExpr:
+		if e.Big != nil && c.intMode != builtin.IntBig {
+			return nil, &IntLiteralRangeError{Pos: e.Pos, End: e.End, Value: e.Big.String()}
+		}
 		return ast.NewTypedIntExpr(e), nil
+	case *ast.UnitExpr:
+		return ast.NewTypedUnitExpr(e), nil
+	case *ast.StringExpr:
+		return ast.NewTypedStringExpr(e), nil
+	case *ast.CharExpr:
+		return ast.NewTypedCharExpr(e), nil
+	case *ast.FloatExpr:
+		return ast.NewTypedFloatExpr(e), nil
 	case *ast.IfExpr:
-		return checkIf(e, g)
+		return c.checkIf(e, g)
+	case *ast.LetExpr:
+		return c.checkLet(e, g)
+	case *ast.FixExpr:
+		return c.checkFix(e, g)
+	case *ast.TupleExpr:
+		return c.checkTuple(e, g)
+	case *ast.ProjExpr:
+		return c.checkProj(e, g)
+	case *ast.InjExpr:
+		return c.checkInj(e, g)
+	case *ast.CaseExpr:
+		return c.checkCase(e, g)
+	case *ast.NilExpr:
+		return c.checkNil(e)
+	case *ast.ListExpr:
+		return c.checkList(e, g)
+	case *ast.ListCaseExpr:
+		return c.checkListCase(e, g)
+	case *ast.RecordExpr:
+		return c.checkRecord(e, g)
+	case *ast.RecordProjExpr:
+		return c.checkRecordProj(e, g)
+	case *ast.VariantExpr:
+		return c.checkVariant(e, g)
+	case *ast.VariantCaseExpr:
+		return c.checkVariantCase(e, g)
+	case *ast.MatchExpr:
+		return c.checkMatch(e, g)
+	case *ast.TyAbsExpr:
+		return c.checkTyAbs(e, g)
+	case *ast.TyAppExpr:
+		return c.checkTyApp(e, g)
+	case *ast.FoldExpr:
+		return c.checkFold(e, g)
+	case *ast.UnfoldExpr:
+		return c.checkUnfold(e, g)
+	case *ast.ErrorExpr:
+		// The syntax error has already been reported by the parser
+		return ast.NewTypedErrorExpr(e.Span()), nil
 	default:
 		return nil, &UnknownExprTypeError{
 			Pos:  expr.Position(),
+			End:  expr.Span().End,
 			Expr: expr,
 		}
 	}
 }
 
-func checkVar(expr *ast.VarExpr, g *Gamma) (ast.TypedExpr, error) {
-	typ, ok := g.Lookup(expr.Name)
+func (c *checker) checkVar(expr *ast.VarExpr, g *Gamma) (ast.TypedExpr, error) {
+	// An operator refers to its builtin even where the name is bound otherwise
+	if typ, ok := builtin.FunctionTypes[expr.Name]; ok && expr.Op != "" {
+		return ast.NewTypedVarExpr(c.instantiate(Generic(typ)), expr), nil
+	}
+
+	scheme, ok := g.Lookup(expr.Name)
 	if !ok {
 		return nil, &UndefinedVariableError{
 			Pos:  expr.Pos,
+			End:  expr.End,
 			Name: expr.Name,
 		}
 	}
-	return ast.NewTypedVarExpr(typ, expr), nil
+	return ast.NewTypedVarExpr(c.instantiate(scheme), expr), nil
 }
 
-func checkAbs(expr *ast.AbsExpr, g *Gamma) (ast.TypedExpr, error) {
-	typedBody, err := checkTyped(expr.Body, g.Bind(expr.Param, expr.ParamType))
+func (c *checker) checkAbs(expr *ast.AbsExpr, g *Gamma) (ast.TypedExpr, error) {
+	// An unannotated parameter gets a type variable solved by its uses
+	paramType, err := c.paramType(expr)
 	if err != nil {
 		return nil, err
 	}
 
+	typedBody := c.checkTyped(expr.Body, g.Bind(expr.Param, Mono(paramType)))
+
 	funcType := &ast.FuncType{
-		From: expr.ParamType,
+		From: paramType,
 		To:   typedBody.Type(),
 	}
-	return ast.NewTypedAbsExpr(funcType, expr.Pos, expr.Param, expr.ParamType, typedBody), nil
+	return ast.NewTypedAbsExpr(funcType, expr.Span(), expr.Param, paramType, typedBody), nil
 }
 
-func checkApp(expr *ast.AppExpr, g *Gamma) (ast.TypedExpr, error) {
-	typedFunc, err := checkTyped(expr.Func, g)
+// paramType returns the resolved type annotation of the parameter of expr, or a fresh type variable without one.
+func (c *checker) paramType(expr *ast.AbsExpr) (ast.Type, error) {
+	if expr.ParamType == nil {
+		return c.fresh(), nil
+	}
+	return c.resolveType(expr.Span(), expr.ParamType)
+}
+
+func (c *checker) checkApp(expr *ast.AppExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedFunc := c.checkTyped(expr.Func, g)
+
+	ft, err := c.funcType(expr.Span(), typedFunc.Type())
 	if err != nil {
-		return nil, err
+		// The argument is still checked for the errors it contains
+		c.errors = append(c.errors, err)
+		typedArg := c.checkTyped(expr.Arg, g)
+		return ast.NewTypedAppExpr(&ast.ErrorType{}, expr.Span(), typedFunc, typedArg), nil
 	}
 
-	ft, ok := typedFunc.Type().(*ast.FuncType)
-	if !ok {
+	typedArg := c.checkExpected(expectation{context: "application", span: expr.Span(), origin: expr.Func.Span()}, expr.Arg, ft.From, g)
+
+	return ast.NewTypedAppExpr(ft.To, expr.Span(), typedFunc, typedArg), nil
+}
+
+// funcType returns typ as a function type, refining an unsolved type variable if needed.
+func (c *checker) funcType(span token.Span, typ ast.Type) (*ast.FuncType, error) {
+	switch t := c.subst.resolve(typ).(type) {
+	case *ast.FuncType:
+		return t, nil
+	case *ast.ErrorType:
+		return &ast.FuncType{From: t, To: t}, nil
+	case *ast.MetaVar:
+		ft := &ast.FuncType{From: c.fresh(), To: c.fresh()}
+		if err := c.expect(expectation{context: "application", span: span}, span, ft, t); err != nil {
+			return nil, err
+		}
+		return ft, nil
+	default:
 		return nil, &NotAFunctionError{
-			Pos:  expr.Pos,
-			Type: typedFunc.Type(),
+			Pos:  span.Start,
+			End:  span.End,
+			Type: c.subst.Apply(typ),
 		}
 	}
+}
+
+func (c *checker) checkIf(expr *ast.IfExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedCond := c.checkCond(expr, g)
+
+	typedThen := c.checkTyped(expr.Then, g)
+
+	typedElse := c.checkExpected(expectation{context: "if-else branches", span: expr.Span(), origin: expr.Then.Span()}, expr.Else, typedThen.Type(), g)
+	return ast.NewTypedIfExpr(expr.Span(), typedCond, typedThen, typedElse), nil
+}
+
+// checkCond checks that the condition of expr is a boolean.
+// A type error is recorded and the condition is replaced by a placeholder.
+func (c *checker) checkCond(expr *ast.IfExpr, g *Gamma) ast.TypedExpr {
+	typedCond := c.checkTyped(expr.Cond, g)
 
-	typedArg, err := checkTyped(expr.Arg, g)
+	if c.unify(&ast.BoolType{}, typedCond.Type()) != nil {
+		return c.report(expr.Cond, &InvalidConditionTypeError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: c.subst.Apply(typedCond.Type()),
+		})
+	}
+	return typedCond
+}
+
+func (c *checker) checkLet(expr *ast.LetExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedValue := c.checkBinding(expr.Span(), expr.Type, expr.Value, g)
+
+	typedBody := c.checkTyped(expr.Body, g.Bind(expr.Name, c.generalize(typedValue.Type())))
+	return ast.NewTypedLetExpr(expr.Span(), expr.Name, typedValue, typedBody), nil
+}
+
+// checkBinding checks the bound expression of a let binding against its optional type annotation.
+// The bound expression is checked one level deeper so that its type can be generalized afterwards.
+func (c *checker) checkBinding(span token.Span, annotation ast.Type, value ast.Expr, g *Gamma) ast.TypedExpr {
+	c.level++
+	defer func() { c.level-- }()
+
+	if annotation == nil {
+		return c.checkTyped(value, g)
+	}
+
+	typ, err := c.resolveType(span, annotation)
+	if err != nil {
+		c.errors = append(c.errors, err)
+		return c.checkTyped(value, g)
+	}
+	return c.checkExpected(expectation{context: "let binding", span: span}, value, typ, g)
+}
+
+func (c *checker) checkFix(expr *ast.FixExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedFunc := c.checkTyped(expr.Func, g)
+
+	ft, err := c.funcType(expr.Span(), typedFunc.Type())
 	if err != nil {
 		return nil, err
 	}
 
-	if !ft.From.Equal(typedArg.Type()) {
+	if c.unify(ft.From, ft.To) != nil {
 		return nil, &TypeMismatchError{
 			Pos:      expr.Pos,
-			Expected: ft.From,
-			Actual:   typedArg.Type(),
-			Context:  "application",
+			End:      expr.End,
+			Expected: c.subst.Apply(&ast.FuncType{From: ft.From, To: ft.From}),
+			Actual:   c.subst.Apply(ft),
+			Context:  "fix",
+		}
+	}
+
+	return ast.NewTypedFixExpr(ft.From, expr.Span(), typedFunc), nil
+}
+
+func (c *checker) checkTuple(expr *ast.TupleExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedElems := make([]ast.TypedExpr, len(expr.Elems))
+	elemTypes := make([]ast.Type, len(expr.Elems))
+	for i, elem := range expr.Elems {
+		typedElem := c.checkTyped(elem, g)
+		typedElems[i] = typedElem
+		elemTypes[i] = typedElem.Type()
+	}
+
+	return ast.NewTypedTupleExpr(&ast.ProductType{Elems: elemTypes}, expr.Span(), typedElems), nil
+}
+
+func (c *checker) checkProj(expr *ast.ProjExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedTuple := c.checkTyped(expr.Tuple, g)
+
+	if c.isError(typedTuple.Type()) {
+		return ast.NewTypedProjExpr(&ast.ErrorType{}, expr.Span(), typedTuple, expr.Index), nil
+	}
+
+	// The arity of a tuple cannot be inferred from a projection
+	pt, ok := c.subst.resolve(typedTuple.Type()).(*ast.ProductType)
+	if !ok {
+		return nil, &NotATupleError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: c.subst.Apply(typedTuple.Type()),
+		}
+	}
+
+	if expr.Index < 1 || expr.Index > len(pt.Elems) {
+		return nil, &TupleIndexOutOfRangeError{
+			Pos:   expr.Pos,
+			End:   expr.End,
+			Index: expr.Index,
+			Type:  c.subst.Apply(pt),
+		}
+	}
+
+	return ast.NewTypedProjExpr(pt.Elems[expr.Index-1], expr.Span(), typedTuple, expr.Index), nil
+}
+
+func (c *checker) checkInj(expr *ast.InjExpr, g *Gamma) (ast.TypedExpr, error) {
+	typ, err := c.resolveType(expr.Span(), expr.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	st, ok := typ.(*ast.SumType)
+	if !ok {
+		return nil, &NotASumError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: typ,
+		}
+	}
+
+	expected := st.Right
+	if expr.Left {
+		expected = st.Left
+	}
+	typedValue := c.checkExpected(expectation{context: "injection", span: expr.Span()}, expr.Value, expected, g)
+
+	return ast.NewTypedInjExpr(st, expr.Span(), expr.Left, typedValue), nil
+}
+
+func (c *checker) checkCase(expr *ast.CaseExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedScrutinee := c.checkTyped(expr.Scrutinee, g)
+
+	st := &ast.SumType{Left: c.fresh(), Right: c.fresh()}
+	if c.unify(st, typedScrutinee.Type()) != nil {
+		return nil, &NotASumError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: c.subst.Apply(typedScrutinee.Type()),
 		}
 	}
 
-	return ast.NewTypedAppExpr(ft.To, expr.Pos, typedFunc, typedArg), nil
+	typedLeft := c.checkTyped(expr.Left, g.Bind(expr.LeftVar, Mono(st.Left)))
+
+	exp := expectation{context: "case branches", span: expr.Span(), origin: expr.Left.Span()}
+	typedRight := c.checkExpected(exp, expr.Right, typedLeft.Type(), g.Bind(expr.RightVar, Mono(st.Right)))
+
+	return ast.NewTypedCaseExpr(expr.Span(), typedScrutinee, expr.LeftVar, typedLeft, expr.RightVar, typedRight), nil
 }
 
-func checkIf(expr *ast.IfExpr, g *Gamma) (ast.TypedExpr, error) {
-	typedCond, err := checkTyped(expr.Cond, g)
+func (c *checker) checkNil(expr *ast.NilExpr) (ast.TypedExpr, error) {
+	elemType, err := c.resolveType(expr.Span(), expr.ElemType)
 	if err != nil {
 		return nil, err
 	}
+	return ast.NewTypedNilExpr(&ast.ListType{Elem: elemType}, expr.Span()), nil
+}
+
+func (c *checker) checkList(expr *ast.ListExpr, g *Gamma) (ast.TypedExpr, error) {
+	// The first element determines the type of the other elements
+	var elemType ast.Type = c.fresh()
+	typedElems := make([]ast.TypedExpr, len(expr.Elems))
+	for i, elem := range expr.Elems {
+		if i == 0 {
+			typedElems[i] = c.checkTyped(elem, g)
+			elemType = typedElems[i].Type()
+			continue
+		}
+		exp := expectation{context: "list elements", span: expr.Span(), origin: expr.Elems[0].Span()}
+		typedElems[i] = c.checkExpected(exp, elem, elemType, g)
+	}
+
+	return ast.NewTypedListExpr(&ast.ListType{Elem: elemType}, expr.Span(), typedElems), nil
+}
+
+func (c *checker) checkListCase(expr *ast.ListCaseExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedScrutinee := c.checkTyped(expr.Scrutinee, g)
+
+	lt := &ast.ListType{Elem: c.fresh()}
+	if c.unify(lt, typedScrutinee.Type()) != nil {
+		return nil, &NotAListError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: c.subst.Apply(typedScrutinee.Type()),
+		}
+	}
+
+	typedNil := c.checkTyped(expr.Nil, g)
+
+	exp := expectation{context: "case branches", span: expr.Span(), origin: expr.Nil.Span()}
+	consGamma := g.Bind(expr.HeadVar, Mono(lt.Elem)).Bind(expr.TailVar, Mono(lt))
+	typedCons := c.checkExpected(exp, expr.Cons, typedNil.Type(), consGamma)
+
+	return ast.NewTypedListCaseExpr(expr.Span(), typedScrutinee, typedNil, expr.HeadVar, expr.TailVar, typedCons), nil
+}
+
+func (c *checker) checkRecord(expr *ast.RecordExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedFields := make([]ast.TypedRecordField, len(expr.Fields))
+	fieldTypes := make([]ast.Field, len(expr.Fields))
+	for i, field := range expr.Fields {
+		typedValue := c.checkTyped(field.Value, g)
+		typedFields[i] = ast.TypedRecordField{Label: field.Label, Value: typedValue}
+		fieldTypes[i] = ast.Field{Label: field.Label, Type: typedValue.Type()}
+	}
+
+	return ast.NewTypedRecordExpr(&ast.RecordType{Fields: fieldTypes}, expr.Span(), typedFields), nil
+}
 
-	if _, ok := typedCond.Type().(*ast.BoolType); !ok {
-		return nil, &InvalidConditionTypeError{
+func (c *checker) checkRecordProj(expr *ast.RecordProjExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedRecord := c.checkTyped(expr.Record, g)
+
+	if c.isError(typedRecord.Type()) {
+		return ast.NewTypedRecordProjExpr(&ast.ErrorType{}, expr.Span(), typedRecord, expr.Label), nil
+	}
+
+	// The fields of a record cannot be inferred from a projection
+	rt, ok := c.subst.resolve(typedRecord.Type()).(*ast.RecordType)
+	if !ok {
+		return nil, &NotARecordError{
 			Pos:  expr.Pos,
-			Type: typedCond.Type(),
+			End:  expr.End,
+			Type: c.subst.Apply(typedRecord.Type()),
+		}
+	}
+
+	typ, ok := rt.Field(expr.Label)
+	if !ok {
+		return nil, &UnknownLabelError{
+			Pos:   expr.Pos,
+			End:   expr.End,
+			Label: expr.Label,
+			Type:  c.subst.Apply(rt),
 		}
 	}
 
-	typedThen, err := checkTyped(expr.Then, g)
+	return ast.NewTypedRecordProjExpr(typ, expr.Span(), typedRecord, expr.Label), nil
+}
+
+func (c *checker) checkVariant(expr *ast.VariantExpr, g *Gamma) (ast.TypedExpr, error) {
+	typ, err := c.resolveType(expr.Span(), expr.Type)
 	if err != nil {
 		return nil, err
 	}
 
-	typedElse, err := checkTyped(expr.Else, g)
+	vt, ok := typ.(*ast.VariantType)
+	if !ok {
+		return nil, &NotAVariantError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: typ,
+		}
+	}
+
+	expected, ok := vt.Field(expr.Label)
+	if !ok {
+		return nil, &UnknownLabelError{
+			Pos:   expr.Pos,
+			End:   expr.End,
+			Label: expr.Label,
+			Type:  vt,
+		}
+	}
+
+	typedValue := c.checkExpected(expectation{context: "variant", span: expr.Span()}, expr.Value, expected, g)
+
+	return ast.NewTypedVariantExpr(vt, expr.Span(), expr.Label, typedValue), nil
+}
+
+func (c *checker) checkVariantCase(expr *ast.VariantCaseExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedScrutinee := c.checkTyped(expr.Scrutinee, g)
+
+	// An unsolved scrutinee type is the variant of exactly the labels of the branches
+	if m, ok := c.subst.resolve(typedScrutinee.Type()).(*ast.MetaVar); ok {
+		fields := make([]ast.Field, len(expr.Branches))
+		for i, branch := range expr.Branches {
+			fields[i] = ast.Field{Label: branch.Label, Type: c.fresh()}
+		}
+		c.bindVar(m, &ast.VariantType{Fields: fields})
+	}
+
+	// The branches of a scrutinee that failed to type check bind variables of ErrorType
+	vt, ok := c.subst.resolve(typedScrutinee.Type()).(*ast.VariantType)
+	if !ok && !c.isError(typedScrutinee.Type()) {
+		return nil, &NotAVariantError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: c.subst.Apply(typedScrutinee.Type()),
+		}
+	}
+
+	var resultType ast.Type
+	covered := map[string]bool{}
+	typedBranches := make([]ast.TypedVariantBranch, len(expr.Branches))
+	for i, branch := range expr.Branches {
+		var typ ast.Type = &ast.ErrorType{}
+		if vt != nil {
+			t, ok := vt.Field(branch.Label)
+			if !ok {
+				return nil, &UnknownLabelError{
+					Pos:   branch.Pos,
+					End:   branch.End,
+					Label: branch.Label,
+					Type:  c.subst.Apply(vt),
+				}
+			}
+			typ = t
+		}
+		covered[branch.Label] = true
+
+		// The first branch determines the type of the other branches
+		var typedBody ast.TypedExpr
+		if resultType == nil {
+			typedBody = c.checkTyped(branch.Body, g.Bind(branch.Var, Mono(typ)))
+		} else {
+			exp := expectation{context: "case branches", span: branch.Span(), origin: expr.Branches[0].Body.Span()}
+			typedBody = c.checkExpected(exp, branch.Body, resultType, g.Bind(branch.Var, Mono(typ)))
+		}
+		if resultType == nil {
+			resultType = typedBody.Type()
+		}
+
+		typedBranches[i] = ast.TypedVariantBranch{
+			Pos:   branch.Pos,
+			End:   branch.End,
+			Label: branch.Label,
+			Var:   branch.Var,
+			Body:  typedBody,
+		}
+	}
+
+	var missing []string
+	if vt != nil {
+		for _, field := range vt.Fields {
+			if !covered[field.Label] {
+				missing = append(missing, field.Label)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &NonExhaustiveCaseError{
+			Pos:     expr.Pos,
+			End:     expr.End,
+			Missing: missing,
+		}
+	}
+
+	return ast.NewTypedVariantCaseExpr(resultType, expr.Span(), typedScrutinee, typedBranches), nil
+}
+
+func (c *checker) checkMatch(expr *ast.MatchExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedScrutinee := c.checkTyped(expr.Scrutinee, g)
+
+	// The scrutinee determines the type of the patterns
+	exp := expectation{context: "pattern", origin: expr.Scrutinee.Span()}
+
+	var resultType ast.Type
+	typedArms := make([]ast.TypedMatchArm, len(expr.Arms))
+	for i, arm := range expr.Arms {
+		armGamma, err := c.checkPattern(arm.Pattern, typedScrutinee.Type(), exp, g, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		// The first arm determines the type of the other arms
+		var typedBody ast.TypedExpr
+		if resultType == nil {
+			typedBody = c.checkTyped(arm.Body, armGamma)
+			resultType = typedBody.Type()
+		} else {
+			exp := expectation{context: "match arms", span: arm.Span(), origin: expr.Arms[0].Body.Span()}
+			typedBody = c.checkExpected(exp, arm.Body, resultType, armGamma)
+		}
+
+		typedArms[i] = ast.TypedMatchArm{
+			Pos:     arm.Pos,
+			End:     arm.End,
+			Pattern: arm.Pattern,
+			Body:    typedBody,
+		}
+	}
+
+	// The arms of a scrutinee that failed to type check are not checked for coverage
+	if !c.isError(typedScrutinee.Type()) {
+		if err := c.checkCoverage(expr); err != nil {
+			return nil, err
+		}
+	}
+
+	return ast.NewTypedMatchExpr(resultType, expr.Span(), typedScrutinee, typedArms), nil
+}
+
+// checkPattern checks that pattern matches values of type t expected as described by exp,
+// and returns g extended with the variables it binds. seen holds the variables bound so far by the pattern.
+func (c *checker) checkPattern(pattern ast.Pattern, t ast.Type, exp expectation, g *Gamma, seen map[string]bool) (*Gamma, error) {
+	switch p := pattern.(type) {
+	case *ast.WildcardPattern:
+		return g, nil
+	case *ast.VarPattern:
+		if seen[p.Name] {
+			return nil, &DuplicatePatternVariableError{
+				Pos:  p.Pos,
+				End:  p.End,
+				Name: p.Name,
+			}
+		}
+		seen[p.Name] = true
+		return g.Bind(p.Name, Mono(t)), nil
+	case *ast.LiteralPattern:
+		typedValue, err := c.infer(p.Value, g)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.expect(exp.at(p.Span()), p.Span(), t, typedValue.Type()); err != nil {
+			return nil, err
+		}
+		return g, nil
+	case *ast.ConstructorPattern:
+		con, ok := c.constructors[p.Name]
+		if !ok {
+			return nil, &UndefinedConstructorError{
+				Pos:  p.Pos,
+				End:  p.End,
+				Name: p.Name,
+			}
+		}
+		if len(p.Args) != len(con.params) {
+			return nil, &ConstructorArityError{
+				Pos:      p.Pos,
+				End:      p.End,
+				Name:     p.Name,
+				Expected: len(con.params),
+				Actual:   len(p.Args),
+			}
+		}
+		if err := c.expect(exp.at(p.Span()), p.Span(), t, &ast.DataType{Name: con.typ.name}); err != nil {
+			return nil, err
+		}
+		for i, arg := range p.Args {
+			var err error
+			// An argument is expected to have the type of the parameter rather than of the scrutinee
+			if g, err = c.checkPattern(arg, con.params[i], expectation{context: "constructor pattern", origin: p.Span()}, g, seen); err != nil {
+				return nil, err
+			}
+		}
+		return g, nil
+	default:
+		panic(fmt.Sprintf("types: unexpected pattern %T", pattern))
+	}
+}
+
+func (c *checker) checkTyAbs(expr *ast.TyAbsExpr, g *Gamma) (ast.TypedExpr, error) {
+	// Rename the type variable if it shadows one whose rigid variable may already occur in g
+	rigid := expr.TypeVar
+	for c.rigidInScope(rigid) {
+		rigid += "'"
+	}
+
+	// Type variables created before this point belong to the enclosing scope
+	outer := c.nextID
+
+	c.typeVars = append(c.typeVars, typeVarBinding{name: expr.TypeVar, rigid: rigid})
+	typedBody := c.checkTyped(expr.Body, g)
+	c.typeVars = c.typeVars[:len(c.typeVars)-1]
+
+	for id := range outer {
+		if slices.Contains(typeVars(c.subst.Apply(&ast.MetaVar{ID: id}), nil), rigid) {
+			return nil, &TypeVariableEscapeError{
+				Pos:  expr.Pos,
+				End:  expr.End,
+				Name: expr.TypeVar,
+			}
+		}
+	}
+
+	forallType := &ast.ForallType{
+		Var:  rigid,
+		Body: typedBody.Type(),
+	}
+	return ast.NewTypedTyAbsExpr(forallType, expr.Span(), rigid, typedBody), nil
+}
+
+func (c *checker) rigidInScope(name string) bool {
+	for _, binding := range c.typeVars {
+		if binding.rigid == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) checkTyApp(expr *ast.TyAppExpr, g *Gamma) (ast.TypedExpr, error) {
+	typedFunc := c.checkTyped(expr.Func, g)
+	if c.isError(typedFunc.Type()) {
+		return ast.NewTypedTyAppExpr(&ast.ErrorType{}, expr.Span(), typedFunc, expr.TypeArg), nil
+	}
+
+	// The polymorphic type of a type abstraction cannot be inferred from its application
+	ft, ok := c.subst.resolve(typedFunc.Type()).(*ast.ForallType)
+	if !ok {
+		return nil, &NotAForallError{
+			Pos:  expr.Pos,
+			End:  expr.End,
+			Type: c.subst.Apply(typedFunc.Type()),
+		}
+	}
+
+	typeArg, err := c.resolveType(expr.Span(), expr.TypeArg)
 	if err != nil {
 		return nil, err
 	}
 
-	if !typedThen.Type().Equal(typedElse.Type()) {
-		return nil, &TypeMismatchError{
-			Pos:      expr.Pos,
-			Expected: typedThen.Type(),
-			Actual:   typedElse.Type(),
-			Context:  "if-else branches",
+	typ := replaceTypeVars(c.subst.Apply(ft.Body), map[string]ast.Type{ft.Var: typeArg})
+	return ast.NewTypedTyAppExpr(typ, expr.Span(), typedFunc, typeArg), nil
+}
+
+// checkFold checks that the value of a fold has the type of the unfolding of the recursive type given.
+func (c *checker) checkFold(expr *ast.FoldExpr, g *Gamma) (ast.TypedExpr, error) {
+	rt, err := c.resolveRecType(expr.Span(), expr.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	typedValue := c.checkExpected(expectation{context: "fold", span: expr.Span()}, expr.Value, unroll(rt), g)
+	return ast.NewTypedFoldExpr(rt, expr.Span(), typedValue), nil
+}
+
+// checkUnfold checks that the value of an unfold has the recursive type given.
+func (c *checker) checkUnfold(expr *ast.UnfoldExpr, g *Gamma) (ast.TypedExpr, error) {
+	rt, err := c.resolveRecType(expr.Span(), expr.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	typedValue := c.checkExpected(expectation{context: "unfold", span: expr.Span()}, expr.Value, rt, g)
+	return ast.NewTypedUnfoldExpr(unroll(rt), expr.Span(), rt, typedValue), nil
+}
+
+// resolveRecType resolves the type annotation of a fold or unfold, which must be a recursive type.
+func (c *checker) resolveRecType(span token.Span, t ast.Type) (*ast.RecType, error) {
+	typ, err := c.resolveType(span, t)
+	if err != nil {
+		return nil, err
+	}
+	rt, ok := typ.(*ast.RecType)
+	if !ok {
+		return nil, &NotARecTypeError{
+			Pos:  span.Start,
+			End:  span.End,
+			Type: typ,
+		}
+	}
+	return rt, nil
+}
+
+// unroll returns the unfolding of a recursive type mu X. T, which is T with X replaced by mu X. T.
+func unroll(t *ast.RecType) ast.Type {
+	return replaceTypeVars(t.Body, map[string]ast.Type{t.Var: t})
+}
+
+// resolveType replaces the type variables of a type annotation with the rigid type variables
+// or the data types they refer to.
+func (c *checker) resolveType(span token.Span, t ast.Type) (ast.Type, error) {
+	// The type variables bound by type abstractions shadow the data types of the same names
+	scope := map[string]ast.Type{}
+	for name := range c.dataTypes {
+		scope[name] = &ast.DataType{Name: name}
+	}
+	for _, binding := range c.typeVars {
+		scope[binding.name] = &ast.TypeVar{Name: binding.rigid}
+	}
+
+	for _, name := range typeVars(t, nil) {
+		if _, ok := scope[name]; !ok {
+			return nil, &UndefinedTypeVariableError{
+				Pos:  span.Start,
+				End:  span.End,
+				Name: name,
+			}
 		}
 	}
-	return ast.NewTypedIfExpr(expr.Pos, typedCond, typedThen, typedElse), nil
+	return replaceTypeVars(t, scope), nil
 }
@@ -1,10 +1,12 @@
 package types
 
 import (
+	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/builtin"
 	"github.com/shota3506/gostlc/internal/token"
 )
 
@@ -12,6 +14,10 @@ func pos(line, col int) token.Position {
 	return token.Position{Line: line, Column: col}
 }
 
+func span(line, col int) token.Span {
+	return token.Span{Start: pos(line, col)}
+}
+
 func TestTypeChecker(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -28,6 +34,26 @@ func TestTypeChecker(t *testing.T) {
 			input:    &ast.IntExpr{Pos: pos(1, 1), Value: 42},
 			expected: ast.NewTypedIntExpr(&ast.IntExpr{Pos: pos(1, 1), Value: 42}),
 		},
+		{
+			name:     "unit literal",
+			input:    &ast.UnitExpr{Pos: pos(1, 1)},
+			expected: ast.NewTypedUnitExpr(&ast.UnitExpr{Pos: pos(1, 1)}),
+		},
+		{
+			name:     "string literal",
+			input:    &ast.StringExpr{Pos: pos(1, 1), Value: "hello"},
+			expected: ast.NewTypedStringExpr(&ast.StringExpr{Pos: pos(1, 1), Value: "hello"}),
+		},
+		{
+			name:     "character literal",
+			input:    &ast.CharExpr{Pos: pos(1, 1), Value: 'a'},
+			expected: ast.NewTypedCharExpr(&ast.CharExpr{Pos: pos(1, 1), Value: 'a'}),
+		},
+		{
+			name:     "float literal",
+			input:    &ast.FloatExpr{Pos: pos(1, 1), Value: 1.5},
+			expected: ast.NewTypedFloatExpr(&ast.FloatExpr{Pos: pos(1, 1), Value: 1.5}),
+		},
 		{
 			name:  "builtin add function",
 			input: &ast.VarExpr{Pos: pos(1, 1), Name: "add"},
@@ -68,7 +94,7 @@ func TestTypeChecker(t *testing.T) {
 					From: &ast.IntType{},
 					To:   &ast.IntType{},
 				},
-				pos(1, 1),
+				span(1, 1),
 				ast.NewTypedVarExpr(
 					&ast.FuncType{
 						From: &ast.IntType{},
@@ -95,13 +121,13 @@ func TestTypeChecker(t *testing.T) {
 			},
 			expected: ast.NewTypedAppExpr(
 				&ast.IntType{},
-				pos(1, 1),
+				span(1, 1),
 				ast.NewTypedAppExpr(
 					&ast.FuncType{
 						From: &ast.IntType{},
 						To:   &ast.IntType{},
 					},
-					pos(1, 1),
+					span(1, 1),
 					ast.NewTypedVarExpr(
 						&ast.FuncType{
 							From: &ast.IntType{},
@@ -130,13 +156,13 @@ func TestTypeChecker(t *testing.T) {
 			},
 			expected: ast.NewTypedAppExpr(
 				&ast.IntType{},
-				pos(1, 1),
+				span(1, 1),
 				ast.NewTypedAppExpr(
 					&ast.FuncType{
 						From: &ast.IntType{},
 						To:   &ast.IntType{},
 					},
-					pos(1, 1),
+					span(1, 1),
 					ast.NewTypedVarExpr(
 						&ast.FuncType{
 							From: &ast.IntType{},
@@ -165,7 +191,7 @@ func TestTypeChecker(t *testing.T) {
 					From: &ast.BoolType{},
 					To:   &ast.BoolType{},
 				},
-				pos(1, 1),
+				span(1, 1),
 				"x",
 				&ast.BoolType{},
 				ast.NewTypedVarExpr(&ast.BoolType{}, &ast.VarExpr{Pos: pos(1, 10), Name: "x"}),
@@ -192,7 +218,7 @@ func TestTypeChecker(t *testing.T) {
 						To:   &ast.IntType{},
 					},
 				},
-				pos(1, 1),
+				span(1, 1),
 				"x",
 				&ast.IntType{},
 				ast.NewTypedAbsExpr(
@@ -200,7 +226,7 @@ func TestTypeChecker(t *testing.T) {
 						From: &ast.BoolType{},
 						To:   &ast.IntType{},
 					},
-					pos(2, 1),
+					span(2, 1),
 					"y",
 					&ast.BoolType{},
 					ast.NewTypedVarExpr(&ast.IntType{}, &ast.VarExpr{Pos: pos(2, 10), Name: "x"}),
@@ -221,13 +247,13 @@ func TestTypeChecker(t *testing.T) {
 			},
 			expected: ast.NewTypedAppExpr(
 				&ast.BoolType{},
-				pos(1, 1),
+				span(1, 1),
 				ast.NewTypedAbsExpr(
 					&ast.FuncType{
 						From: &ast.BoolType{},
 						To:   &ast.BoolType{},
 					},
-					pos(1, 1),
+					span(1, 1),
 					"x",
 					&ast.BoolType{},
 					ast.NewTypedVarExpr(&ast.BoolType{}, &ast.VarExpr{Pos: pos(1, 10), Name: "x"}),
@@ -244,7 +270,7 @@ func TestTypeChecker(t *testing.T) {
 				Else: &ast.BoolExpr{Pos: pos(1, 20), Value: true},
 			},
 			expected: ast.NewTypedIfExpr(
-				pos(1, 1),
+				span(1, 1),
 				ast.NewTypedBoolExpr(&ast.BoolExpr{Pos: pos(1, 4), Value: true}),
 				ast.NewTypedBoolExpr(&ast.BoolExpr{Pos: pos(1, 10), Value: false}),
 				ast.NewTypedBoolExpr(&ast.BoolExpr{Pos: pos(1, 20), Value: true}),
@@ -259,7 +285,7 @@ func TestTypeChecker(t *testing.T) {
 				Else: &ast.IntExpr{Pos: pos(1, 15), Value: 2},
 			},
 			expected: ast.NewTypedIfExpr(
-				pos(1, 1),
+				span(1, 1),
 				ast.NewTypedBoolExpr(&ast.BoolExpr{Pos: pos(1, 4), Value: true}),
 				ast.NewTypedIntExpr(&ast.IntExpr{Pos: pos(1, 10), Value: 1}),
 				ast.NewTypedIntExpr(&ast.IntExpr{Pos: pos(1, 15), Value: 2}),
@@ -296,7 +322,7 @@ func TestTypeChecker(t *testing.T) {
 						To:   &ast.IntType{},
 					},
 				},
-				pos(1, 1),
+				span(1, 1),
 				"f",
 				&ast.FuncType{
 					From: &ast.BoolType{},
@@ -307,12 +333,12 @@ func TestTypeChecker(t *testing.T) {
 						From: &ast.BoolType{},
 						To:   &ast.IntType{},
 					},
-					pos(2, 1),
+					span(2, 1),
 					"x",
 					&ast.BoolType{},
 					ast.NewTypedAppExpr(
 						&ast.IntType{},
-						pos(3, 1),
+						span(3, 1),
 						ast.NewTypedVarExpr(
 							&ast.FuncType{
 								From: &ast.BoolType{},
@@ -328,6 +354,111 @@ func TestTypeChecker(t *testing.T) {
 				),
 			),
 		},
+		{
+			name: "fixed point of function",
+			input: &ast.FixExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:       pos(1, 6),
+					Param:     "x",
+					ParamType: &ast.IntType{},
+					Body:      &ast.VarExpr{Pos: pos(1, 14), Name: "x"},
+				},
+			},
+			expected: ast.NewTypedFixExpr(
+				&ast.IntType{},
+				span(1, 1),
+				ast.NewTypedAbsExpr(
+					&ast.FuncType{
+						From: &ast.IntType{},
+						To:   &ast.IntType{},
+					},
+					span(1, 6),
+					"x",
+					&ast.IntType{},
+					ast.NewTypedVarExpr(&ast.IntType{}, &ast.VarExpr{Pos: pos(1, 14), Name: "x"}),
+				),
+			),
+		},
+		{
+			name: "tuple projection",
+			input: &ast.ProjExpr{
+				Pos: pos(1, 1),
+				Tuple: &ast.TupleExpr{
+					Pos: pos(1, 1),
+					Elems: []ast.Expr{
+						&ast.IntExpr{Pos: pos(1, 2), Value: 1},
+						&ast.BoolExpr{Pos: pos(1, 5), Value: true},
+					},
+				},
+				Index: 2,
+			},
+			expected: ast.NewTypedProjExpr(
+				&ast.BoolType{},
+				span(1, 1),
+				ast.NewTypedTupleExpr(
+					&ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}}},
+					span(1, 1),
+					[]ast.TypedExpr{
+						ast.NewTypedIntExpr(&ast.IntExpr{Pos: pos(1, 2), Value: 1}),
+						ast.NewTypedBoolExpr(&ast.BoolExpr{Pos: pos(1, 5), Value: true}),
+					},
+				),
+				2,
+			),
+		},
+		{
+			name: "empty list",
+			input: &ast.NilExpr{
+				Pos:      pos(1, 1),
+				ElemType: &ast.IntType{},
+			},
+			expected: ast.NewTypedNilExpr(&ast.ListType{Elem: &ast.IntType{}}, span(1, 1)),
+		},
+		{
+			name: "list literal",
+			input: &ast.ListExpr{
+				Pos: pos(1, 1),
+				Elems: []ast.Expr{
+					&ast.IntExpr{Pos: pos(1, 2), Value: 1},
+					&ast.IntExpr{Pos: pos(1, 5), Value: 2},
+				},
+			},
+			expected: ast.NewTypedListExpr(
+				&ast.ListType{Elem: &ast.IntType{}},
+				span(1, 1),
+				[]ast.TypedExpr{
+					ast.NewTypedIntExpr(&ast.IntExpr{Pos: pos(1, 2), Value: 1}),
+					ast.NewTypedIntExpr(&ast.IntExpr{Pos: pos(1, 5), Value: 2}),
+				},
+			),
+		},
+		{
+			name: "list case analysis",
+			input: &ast.ListCaseExpr{
+				Pos: pos(1, 1),
+				Scrutinee: &ast.ListExpr{
+					Pos:   pos(1, 6),
+					Elems: []ast.Expr{&ast.BoolExpr{Pos: pos(1, 7), Value: true}},
+				},
+				Nil:     &ast.BoolExpr{Pos: pos(1, 24), Value: false},
+				HeadVar: "h",
+				TailVar: "t",
+				Cons:    &ast.VarExpr{Pos: pos(1, 45), Name: "h"},
+			},
+			expected: ast.NewTypedListCaseExpr(
+				span(1, 1),
+				ast.NewTypedListExpr(
+					&ast.ListType{Elem: &ast.BoolType{}},
+					span(1, 6),
+					[]ast.TypedExpr{ast.NewTypedBoolExpr(&ast.BoolExpr{Pos: pos(1, 7), Value: true})},
+				),
+				ast.NewTypedBoolExpr(&ast.BoolExpr{Pos: pos(1, 24), Value: false}),
+				"h",
+				"t",
+				ast.NewTypedVarExpr(&ast.BoolType{}, &ast.VarExpr{Pos: pos(1, 45), Name: "h"}),
+			),
+		},
 	}
 
 	for _, tt := range tests {
@@ -399,6 +530,165 @@ func TestTypeCheckerErrors(t *testing.T) {
 			},
 			expectedError: "1:1: type mismatch in if-else branches: expected Int, got Bool",
 		},
+		{
+			name: "fix of non-function",
+			input: &ast.FixExpr{
+				Pos:  pos(1, 1),
+				Func: &ast.IntExpr{Pos: pos(1, 5), Value: 1},
+			},
+			expectedError: "1:1: cannot apply non-function type: Int",
+		},
+		{
+			name: "fix of function with different domain and codomain",
+			input: &ast.FixExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:       pos(1, 6),
+					Param:     "x",
+					ParamType: &ast.IntType{},
+					Body:      &ast.BoolExpr{Pos: pos(1, 14), Value: true},
+				},
+			},
+			expectedError: "1:1: type mismatch in fix: expected (Int->Int), got (Int->Bool)",
+		},
+		{
+			name: "projection from non-tuple",
+			input: &ast.ProjExpr{
+				Pos:   pos(1, 1),
+				Tuple: &ast.IntExpr{Pos: pos(1, 1), Value: 1},
+				Index: 1,
+			},
+			expectedError: "1:1: cannot project from non-tuple type: Int",
+		},
+		{
+			name: "projection index out of range",
+			input: &ast.ProjExpr{
+				Pos: pos(1, 1),
+				Tuple: &ast.TupleExpr{
+					Pos: pos(1, 1),
+					Elems: []ast.Expr{
+						&ast.IntExpr{Pos: pos(1, 2), Value: 1},
+						&ast.BoolExpr{Pos: pos(1, 5), Value: true},
+					},
+				},
+				Index: 3,
+			},
+			expectedError: "1:1: tuple index 3 out of range for type: (Int*Bool)",
+		},
+		{
+			name: "injection with non-sum type",
+			input: &ast.InjExpr{
+				Pos:   pos(1, 1),
+				Left:  true,
+				Value: &ast.IntExpr{Pos: pos(1, 5), Value: 1},
+				Type:  &ast.IntType{},
+			},
+			expectedError: "1:1: expected sum type, got Int",
+		},
+		{
+			name: "injection with mismatched component",
+			input: &ast.InjExpr{
+				Pos:   pos(1, 1),
+				Left:  false,
+				Value: &ast.IntExpr{Pos: pos(1, 5), Value: 1},
+				Type:  &ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+			},
+			expectedError: "1:1: type mismatch in injection: expected Bool, got Int",
+		},
+		{
+			name: "mismatched list elements",
+			input: &ast.ListExpr{
+				Pos: pos(1, 1),
+				Elems: []ast.Expr{
+					&ast.IntExpr{Pos: pos(1, 2), Value: 1},
+					&ast.BoolExpr{Pos: pos(1, 5), Value: true},
+				},
+			},
+			expectedError: "1:1: type mismatch in list elements: expected Int, got Bool",
+		},
+		{
+			name: "list case analysis of non-list",
+			input: &ast.ListCaseExpr{
+				Pos:       pos(1, 1),
+				Scrutinee: &ast.IntExpr{Pos: pos(1, 6), Value: 1},
+				Nil:       &ast.IntExpr{Pos: pos(1, 17), Value: 0},
+				HeadVar:   "h",
+				TailVar:   "t",
+				Cons:      &ast.VarExpr{Pos: pos(1, 34), Name: "h"},
+			},
+			expectedError: "1:1: expected list type, got Int",
+		},
+		{
+			name: "mismatched case branches",
+			input: &ast.CaseExpr{
+				Pos: pos(1, 1),
+				Scrutinee: &ast.InjExpr{
+					Pos:   pos(1, 6),
+					Left:  true,
+					Value: &ast.IntExpr{Pos: pos(1, 10), Value: 1},
+					Type:  &ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+				},
+				LeftVar:  "x",
+				Left:     &ast.VarExpr{Pos: pos(1, 40), Name: "x"},
+				RightVar: "y",
+				Right:    &ast.VarExpr{Pos: pos(1, 53), Name: "y"},
+			},
+			expectedError: "1:1: type mismatch in case branches: expected Int, got Bool",
+		},
+		{
+			name: "projection of missing record field",
+			input: &ast.RecordProjExpr{
+				Pos: pos(1, 1),
+				Record: &ast.RecordExpr{
+					Pos: pos(1, 1),
+					Fields: []ast.RecordField{
+						{Label: "x", Value: &ast.IntExpr{Pos: pos(1, 6), Value: 1}},
+					},
+				},
+				Label: "y",
+			},
+			expectedError: "1:1: label y not found in type: {x:Int}",
+		},
+		{
+			name: "projection from non-record",
+			input: &ast.RecordProjExpr{
+				Pos:    pos(1, 1),
+				Record: &ast.IntExpr{Pos: pos(1, 1), Value: 1},
+				Label:  "x",
+			},
+			expectedError: "1:1: expected record type, got Int",
+		},
+		{
+			name: "variant with unknown label",
+			input: &ast.VariantExpr{
+				Pos:   pos(1, 1),
+				Label: "other",
+				Value: &ast.IntExpr{Pos: pos(1, 10), Value: 1},
+				Type: &ast.VariantType{Fields: []ast.Field{
+					{Label: "some", Type: &ast.IntType{}},
+				}},
+			},
+			expectedError: "1:1: label other not found in type: <some:Int>",
+		},
+		{
+			name: "non-exhaustive variant case",
+			input: &ast.VariantCaseExpr{
+				Pos: pos(1, 1),
+				Scrutinee: &ast.VariantExpr{
+					Pos:   pos(1, 6),
+					Label: "some",
+					Value: &ast.IntExpr{Pos: pos(1, 13), Value: 1},
+					Type: &ast.VariantType{Fields: []ast.Field{
+						{Label: "some", Type: &ast.IntType{}},
+						{Label: "none", Type: &ast.BoolType{}},
+					}},
+				},
+				Branches: []ast.VariantBranch{
+					{Pos: pos(2, 1), Label: "some", Var: "n", Body: &ast.VarExpr{Pos: pos(2, 15), Name: "n"}},
+				},
+			},
+			expectedError: "1:1: non-exhaustive case analysis: missing none",
+		},
 		{
 			name: "undefined variable in abstraction body",
 			input: &ast.AbsExpr{
@@ -412,6 +702,242 @@ func TestTypeCheckerErrors(t *testing.T) {
 			},
 			expectedError: "1:10: undefined variable: y",
 		},
+		{
+			name: "infinite type in self application",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "x",
+				Body: &ast.AppExpr{
+					Pos:  pos(1, 5),
+					Func: &ast.VarExpr{Pos: pos(1, 5), Name: "x"},
+					Arg:  &ast.VarExpr{Pos: pos(1, 7), Name: "x"},
+				},
+			},
+			expectedError: "1:5: infinite type in application: 'a occurs in ('a->'b)",
+		},
+		{
+			name: "unification failure inside function type",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:   pos(1, 2),
+					Param: "f",
+					ParamType: &ast.FuncType{
+						From: &ast.IntType{},
+						To:   &ast.BoolType{},
+					},
+					Body: &ast.VarExpr{Pos: pos(1, 19), Name: "f"},
+				},
+				Arg: &ast.AbsExpr{
+					Pos:   pos(1, 23),
+					Param: "y",
+					Body: &ast.AppExpr{
+						Pos: pos(1, 27),
+						Func: &ast.AppExpr{
+							Pos:  pos(1, 27),
+							Func: &ast.VarExpr{Pos: pos(1, 27), Name: "add"},
+							Arg:  &ast.VarExpr{Pos: pos(1, 31), Name: "y"},
+						},
+						Arg: &ast.IntExpr{Pos: pos(1, 33), Value: 1},
+					},
+				},
+			},
+			expectedError: "1:1: type mismatch in application: expected (Int->Bool), got (Int->Int): Bool is incompatible with Int",
+		},
+		{
+			name: "inferred parameter used at conflicting types",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "f",
+				Body: &ast.IfExpr{
+					Pos: pos(1, 5),
+					Cond: &ast.AppExpr{
+						Pos:  pos(1, 8),
+						Func: &ast.VarExpr{Pos: pos(1, 8), Name: "f"},
+						Arg:  &ast.IntExpr{Pos: pos(1, 10), Value: 1},
+					},
+					Then: &ast.AppExpr{
+						Pos:  pos(1, 17),
+						Func: &ast.VarExpr{Pos: pos(1, 17), Name: "f"},
+						Arg:  &ast.BoolExpr{Pos: pos(1, 19), Value: true},
+					},
+					Else: &ast.BoolExpr{Pos: pos(1, 29), Value: false},
+				},
+			},
+			expectedError: "1:17: type mismatch in application: expected Int, got Bool",
+		},
+		{
+			name: "undefined type variable",
+			input: &ast.AbsExpr{
+				Pos:       pos(1, 1),
+				Param:     "x",
+				ParamType: &ast.TypeVar{Name: "A"},
+				Body:      &ast.VarExpr{Pos: pos(1, 7), Name: "x"},
+			},
+			expectedError: "1:1: undefined type variable: A",
+		},
+		{
+			name: "type application of monomorphic function",
+			input: &ast.TyAppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:       pos(1, 2),
+					Param:     "x",
+					ParamType: &ast.IntType{},
+					Body:      &ast.VarExpr{Pos: pos(1, 10), Name: "x"},
+				},
+				TypeArg: &ast.IntType{},
+			},
+			expectedError: "1:1: cannot apply type to non-polymorphic type: (Int->Int)",
+		},
+		{
+			name: "fold into non-recursive type",
+			input: &ast.FoldExpr{
+				Pos:   pos(1, 1),
+				Type:  &ast.IntType{},
+				Value: &ast.IntExpr{Pos: pos(1, 12), Value: 1},
+			},
+			expectedError: "1:1: expected recursive type, got Int",
+		},
+		{
+			name: "unfold of unfolded value",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "x",
+				ParamType: &ast.ProductType{Elems: []ast.Type{
+					&ast.IntType{},
+					&ast.RecType{Var: "S", Body: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "S"}}}},
+				}},
+				Body: &ast.UnfoldExpr{
+					Pos:   pos(1, 28),
+					Type:  &ast.RecType{Var: "S", Body: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "S"}}}},
+					Value: &ast.VarExpr{Pos: pos(1, 52), Name: "x"},
+				},
+			},
+			expectedError: "1:28: type mismatch in unfold: expected (mu S. (Int*S)), got (Int*(mu S. (Int*S)))",
+		},
+		{
+			name: "type variable escaping through an inferred parameter",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "x",
+				Body: &ast.TyAbsExpr{
+					Pos:     pos(1, 5),
+					TypeVar: "A",
+					Body: &ast.AbsExpr{
+						Pos:       pos(1, 10),
+						Param:     "y",
+						ParamType: &ast.TypeVar{Name: "A"},
+						Body: &ast.AppExpr{
+							Pos: pos(1, 16),
+							Func: &ast.AppExpr{
+								Pos: pos(1, 16),
+								Func: &ast.AppExpr{
+									Pos:  pos(1, 16),
+									Func: &ast.VarExpr{Pos: pos(1, 16), Name: "choose"},
+									Arg:  &ast.BoolExpr{Pos: pos(1, 23), Value: true},
+								},
+								Arg: &ast.VarExpr{Pos: pos(1, 28), Name: "x"},
+							},
+							Arg: &ast.VarExpr{Pos: pos(1, 30), Name: "y"},
+						},
+					},
+				},
+			},
+			expectedError: "1:5: type variable A escapes its scope",
+		},
+		{
+			name: "lambda-bound variable is monomorphic",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "f",
+				Body: &ast.TupleExpr{
+					Pos: pos(1, 5),
+					Elems: []ast.Expr{
+						&ast.AppExpr{
+							Pos:  pos(1, 6),
+							Func: &ast.VarExpr{Pos: pos(1, 6), Name: "f"},
+							Arg:  &ast.IntExpr{Pos: pos(1, 8), Value: 1},
+						},
+						&ast.AppExpr{
+							Pos:  pos(1, 11),
+							Func: &ast.VarExpr{Pos: pos(1, 11), Name: "f"},
+							Arg:  &ast.BoolExpr{Pos: pos(1, 13), Value: true},
+						},
+					},
+				},
+			},
+			expectedError: "1:11: type mismatch in application: expected Int, got Bool",
+		},
+		{
+			name: "errors in independent subexpressions",
+			input: &ast.TupleExpr{
+				Pos: pos(1, 1),
+				Elems: []ast.Expr{
+					&ast.VarExpr{Pos: pos(1, 2), Name: "x"},
+					&ast.AppExpr{
+						Pos: pos(1, 5),
+						Func: &ast.AppExpr{
+							Pos:  pos(1, 5),
+							Func: &ast.VarExpr{Pos: pos(1, 5), Name: "add"},
+							Arg:  &ast.BoolExpr{Pos: pos(1, 9), Value: true},
+						},
+						Arg: &ast.IntExpr{Pos: pos(1, 14), Value: 1},
+					},
+				},
+			},
+			expectedError: "1:2: undefined variable: x\n1:5: type mismatch in application: expected Int, got Bool",
+		},
+		{
+			name: "error type does not cascade",
+			input: &ast.LetExpr{
+				Pos:   pos(1, 1),
+				Name:  "x",
+				Value: &ast.VarExpr{Pos: pos(1, 9), Name: "y"},
+				Body: &ast.ProjExpr{
+					Pos: pos(1, 14),
+					Tuple: &ast.AppExpr{
+						Pos:  pos(1, 15),
+						Func: &ast.VarExpr{Pos: pos(1, 15), Name: "x"},
+						Arg:  &ast.IntExpr{Pos: pos(1, 17), Value: 1},
+					},
+					Index: 1,
+				},
+			},
+			expectedError: "1:9: undefined variable: y",
+		},
+		{
+			name: "errors in condition and branches",
+			input: &ast.IfExpr{
+				Pos:  pos(1, 1),
+				Cond: &ast.IntExpr{Pos: pos(1, 4), Value: 1},
+				Then: &ast.VarExpr{Pos: pos(1, 11), Name: "x"},
+				Else: &ast.VarExpr{Pos: pos(1, 18), Name: "y"},
+			},
+			expectedError: "1:1: condition must be boolean, got Int\n1:11: undefined variable: x\n1:18: undefined variable: y",
+		},
+		{
+			name: "errors in applied non-function and its argument",
+			input: &ast.LetExpr{
+				Pos:  pos(1, 1),
+				Name: "x",
+				Value: &ast.AppExpr{
+					Pos: pos(1, 9),
+					Func: &ast.AppExpr{
+						Pos:  pos(1, 9),
+						Func: &ast.VarExpr{Pos: pos(1, 9), Name: "add"},
+						Arg:  &ast.BoolExpr{Pos: pos(1, 13), Value: true},
+					},
+					Arg: &ast.IntExpr{Pos: pos(1, 18), Value: 1},
+				},
+				Body: &ast.AppExpr{
+					Pos:  pos(1, 23),
+					Func: &ast.VarExpr{Pos: pos(1, 23), Name: "x"},
+					Arg:  &ast.VarExpr{Pos: pos(1, 25), Name: "y"},
+				},
+			},
+			expectedError: "1:9: type mismatch in application: expected Int, got Bool\n1:23: cannot apply non-function type: Int\n1:25: undefined variable: y",
+		},
 	}
 
 	for _, tt := range tests {
@@ -427,42 +953,1198 @@ func TestTypeCheckerErrors(t *testing.T) {
 	}
 }
 
-func TestTypesEqual(t *testing.T) {
+func TestTypeInference(t *testing.T) {
 	tests := []struct {
-		name  string
-		t1    ast.Type
-		t2    ast.Type
-		equal bool
+		name     string
+		input    ast.Expr
+		expected ast.Type
 	}{
 		{
-			name:  "same boolean types",
-			t1:    &ast.BoolType{},
-			t2:    &ast.BoolType{},
-			equal: true,
+			name: "parameter inferred from builtin",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "x",
+				Body: &ast.AppExpr{
+					Pos: pos(1, 5),
+					Func: &ast.AppExpr{
+						Pos:  pos(1, 5),
+						Func: &ast.VarExpr{Pos: pos(1, 5), Name: "add"},
+						Arg:  &ast.VarExpr{Pos: pos(1, 9), Name: "x"},
+					},
+					Arg: &ast.IntExpr{Pos: pos(1, 11), Value: 1},
+				},
+			},
+			expected: &ast.FuncType{From: &ast.IntType{}, To: &ast.IntType{}},
 		},
 		{
-			name:  "same integer types",
-			t1:    &ast.IntType{},
-			t2:    &ast.IntType{},
-			equal: true,
+			name: "parameter inferred from condition",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "b",
+				Body: &ast.IfExpr{
+					Pos:  pos(1, 5),
+					Cond: &ast.VarExpr{Pos: pos(1, 8), Name: "b"},
+					Then: &ast.IntExpr{Pos: pos(1, 15), Value: 1},
+					Else: &ast.IntExpr{Pos: pos(1, 22), Value: 0},
+				},
+			},
+			expected: &ast.FuncType{From: &ast.BoolType{}, To: &ast.IntType{}},
 		},
 		{
-			name:  "different base types",
-			t1:    &ast.BoolType{},
-			t2:    &ast.IntType{},
-			equal: false,
+			name: "unconstrained parameter",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "x",
+				Body:  &ast.VarExpr{Pos: pos(1, 5), Name: "x"},
+			},
+			expected: &ast.FuncType{From: &ast.MetaVar{ID: 0}, To: &ast.MetaVar{ID: 0}},
 		},
 		{
-			name: "same function types",
-			t1: &ast.FuncType{
-				From: &ast.BoolType{},
-				To:   &ast.IntType{},
+			name: "function parameter inferred from application",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "f",
+				Body: &ast.AppExpr{
+					Pos:  pos(1, 5),
+					Func: &ast.VarExpr{Pos: pos(1, 5), Name: "f"},
+					Arg:  &ast.BoolExpr{Pos: pos(1, 7), Value: true},
+				},
 			},
-			t2: &ast.FuncType{
-				From: &ast.BoolType{},
-				To:   &ast.IntType{},
+			expected: &ast.FuncType{
+				From: &ast.FuncType{From: &ast.BoolType{}, To: &ast.MetaVar{ID: 0}},
+				To:   &ast.MetaVar{ID: 0},
 			},
-			equal: true,
+		},
+		{
+			name: "unannotated fix",
+			input: &ast.FixExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:   pos(1, 5),
+					Param: "f",
+					Body: &ast.AbsExpr{
+						Pos:   pos(1, 9),
+						Param: "x",
+						Body: &ast.IfExpr{
+							Pos:  pos(1, 13),
+							Cond: &ast.VarExpr{Pos: pos(1, 16), Name: "x"},
+							Then: &ast.AppExpr{
+								Pos:  pos(1, 23),
+								Func: &ast.VarExpr{Pos: pos(1, 23), Name: "f"},
+								Arg:  &ast.BoolExpr{Pos: pos(1, 25), Value: false},
+							},
+							Else: &ast.IntExpr{Pos: pos(1, 36), Value: 1},
+						},
+					},
+				},
+			},
+			expected: &ast.FuncType{From: &ast.BoolType{}, To: &ast.IntType{}},
+		},
+		{
+			name: "polymorphic let",
+			input: &ast.LetExpr{
+				Pos:  pos(1, 1),
+				Name: "id",
+				Value: &ast.AbsExpr{
+					Pos:   pos(1, 10),
+					Param: "x",
+					Body:  &ast.VarExpr{Pos: pos(1, 14), Name: "x"},
+				},
+				Body: &ast.TupleExpr{
+					Pos: pos(1, 19),
+					Elems: []ast.Expr{
+						&ast.AppExpr{
+							Pos:  pos(1, 20),
+							Func: &ast.VarExpr{Pos: pos(1, 20), Name: "id"},
+							Arg:  &ast.IntExpr{Pos: pos(1, 23), Value: 1},
+						},
+						&ast.AppExpr{
+							Pos:  pos(1, 26),
+							Func: &ast.VarExpr{Pos: pos(1, 26), Name: "id"},
+							Arg:  &ast.BoolExpr{Pos: pos(1, 29), Value: true},
+						},
+					},
+				},
+			},
+			expected: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}}},
+		},
+		{
+			name: "type variable of enclosing parameter is not generalized",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "y",
+				Body: &ast.LetExpr{
+					Pos:  pos(1, 5),
+					Name: "f",
+					Value: &ast.AbsExpr{
+						Pos:   pos(1, 13),
+						Param: "x",
+						Body:  &ast.VarExpr{Pos: pos(1, 17), Name: "y"},
+					},
+					Body: &ast.AppExpr{
+						Pos:  pos(1, 22),
+						Func: &ast.VarExpr{Pos: pos(1, 22), Name: "f"},
+						Arg:  &ast.IntExpr{Pos: pos(1, 24), Value: 1},
+					},
+				},
+			},
+			expected: &ast.FuncType{From: &ast.MetaVar{ID: 0}, To: &ast.MetaVar{ID: 0}},
+		},
+		{
+			name: "polymorphic builtin",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AppExpr{
+					Pos: pos(1, 1),
+					Func: &ast.AppExpr{
+						Pos:  pos(1, 1),
+						Func: &ast.VarExpr{Pos: pos(1, 1), Name: "choose"},
+						Arg:  &ast.BoolExpr{Pos: pos(1, 8), Value: true},
+					},
+					Arg: &ast.IntExpr{Pos: pos(1, 13), Value: 1},
+				},
+				Arg: &ast.IntExpr{Pos: pos(1, 15), Value: 2},
+			},
+			expected: &ast.IntType{},
+		},
+		{
+			name: "empty list element inferred from cons",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AppExpr{
+					Pos:  pos(1, 1),
+					Func: &ast.VarExpr{Pos: pos(1, 1), Name: "cons"},
+					Arg:  &ast.BoolExpr{Pos: pos(1, 6), Value: true},
+				},
+				Arg: &ast.ListExpr{Pos: pos(1, 11)},
+			},
+			expected: &ast.ListType{Elem: &ast.BoolType{}},
+		},
+		{
+			name: "type abstraction",
+			input: &ast.TyAbsExpr{
+				Pos:     pos(1, 1),
+				TypeVar: "A",
+				Body: &ast.AbsExpr{
+					Pos:       pos(1, 6),
+					Param:     "x",
+					ParamType: &ast.TypeVar{Name: "A"},
+					Body:      &ast.VarExpr{Pos: pos(1, 12), Name: "x"},
+				},
+			},
+			expected: &ast.ForallType{
+				Var:  "A",
+				Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "A"}},
+			},
+		},
+		{
+			name: "type application",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.TyAppExpr{
+					Pos: pos(1, 1),
+					Func: &ast.TyAbsExpr{
+						Pos:     pos(1, 2),
+						TypeVar: "A",
+						Body: &ast.AbsExpr{
+							Pos:       pos(1, 7),
+							Param:     "x",
+							ParamType: &ast.TypeVar{Name: "A"},
+							Body:      &ast.VarExpr{Pos: pos(1, 13), Name: "x"},
+						},
+					},
+					TypeArg: &ast.IntType{},
+				},
+				Arg: &ast.IntExpr{Pos: pos(1, 21), Value: 3},
+			},
+			expected: &ast.IntType{},
+		},
+		{
+			name: "fold into recursive type",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "f",
+				Body: &ast.FoldExpr{
+					Pos:   pos(1, 5),
+					Type:  &ast.RecType{Var: "S", Body: &ast.FuncType{From: &ast.IntType{}, To: &ast.TypeVar{Name: "S"}}},
+					Value: &ast.VarExpr{Pos: pos(1, 30), Name: "f"},
+				},
+			},
+			expected: &ast.FuncType{
+				From: &ast.FuncType{
+					From: &ast.IntType{},
+					To:   &ast.RecType{Var: "S", Body: &ast.FuncType{From: &ast.IntType{}, To: &ast.TypeVar{Name: "S"}}},
+				},
+				To: &ast.RecType{Var: "S", Body: &ast.FuncType{From: &ast.IntType{}, To: &ast.TypeVar{Name: "S"}}},
+			},
+		},
+		{
+			name: "unfold of alpha-equivalent recursive type",
+			input: &ast.AbsExpr{
+				Pos:       pos(1, 1),
+				Param:     "x",
+				ParamType: &ast.RecType{Var: "T", Body: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "T"}}}},
+				Body: &ast.UnfoldExpr{
+					Pos:   pos(1, 22),
+					Type:  &ast.RecType{Var: "S", Body: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "S"}}}},
+					Value: &ast.VarExpr{Pos: pos(1, 46), Name: "x"},
+				},
+			},
+			expected: &ast.FuncType{
+				From: &ast.RecType{Var: "T", Body: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "T"}}}},
+				To: &ast.ProductType{Elems: []ast.Type{
+					&ast.IntType{},
+					&ast.RecType{Var: "S", Body: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "S"}}}},
+				}},
+			},
+		},
+		{
+			name: "shadowed type variable is renamed",
+			input: &ast.TyAbsExpr{
+				Pos:     pos(1, 1),
+				TypeVar: "A",
+				Body: &ast.AbsExpr{
+					Pos:       pos(1, 6),
+					Param:     "x",
+					ParamType: &ast.TypeVar{Name: "A"},
+					Body: &ast.TyAbsExpr{
+						Pos:     pos(1, 12),
+						TypeVar: "A",
+						Body: &ast.AbsExpr{
+							Pos:       pos(1, 17),
+							Param:     "y",
+							ParamType: &ast.TypeVar{Name: "A"},
+							Body:      &ast.VarExpr{Pos: pos(1, 23), Name: "x"},
+						},
+					},
+				},
+			},
+			expected: &ast.ForallType{
+				Var: "A",
+				Body: &ast.FuncType{
+					From: &ast.TypeVar{Name: "A"},
+					To: &ast.ForallType{
+						Var:  "B",
+						Body: &ast.FuncType{From: &ast.TypeVar{Name: "B"}, To: &ast.TypeVar{Name: "A"}},
+					},
+				},
+			},
+		},
+		{
+			name: "polymorphic argument",
+			input: &ast.AbsExpr{
+				Pos:   pos(1, 1),
+				Param: "f",
+				ParamType: &ast.ForallType{
+					Var:  "A",
+					Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "A"}},
+				},
+				Body: &ast.TupleExpr{
+					Pos: pos(1, 25),
+					Elems: []ast.Expr{
+						&ast.AppExpr{
+							Pos: pos(1, 26),
+							Func: &ast.TyAppExpr{
+								Pos:     pos(1, 26),
+								Func:    &ast.VarExpr{Pos: pos(1, 26), Name: "f"},
+								TypeArg: &ast.IntType{},
+							},
+							Arg: &ast.IntExpr{Pos: pos(1, 34), Value: 1},
+						},
+						&ast.AppExpr{
+							Pos: pos(1, 37),
+							Func: &ast.TyAppExpr{
+								Pos:     pos(1, 37),
+								Func:    &ast.VarExpr{Pos: pos(1, 37), Name: "f"},
+								TypeArg: &ast.BoolType{},
+							},
+							Arg: &ast.BoolExpr{Pos: pos(1, 46), Value: true},
+						},
+					},
+				},
+			},
+			expected: &ast.FuncType{
+				From: &ast.ForallType{
+					Var:  "B",
+					Body: &ast.FuncType{From: &ast.TypeVar{Name: "B"}, To: &ast.TypeVar{Name: "B"}},
+				},
+				To: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Check(tt.input)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if !actual.Type().Equal(tt.expected) {
+				t.Errorf("type mismatch: got %v, want %v", actual.Type(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestSchemeString(t *testing.T) {
+	tests := []struct {
+		name     string
+		scheme   *Scheme
+		expected string
+	}{
+		{
+			name:     "monomorphic",
+			scheme:   Mono(&ast.FuncType{From: &ast.IntType{}, To: &ast.IntType{}}),
+			expected: "(Int->Int)",
+		},
+		{
+			name: "quantified over named type variables",
+			scheme: Generic(&ast.FuncType{
+				From: &ast.TypeVar{Name: "a"},
+				To: &ast.FuncType{
+					From: &ast.TypeVar{Name: "b"},
+					To:   &ast.TypeVar{Name: "a"},
+				},
+			}),
+			expected: "forall a b. (a->(b->a))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scheme.String(); got != tt.expected {
+				t.Errorf("String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReplaceTypeVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    ast.Type
+		vars     map[string]ast.Type
+		expected string
+	}{
+		{
+			name:     "free occurrence",
+			input:    &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "B"}},
+			vars:     map[string]ast.Type{"A": &ast.IntType{}},
+			expected: "(Int->B)",
+		},
+		{
+			name: "bound occurrence is not replaced",
+			input: &ast.ForallType{
+				Var:  "A",
+				Body: &ast.TypeVar{Name: "A"},
+			},
+			vars:     map[string]ast.Type{"A": &ast.IntType{}},
+			expected: "(forall A. A)",
+		},
+		{
+			name: "bound variable is renamed to avoid capture",
+			input: &ast.ForallType{
+				Var:  "B",
+				Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "B"}},
+			},
+			vars:     map[string]ast.Type{"A": &ast.TypeVar{Name: "B"}},
+			expected: "(forall B'. (B->B'))",
+		},
+		{
+			name: "bound variable of recursive type is renamed to avoid capture",
+			input: &ast.RecType{
+				Var:  "X",
+				Body: &ast.SumType{Left: &ast.TypeVar{Name: "A"}, Right: &ast.TypeVar{Name: "X"}},
+			},
+			vars:     map[string]ast.Type{"A": &ast.TypeVar{Name: "X"}},
+			expected: "(mu X'. (X+X'))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceTypeVars(tt.input, tt.vars).String(); got != tt.expected {
+				t.Errorf("replaceTypeVars() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckProgram(t *testing.T) {
+	incType := &ast.FuncType{
+		From: &ast.IntType{},
+		To:   &ast.IntType{},
+	}
+	prog := &ast.Program{
+		Decls: []*ast.Decl{
+			{
+				Pos:  pos(1, 1),
+				Name: "inc",
+				Type: incType,
+				Value: &ast.AppExpr{
+					Pos:  pos(1, 23),
+					Func: &ast.VarExpr{Pos: pos(1, 23), Name: "add"},
+					Arg:  &ast.IntExpr{Pos: pos(1, 27), Value: 1},
+				},
+			},
+		},
+		Main: &ast.AppExpr{
+			Pos:  pos(2, 1),
+			Func: &ast.VarExpr{Pos: pos(2, 1), Name: "inc"},
+			Arg:  &ast.IntExpr{Pos: pos(2, 5), Value: 41},
+		},
+	}
+
+	typedProg, err := CheckProgram(prog)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(typedProg.Decls) != 1 {
+		t.Fatalf("expected 1 decl, got %d", len(typedProg.Decls))
+	}
+	if got := typedProg.Decls[0].Type(); !reflect.DeepEqual(got, incType) {
+		t.Errorf("decl type mismatch: got %v, want %v", got, incType)
+	}
+	if got := typedProg.Type(); !reflect.DeepEqual(got, &ast.IntType{}) {
+		t.Errorf("main type mismatch: got %v, want Int", got)
+	}
+}
+
+func TestCheckProgramTypeVarNames(t *testing.T) {
+	// The type variables of the main expression are named from 'a however many the definitions used
+	prog := &ast.Program{
+		Decls: []*ast.Decl{
+			{
+				Pos:  pos(1, 1),
+				Name: "k",
+				Value: &ast.AbsExpr{
+					Pos:   pos(1, 9),
+					Param: "x",
+					Body: &ast.AbsExpr{
+						Pos:   pos(1, 13),
+						Param: "y",
+						Body:  &ast.VarExpr{Pos: pos(1, 17), Name: "x"},
+					},
+				},
+			},
+		},
+		Main: &ast.AbsExpr{
+			Pos:   pos(2, 1),
+			Param: "z",
+			Body:  &ast.AppExpr{Pos: pos(2, 5), Func: &ast.VarExpr{Pos: pos(2, 5), Name: "k"}, Arg: &ast.VarExpr{Pos: pos(2, 7), Name: "z"}},
+		},
+	}
+
+	typedProg, err := CheckProgram(prog)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := typedProg.Type().String(), "('a->('b->'a))"; got != want {
+		t.Errorf("main type mismatch: got %s, want %s", got, want)
+	}
+	if got, want := typedProg.Decls[0].Type().String(), "('c->('d->'c))"; got != want {
+		t.Errorf("decl type mismatch: got %s, want %s", got, want)
+	}
+}
+
+func TestCheckIntLiterals(t *testing.T) {
+	huge, _ := new(big.Int).SetString("99999999999999999999", 10)
+	// literal is 99999999999999999999 at 1:1 as an expression, and at 2:3 as the pattern of a match arm
+	literal := &ast.IntExpr{Pos: pos(1, 1), End: pos(1, 21), Big: huge}
+	pattern := &ast.IntExpr{Pos: pos(2, 3), End: pos(2, 23), Big: huge}
+	match := &ast.MatchExpr{
+		Pos:       pos(1, 1),
+		Scrutinee: &ast.IntExpr{Pos: pos(1, 7), Value: 1},
+		Arms: []ast.MatchArm{
+			{Pattern: &ast.LiteralPattern{Value: pattern}, Body: &ast.IntExpr{Pos: pos(2, 27), Value: 1}},
+			{Pattern: &ast.WildcardPattern{Pos: pos(3, 3)}, Body: &ast.IntExpr{Pos: pos(3, 8), Value: 0}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		mode          builtin.IntMode
+		input         ast.Expr
+		expectedError string
+	}{
+		{name: "wrapping literal", mode: builtin.IntWrap, input: literal, expectedError: "1:1: integer literal out of the range of Int: 99999999999999999999"},
+		{name: "checked literal", mode: builtin.IntChecked, input: literal, expectedError: "1:1: integer literal out of the range of Int: 99999999999999999999"},
+		{name: "big literal", mode: builtin.IntBig, input: literal},
+		{name: "wrapping literal pattern", mode: builtin.IntWrap, input: match, expectedError: "2:3: integer literal out of the range of Int: 99999999999999999999"},
+		{name: "big literal pattern", mode: builtin.IntBig, input: match},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{IntMode: tt.mode}
+			_, err := config.Check(tt.input)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error %q, got nil", tt.expectedError)
+			}
+			if err.Error() != tt.expectedError {
+				t.Errorf("expected error %q, got %q", tt.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestCheckProgramErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         *ast.Program
+		expectedError string
+	}{
+		{
+			name: "annotation mismatch",
+			input: &ast.Program{
+				Decls: []*ast.Decl{
+					{
+						Pos:   pos(1, 1),
+						Name:  "x",
+						Type:  &ast.BoolType{},
+						Value: &ast.IntExpr{Pos: pos(1, 16), Value: 1},
+					},
+				},
+				Main: &ast.VarExpr{Pos: pos(2, 1), Name: "x"},
+			},
+			expectedError: "1:1: type mismatch in let binding: expected Bool, got Int",
+		},
+		{
+			name: "use before definition",
+			input: &ast.Program{
+				Decls: []*ast.Decl{
+					{
+						Pos:   pos(1, 1),
+						Name:  "x",
+						Value: &ast.VarExpr{Pos: pos(1, 9), Name: "y"},
+					},
+					{
+						Pos:   pos(2, 1),
+						Name:  "y",
+						Value: &ast.IntExpr{Pos: pos(2, 9), Value: 1},
+					},
+				},
+				Main: &ast.VarExpr{Pos: pos(3, 1), Name: "x"},
+			},
+			expectedError: "1:9: undefined variable: y",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CheckProgram(tt.input)
+
+			if err == nil {
+				t.Errorf("expected error, but got nil")
+			} else if err.Error() != tt.expectedError {
+				t.Errorf("error mismatch: got %v, want %v", err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+// shapeTypes declares type Shape = Circle Int | Rect Int Int and type Pair = Pair Shape Bool.
+var shapeTypes = []*ast.TypeDecl{
+	{
+		Pos:  pos(1, 1),
+		Name: "Shape",
+		Constructors: []*ast.Constructor{
+			{Pos: pos(1, 14), Name: "Circle", Params: []ast.Type{&ast.IntType{}}},
+			{Pos: pos(1, 27), Name: "Rect", Params: []ast.Type{&ast.IntType{}, &ast.IntType{}}},
+		},
+	},
+	{
+		Pos:  pos(2, 1),
+		Name: "Pair",
+		Constructors: []*ast.Constructor{
+			{Pos: pos(2, 13), Name: "Pair", Params: []ast.Type{&ast.TypeVar{Name: "Shape"}, &ast.BoolType{}}},
+		},
+	},
+}
+
+func conPattern(name string, args ...ast.Pattern) *ast.ConstructorPattern {
+	return &ast.ConstructorPattern{Pos: pos(3, 1), Name: name, Args: args}
+}
+
+func varPattern(name string) *ast.VarPattern {
+	return &ast.VarPattern{Pos: pos(3, 1), Name: name}
+}
+
+// matchProgram returns a program declaring shapeTypes whose main expression matches scrutinee against arms.
+func matchProgram(scrutinee ast.Expr, arms ...ast.MatchArm) *ast.Program {
+	for i := range arms {
+		arms[i].Pos = pos(4+i, 3)
+	}
+	return &ast.Program{
+		Types: shapeTypes,
+		Main:  &ast.MatchExpr{Pos: pos(3, 1), Scrutinee: scrutinee, Arms: arms},
+	}
+}
+
+func TestCheckProgramDataTypes(t *testing.T) {
+	circle := &ast.AppExpr{
+		Func: &ast.VarExpr{Name: "Circle"},
+		Arg:  &ast.IntExpr{Value: 1},
+	}
+	pair := &ast.AppExpr{
+		Func: &ast.AppExpr{Func: &ast.VarExpr{Name: "Pair"}, Arg: circle},
+		Arg:  &ast.BoolExpr{Value: true},
+	}
+
+	tests := []struct {
+		name             string
+		input            *ast.Program
+		expected         ast.Type
+		expectedWarnings []string
+	}{
+		{
+			name: "constructor",
+			input: &ast.Program{
+				Types: shapeTypes,
+				Main:  &ast.VarExpr{Name: "Rect"},
+			},
+			expected: &ast.FuncType{
+				From: &ast.IntType{},
+				To:   &ast.FuncType{From: &ast.IntType{}, To: &ast.DataType{Name: "Shape"}},
+			},
+		},
+		{
+			name: "match binding constructor arguments",
+			input: matchProgram(circle,
+				ast.MatchArm{Pattern: conPattern("Circle", varPattern("r")), Body: &ast.VarExpr{Name: "r"}},
+				ast.MatchArm{Pattern: conPattern("Rect", varPattern("w"), &ast.WildcardPattern{}), Body: &ast.VarExpr{Name: "w"}},
+			),
+			expected: &ast.IntType{},
+		},
+		{
+			name: "nested and literal patterns",
+			input: matchProgram(pair,
+				ast.MatchArm{
+					Pattern: conPattern("Pair", conPattern("Circle", &ast.LiteralPattern{Value: &ast.IntExpr{Value: 0}}), &ast.WildcardPattern{}),
+					Body:    &ast.BoolExpr{Value: false},
+				},
+				ast.MatchArm{
+					Pattern: conPattern("Pair", &ast.WildcardPattern{}, varPattern("b")),
+					Body:    &ast.VarExpr{Name: "b"},
+				},
+			),
+			expected: &ast.BoolType{},
+		},
+		{
+			name: "redundant arm",
+			input: matchProgram(circle,
+				ast.MatchArm{Pattern: varPattern("s"), Body: &ast.IntExpr{Value: 0}},
+				ast.MatchArm{Pattern: conPattern("Circle", varPattern("r")), Body: &ast.VarExpr{Name: "r"}},
+			),
+			expected:         &ast.IntType{},
+			expectedWarnings: []string{"5:3: redundant match arm: the arms before it match every value it matches"},
+		},
+		{
+			name: "redundant literal",
+			input: matchProgram(&ast.IntExpr{Value: 1},
+				ast.MatchArm{Pattern: &ast.LiteralPattern{Value: &ast.IntExpr{Value: 1}}, Body: &ast.IntExpr{Value: 0}},
+				ast.MatchArm{Pattern: &ast.LiteralPattern{Value: &ast.IntExpr{Value: 1}}, Body: &ast.IntExpr{Value: 1}},
+				ast.MatchArm{Pattern: &ast.WildcardPattern{}, Body: &ast.IntExpr{Value: 2}},
+			),
+			expected:         &ast.IntType{},
+			expectedWarnings: []string{"5:3: redundant match arm: the arms before it match every value it matches"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typedProg, err := CheckProgram(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := typedProg.Type(); !got.Equal(tt.expected) {
+				t.Errorf("type mismatch: got %v, want %v", got, tt.expected)
+			}
+			var warnings []string
+			for _, w := range typedProg.Warnings {
+				warnings = append(warnings, w.Error())
+			}
+			if !reflect.DeepEqual(warnings, tt.expectedWarnings) {
+				t.Errorf("warnings mismatch: got %q, want %q", warnings, tt.expectedWarnings)
+			}
+		})
+	}
+}
+
+func TestCheckProgramDataTypeErrors(t *testing.T) {
+	shape := &ast.VarExpr{Name: "shape"}
+	withShape := func(prog *ast.Program) *ast.Program {
+		prog.Decls = []*ast.Decl{{Name: "shape", Value: &ast.AppExpr{
+			Func: &ast.VarExpr{Name: "Circle"},
+			Arg:  &ast.IntExpr{Value: 1},
+		}}}
+		return prog
+	}
+
+	tests := []struct {
+		name          string
+		input         *ast.Program
+		expectedError string
+	}{
+		{
+			name: "missing constructor",
+			input: withShape(matchProgram(shape,
+				ast.MatchArm{Pattern: conPattern("Circle", varPattern("r")), Body: &ast.VarExpr{Name: "r"}},
+			)),
+			expectedError: "3:1: non-exhaustive match: missing Rect _ _",
+		},
+		{
+			name: "missing nested constructor",
+			input: withShape(matchProgram(&ast.AppExpr{
+				Func: &ast.AppExpr{Func: &ast.VarExpr{Name: "Pair"}, Arg: shape},
+				Arg:  &ast.BoolExpr{Value: true},
+			},
+				ast.MatchArm{Pattern: conPattern("Pair", conPattern("Circle", &ast.WildcardPattern{}), &ast.WildcardPattern{}), Body: &ast.IntExpr{Value: 0}},
+				ast.MatchArm{Pattern: conPattern("Pair", &ast.WildcardPattern{}, &ast.LiteralPattern{Value: &ast.BoolExpr{Value: true}}), Body: &ast.IntExpr{Value: 1}},
+			)),
+			expectedError: "3:1: non-exhaustive match: missing Pair (Rect _ _) false",
+		},
+		{
+			name: "missing integers",
+			input: matchProgram(&ast.IntExpr{Value: 1},
+				ast.MatchArm{Pattern: &ast.LiteralPattern{Value: &ast.IntExpr{Value: 0}}, Body: &ast.IntExpr{Value: 0}},
+			),
+			expectedError: "3:1: non-exhaustive match: missing _",
+		},
+		{
+			name: "undefined constructor",
+			input: withShape(matchProgram(shape,
+				ast.MatchArm{Pattern: conPattern("Square", varPattern("a")), Body: &ast.VarExpr{Name: "a"}},
+			)),
+			expectedError: "3:1: undefined constructor: Square",
+		},
+		{
+			name: "constructor arity",
+			input: withShape(matchProgram(shape,
+				ast.MatchArm{Pattern: conPattern("Rect", varPattern("w")), Body: &ast.VarExpr{Name: "w"}},
+			)),
+			expectedError: "3:1: constructor Rect expects 2 arguments, got 1",
+		},
+		{
+			name: "pattern of another type",
+			input: withShape(matchProgram(shape,
+				ast.MatchArm{Pattern: &ast.LiteralPattern{Value: &ast.IntExpr{Pos: pos(4, 3), Value: 0}}, Body: &ast.IntExpr{Value: 0}},
+			)),
+			expectedError: "4:3: type mismatch in pattern: expected Shape, got Int",
+		},
+		{
+			name: "variable bound twice",
+			input: withShape(matchProgram(shape,
+				ast.MatchArm{Pattern: conPattern("Rect", varPattern("w"), varPattern("w")), Body: &ast.VarExpr{Name: "w"}},
+			)),
+			expectedError: "3:1: variable w is bound more than once in pattern",
+		},
+		{
+			name: "arms of different types",
+			input: withShape(matchProgram(shape,
+				ast.MatchArm{Pattern: conPattern("Circle", varPattern("r")), Body: &ast.VarExpr{Name: "r"}},
+				ast.MatchArm{Pattern: &ast.WildcardPattern{}, Body: &ast.BoolExpr{Value: true}},
+			)),
+			expectedError: "5:3: type mismatch in match arms: expected Int, got Bool",
+		},
+		{
+			name: "duplicate constructor",
+			input: &ast.Program{
+				Types: append(shapeTypes[:1:1], &ast.TypeDecl{
+					Pos:          pos(3, 1),
+					Name:         "Box",
+					Constructors: []*ast.Constructor{{Pos: pos(3, 12), Name: "Circle"}},
+				}),
+				Main: &ast.IntExpr{Value: 0},
+			},
+			expectedError: "3:12: constructor Circle is already declared",
+		},
+		{
+			name: "undefined type in constructor",
+			input: &ast.Program{
+				Types: []*ast.TypeDecl{{
+					Pos:          pos(1, 1),
+					Name:         "Box",
+					Constructors: []*ast.Constructor{{Pos: pos(1, 12), Name: "Box", Params: []ast.Type{&ast.TypeVar{Name: "Shape"}}}},
+				}},
+				Main: &ast.IntExpr{Value: 0},
+			},
+			expectedError: "1:12: undefined type variable: Shape",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CheckProgram(tt.input)
+
+			if err == nil {
+				t.Errorf("expected error, but got nil")
+			} else if err.Error() != tt.expectedError {
+				t.Errorf("error mismatch: got %v, want %v", err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestCheckBidirectional(t *testing.T) {
+	intToInt := &ast.FuncType{From: &ast.IntType{}, To: &ast.IntType{}}
+	tests := []struct {
+		name     string
+		input    ast.Expr
+		expected ast.Type
+	}{
+		{
+			name: "unannotated lambda against annotation",
+			input: &ast.LetExpr{
+				Pos:  pos(1, 1),
+				Name: "f",
+				Type: intToInt,
+				Value: &ast.AbsExpr{
+					Pos:   pos(1, 22),
+					Param: "x",
+					Body:  &ast.VarExpr{Pos: pos(1, 26), Name: "x"},
+				},
+				Body: &ast.VarExpr{Pos: pos(1, 31), Name: "f"},
+			},
+			expected: intToInt,
+		},
+		{
+			name: "unannotated lambda as argument",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:       pos(1, 2),
+					Param:     "f",
+					ParamType: intToInt,
+					Body: &ast.AppExpr{
+						Pos:  pos(1, 15),
+						Func: &ast.VarExpr{Pos: pos(1, 15), Name: "f"},
+						Arg:  &ast.IntExpr{Pos: pos(1, 17), Value: 1},
+					},
+				},
+				Arg: &ast.AbsExpr{
+					Pos:   pos(1, 21),
+					Param: "x",
+					Body:  &ast.VarExpr{Pos: pos(1, 25), Name: "x"},
+				},
+			},
+			expected: &ast.IntType{},
+		},
+		{
+			name: "lambdas in if branches",
+			input: &ast.LetExpr{
+				Pos:  pos(1, 1),
+				Name: "f",
+				Type: intToInt,
+				Value: &ast.IfExpr{
+					Pos:  pos(1, 22),
+					Cond: &ast.BoolExpr{Pos: pos(1, 25), Value: true},
+					Then: &ast.AbsExpr{
+						Pos:   pos(1, 35),
+						Param: "x",
+						Body:  &ast.VarExpr{Pos: pos(1, 39), Name: "x"},
+					},
+					Else: &ast.AbsExpr{
+						Pos:   pos(1, 46),
+						Param: "y",
+						Body:  &ast.IntExpr{Pos: pos(1, 50), Value: 0},
+					},
+				},
+				Body: &ast.VarExpr{Pos: pos(1, 55), Name: "f"},
+			},
+			expected: intToInt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typedExpr, err := CheckBidirectional(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := typedExpr.Type(); !got.Equal(tt.expected) {
+				t.Errorf("type mismatch: got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckBidirectionalErrors(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              ast.Expr
+		expectedCheckError string
+		expectedError      string
+	}{
+		{
+			name: "argument mismatch",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:       pos(1, 2),
+					Param:     "x",
+					ParamType: &ast.IntType{},
+					Body:      &ast.VarExpr{Pos: pos(1, 9), Name: "x"},
+				},
+				Arg: &ast.BoolExpr{Pos: pos(1, 12), Value: true},
+			},
+			expectedCheckError: "1:1: type mismatch in application: expected Int, got Bool",
+			expectedError:      "1:12: type mismatch in application: expected Int, got Bool",
+		},
+		{
+			name: "tuple element mismatch",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:       pos(1, 2),
+					Param:     "p",
+					ParamType: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}}},
+					Body:      &ast.VarExpr{Pos: pos(1, 15), Name: "p"},
+				},
+				Arg: &ast.TupleExpr{
+					Pos: pos(1, 18),
+					Elems: []ast.Expr{
+						&ast.IntExpr{Pos: pos(1, 19), Value: 1},
+						&ast.IntExpr{Pos: pos(1, 22), Value: 2},
+					},
+				},
+			},
+			expectedCheckError: "1:1: type mismatch in application: expected (Int*Bool), got (Int*Int): Bool is incompatible with Int",
+			expectedError:      "1:22: type mismatch in application: expected Bool, got Int",
+		},
+		{
+			name: "list element mismatch",
+			input: &ast.AppExpr{
+				Pos: pos(1, 1),
+				Func: &ast.AbsExpr{
+					Pos:       pos(1, 2),
+					Param:     "xs",
+					ParamType: &ast.ListType{Elem: &ast.IntType{}},
+					Body:      &ast.VarExpr{Pos: pos(1, 16), Name: "xs"},
+				},
+				Arg: &ast.ListExpr{
+					Pos: pos(1, 20),
+					Elems: []ast.Expr{
+						&ast.IntExpr{Pos: pos(1, 21), Value: 1},
+						&ast.BoolExpr{Pos: pos(1, 24), Value: true},
+					},
+				},
+			},
+			expectedCheckError: "1:20: type mismatch in list elements: expected Int, got Bool",
+			expectedError:      "1:24: type mismatch in application: expected Int, got Bool",
+		},
+		{
+			name: "mismatch inside lambda body",
+			input: &ast.LetExpr{
+				Pos:  pos(1, 1),
+				Name: "f",
+				Type: &ast.FuncType{From: &ast.IntType{}, To: &ast.BoolType{}},
+				Value: &ast.AbsExpr{
+					Pos:   pos(1, 23),
+					Param: "x",
+					Body: &ast.IfExpr{
+						Pos:  pos(1, 27),
+						Cond: &ast.BoolExpr{Pos: pos(1, 30), Value: true},
+						Then: &ast.VarExpr{Pos: pos(1, 40), Name: "x"},
+						Else: &ast.BoolExpr{Pos: pos(1, 47), Value: false},
+					},
+				},
+				Body: &ast.VarExpr{Pos: pos(1, 56), Name: "f"},
+			},
+			expectedCheckError: "1:1: type mismatch in let binding: expected (Int->Bool), got (Bool->Bool): Int is incompatible with Bool",
+			expectedError:      "1:40: type mismatch in let binding: expected Bool, got Int",
+		},
+		{
+			name: "parameter annotation mismatch",
+			input: &ast.LetExpr{
+				Pos:  pos(1, 1),
+				Name: "f",
+				Type: &ast.FuncType{From: &ast.IntType{}, To: &ast.IntType{}},
+				Value: &ast.AbsExpr{
+					Pos:       pos(1, 22),
+					Param:     "x",
+					ParamType: &ast.BoolType{},
+					Body:      &ast.IntExpr{Pos: pos(1, 31), Value: 1},
+				},
+				Body: &ast.VarExpr{Pos: pos(1, 36), Name: "f"},
+			},
+			expectedCheckError: "1:1: type mismatch in let binding: expected (Int->Int), got (Bool->Int): Int is incompatible with Bool",
+			expectedError:      "1:22: type mismatch in let binding: expected Int, got Bool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Check(tt.input); err == nil {
+				t.Errorf("expected error from Check, but got nil")
+			} else if err.Error() != tt.expectedCheckError {
+				t.Errorf("Check error mismatch: got %v, want %v", err.Error(), tt.expectedCheckError)
+			}
+
+			if _, err := CheckBidirectional(tt.input); err == nil {
+				t.Errorf("expected error, but got nil")
+			} else if err.Error() != tt.expectedError {
+				t.Errorf("error mismatch: got %v, want %v", err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestTypesEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		t1    ast.Type
+		t2    ast.Type
+		equal bool
+	}{
+		{
+			name:  "same boolean types",
+			t1:    &ast.BoolType{},
+			t2:    &ast.BoolType{},
+			equal: true,
+		},
+		{
+			name:  "same integer types",
+			t1:    &ast.IntType{},
+			t2:    &ast.IntType{},
+			equal: true,
+		},
+		{
+			name:  "same string types",
+			t1:    &ast.StringType{},
+			t2:    &ast.StringType{},
+			equal: true,
+		},
+		{
+			name:  "different base types",
+			t1:    &ast.CharType{},
+			t2:    &ast.StringType{},
+			equal: false,
+		},
+		{
+			name:  "unit and float types",
+			t1:    &ast.UnitType{},
+			t2:    &ast.FloatType{},
+			equal: false,
+		},
+		{
+			name:  "same product types",
+			t1:    &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}}},
+			t2:    &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}}},
+			equal: true,
+		},
+		{
+			name:  "product types with different arity",
+			t1:    &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}}},
+			t2:    &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.BoolType{}, &ast.IntType{}}},
+			equal: false,
+		},
+		{
+			name:  "same list types",
+			t1:    &ast.ListType{Elem: &ast.IntType{}},
+			t2:    &ast.ListType{Elem: &ast.IntType{}},
+			equal: true,
+		},
+		{
+			name:  "list types with different elements",
+			t1:    &ast.ListType{Elem: &ast.IntType{}},
+			t2:    &ast.ListType{Elem: &ast.BoolType{}},
+			equal: false,
+		},
+		{
+			name:  "data types of different names",
+			t1:    &ast.DataType{Name: "Shape"},
+			t2:    &ast.DataType{Name: "Tree"},
+			equal: false,
+		},
+		{
+			name:  "data type and type variable of the same name",
+			t1:    &ast.DataType{Name: "Shape"},
+			t2:    &ast.TypeVar{Name: "Shape"},
+			equal: false,
+		},
+		{
+			name:  "same sum types",
+			t1:    &ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+			t2:    &ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+			equal: true,
+		},
+		{
+			name:  "swapped sum types",
+			t1:    &ast.SumType{Left: &ast.IntType{}, Right: &ast.BoolType{}},
+			t2:    &ast.SumType{Left: &ast.BoolType{}, Right: &ast.IntType{}},
+			equal: false,
+		},
+		{
+			name: "record types with reordered fields",
+			t1: &ast.RecordType{Fields: []ast.Field{
+				{Label: "x", Type: &ast.IntType{}},
+				{Label: "y", Type: &ast.BoolType{}},
+			}},
+			t2: &ast.RecordType{Fields: []ast.Field{
+				{Label: "y", Type: &ast.BoolType{}},
+				{Label: "x", Type: &ast.IntType{}},
+			}},
+			equal: true,
+		},
+		{
+			name: "record types with different fields",
+			t1: &ast.RecordType{Fields: []ast.Field{
+				{Label: "x", Type: &ast.IntType{}},
+			}},
+			t2: &ast.RecordType{Fields: []ast.Field{
+				{Label: "x", Type: &ast.IntType{}},
+				{Label: "y", Type: &ast.BoolType{}},
+			}},
+			equal: false,
+		},
+		{
+			name: "variant types with reordered labels",
+			t1: &ast.VariantType{Fields: []ast.Field{
+				{Label: "some", Type: &ast.IntType{}},
+				{Label: "none", Type: &ast.BoolType{}},
+			}},
+			t2: &ast.VariantType{Fields: []ast.Field{
+				{Label: "none", Type: &ast.BoolType{}},
+				{Label: "some", Type: &ast.IntType{}},
+			}},
+			equal: true,
+		},
+		{
+			name: "record and variant types",
+			t1: &ast.RecordType{Fields: []ast.Field{
+				{Label: "x", Type: &ast.IntType{}},
+			}},
+			t2: &ast.VariantType{Fields: []ast.Field{
+				{Label: "x", Type: &ast.IntType{}},
+			}},
+			equal: false,
+		},
+		{
+			name:  "different base types",
+			t1:    &ast.BoolType{},
+			t2:    &ast.IntType{},
+			equal: false,
+		},
+		{
+			name: "same function types",
+			t1: &ast.FuncType{
+				From: &ast.BoolType{},
+				To:   &ast.IntType{},
+			},
+			t2: &ast.FuncType{
+				From: &ast.BoolType{},
+				To:   &ast.IntType{},
+			},
+			equal: true,
 		},
 		{
 			name: "different function parameter types",
@@ -506,6 +2188,90 @@ func TestTypesEqual(t *testing.T) {
 			},
 			equal: true,
 		},
+		{
+			name: "alpha-equivalent universal types",
+			t1: &ast.ForallType{
+				Var:  "A",
+				Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "A"}},
+			},
+			t2: &ast.ForallType{
+				Var:  "B",
+				Body: &ast.FuncType{From: &ast.TypeVar{Name: "B"}, To: &ast.TypeVar{Name: "B"}},
+			},
+			equal: true,
+		},
+		{
+			name: "universal types binding different positions",
+			t1: &ast.ForallType{
+				Var: "A",
+				Body: &ast.ForallType{
+					Var:  "B",
+					Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "B"}},
+				},
+			},
+			t2: &ast.ForallType{
+				Var: "B",
+				Body: &ast.ForallType{
+					Var:  "A",
+					Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "B"}},
+				},
+			},
+			equal: false,
+		},
+		{
+			name: "alpha-equivalent recursive types",
+			t1: &ast.RecType{
+				Var:  "L",
+				Body: &ast.SumType{Left: &ast.UnitType{}, Right: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "L"}}}},
+			},
+			t2: &ast.RecType{
+				Var:  "M",
+				Body: &ast.SumType{Left: &ast.UnitType{}, Right: &ast.ProductType{Elems: []ast.Type{&ast.IntType{}, &ast.TypeVar{Name: "M"}}}},
+			},
+			equal: true,
+		},
+		{
+			name: "recursive type and its unfolding",
+			t1: &ast.RecType{
+				Var:  "S",
+				Body: &ast.FuncType{From: &ast.UnitType{}, To: &ast.TypeVar{Name: "S"}},
+			},
+			t2: &ast.FuncType{
+				From: &ast.UnitType{},
+				To:   &ast.RecType{Var: "S", Body: &ast.FuncType{From: &ast.UnitType{}, To: &ast.TypeVar{Name: "S"}}},
+			},
+			equal: false,
+		},
+		{
+			name:  "recursive and universal types",
+			t1:    &ast.RecType{Var: "A", Body: &ast.TypeVar{Name: "A"}},
+			t2:    &ast.ForallType{Var: "A", Body: &ast.TypeVar{Name: "A"}},
+			equal: false,
+		},
+		{
+			name: "recursive types binding different positions",
+			t1: &ast.RecType{
+				Var:  "X",
+				Body: &ast.RecType{Var: "Y", Body: &ast.FuncType{From: &ast.TypeVar{Name: "X"}, To: &ast.TypeVar{Name: "Y"}}},
+			},
+			t2: &ast.RecType{
+				Var:  "Y",
+				Body: &ast.RecType{Var: "X", Body: &ast.FuncType{From: &ast.TypeVar{Name: "X"}, To: &ast.TypeVar{Name: "Y"}}},
+			},
+			equal: false,
+		},
+		{
+			name: "universal type and free type variable",
+			t1: &ast.ForallType{
+				Var:  "A",
+				Body: &ast.FuncType{From: &ast.TypeVar{Name: "A"}, To: &ast.TypeVar{Name: "B"}},
+			},
+			t2: &ast.ForallType{
+				Var:  "B",
+				Body: &ast.FuncType{From: &ast.TypeVar{Name: "B"}, To: &ast.TypeVar{Name: "B"}},
+			},
+			equal: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -541,6 +2307,22 @@ func compareTypedExprs(actual, expected ast.TypedExpr) bool {
 		}
 		return a.Value == e.Value && a.Pos == e.Pos
 
+	case *ast.TypedUnitExpr:
+		e, ok := expected.(*ast.TypedUnitExpr)
+		return ok && a.Pos == e.Pos
+
+	case *ast.TypedStringExpr:
+		e, ok := expected.(*ast.TypedStringExpr)
+		return ok && a.Value == e.Value && a.Pos == e.Pos
+
+	case *ast.TypedCharExpr:
+		e, ok := expected.(*ast.TypedCharExpr)
+		return ok && a.Value == e.Value && a.Pos == e.Pos
+
+	case *ast.TypedFloatExpr:
+		e, ok := expected.(*ast.TypedFloatExpr)
+		return ok && a.Value == e.Value && a.Pos == e.Pos
+
 	case *ast.TypedVarExpr:
 		e, ok := expected.(*ast.TypedVarExpr)
 		if !ok {
@@ -584,6 +2366,125 @@ func compareTypedExprs(actual, expected ast.TypedExpr) bool {
 			compareTypedExprs(a.Else, e.Else) &&
 			reflect.DeepEqual(a.Type(), e.Type())
 
+	case *ast.TypedFixExpr:
+		e, ok := expected.(*ast.TypedFixExpr)
+		if !ok {
+			return false
+		}
+		if a.Pos != e.Pos {
+			return false
+		}
+		return compareTypedExprs(a.Func, e.Func) && reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedTupleExpr:
+		e, ok := expected.(*ast.TypedTupleExpr)
+		if !ok || a.Pos != e.Pos || len(a.Elems) != len(e.Elems) {
+			return false
+		}
+		for i := range a.Elems {
+			if !compareTypedExprs(a.Elems[i], e.Elems[i]) {
+				return false
+			}
+		}
+		return reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedProjExpr:
+		e, ok := expected.(*ast.TypedProjExpr)
+		if !ok {
+			return false
+		}
+		if a.Pos != e.Pos || a.Index != e.Index {
+			return false
+		}
+		return compareTypedExprs(a.Tuple, e.Tuple) && reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedInjExpr:
+		e, ok := expected.(*ast.TypedInjExpr)
+		if !ok {
+			return false
+		}
+		if a.Pos != e.Pos || a.Left != e.Left {
+			return false
+		}
+		return compareTypedExprs(a.Value, e.Value) && reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedCaseExpr:
+		e, ok := expected.(*ast.TypedCaseExpr)
+		if !ok {
+			return false
+		}
+		if a.Pos != e.Pos || a.LeftVar != e.LeftVar || a.RightVar != e.RightVar {
+			return false
+		}
+		return compareTypedExprs(a.Scrutinee, e.Scrutinee) &&
+			compareTypedExprs(a.Left, e.Left) &&
+			compareTypedExprs(a.Right, e.Right) &&
+			reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedNilExpr:
+		e, ok := expected.(*ast.TypedNilExpr)
+		return ok && a.Pos == e.Pos && reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedListExpr:
+		e, ok := expected.(*ast.TypedListExpr)
+		if !ok || a.Pos != e.Pos || len(a.Elems) != len(e.Elems) {
+			return false
+		}
+		for i := range a.Elems {
+			if !compareTypedExprs(a.Elems[i], e.Elems[i]) {
+				return false
+			}
+		}
+		return reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedListCaseExpr:
+		e, ok := expected.(*ast.TypedListCaseExpr)
+		if !ok {
+			return false
+		}
+		if a.Pos != e.Pos || a.HeadVar != e.HeadVar || a.TailVar != e.TailVar {
+			return false
+		}
+		return compareTypedExprs(a.Scrutinee, e.Scrutinee) &&
+			compareTypedExprs(a.Nil, e.Nil) &&
+			compareTypedExprs(a.Cons, e.Cons) &&
+			reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedLetExpr:
+		e, ok := expected.(*ast.TypedLetExpr)
+		if !ok {
+			return false
+		}
+		if a.Name != e.Name || a.Pos != e.Pos {
+			return false
+		}
+		return compareTypedExprs(a.Value, e.Value) &&
+			compareTypedExprs(a.Body, e.Body) &&
+			reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedTyAbsExpr:
+		e, ok := expected.(*ast.TypedTyAbsExpr)
+		if !ok {
+			return false
+		}
+		if a.TypeVar != e.TypeVar || a.Pos != e.Pos {
+			return false
+		}
+		return compareTypedExprs(a.Body, e.Body) &&
+			reflect.DeepEqual(a.Type(), e.Type())
+
+	case *ast.TypedTyAppExpr:
+		e, ok := expected.(*ast.TypedTyAppExpr)
+		if !ok {
+			return false
+		}
+		if a.Pos != e.Pos {
+			return false
+		}
+		return compareTypedExprs(a.Func, e.Func) &&
+			reflect.DeepEqual(a.TypeArg, e.TypeArg) &&
+			reflect.DeepEqual(a.Type(), e.Type())
+
 	default:
 		return false
 	}
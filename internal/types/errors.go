@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/shota3506/gostlc/internal/ast"
 	"github.com/shota3506/gostlc/internal/token"
@@ -10,6 +11,7 @@ import (
 // UndefinedVariableError occurs when a variable is not found in the environment.
 type UndefinedVariableError struct {
 	Pos  token.Position
+	End  token.Position
 	Name string
 }
 
@@ -17,12 +19,21 @@ func (e *UndefinedVariableError) Error() string {
 	return fmt.Sprintf("%d:%d: undefined variable: %s", e.Pos.Line, e.Pos.Column, e.Name)
 }
 
+func (e *UndefinedVariableError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
 // TypeMismatchError occurs when expected and actual types don't match.
+// ExpectedSpan locates what determined the expected type and ActualSpan the expression of the actual type,
+// when known.
 type TypeMismatchError struct {
-	Pos      token.Position
-	Expected ast.Type
-	Actual   ast.Type
-	Context  string
+	Pos          token.Position
+	End          token.Position
+	Expected     ast.Type
+	Actual       ast.Type
+	Context      string
+	ExpectedSpan token.Span
+	ActualSpan   token.Span
 }
 
 func (e *TypeMismatchError) Error() string {
@@ -32,9 +43,60 @@ func (e *TypeMismatchError) Error() string {
 	return fmt.Sprintf("%d:%d: type mismatch: expected %s, got %s", e.Pos.Line, e.Pos.Column, e.Expected, e.Actual)
 }
 
+func (e *TypeMismatchError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// UnificationError occurs when expected and actual types don't match in a nested component.
+// Left and Right are the innermost conflicting components of Expected and Actual,
+// and the spans are as for TypeMismatchError.
+type UnificationError struct {
+	Pos          token.Position
+	End          token.Position
+	Expected     ast.Type
+	Actual       ast.Type
+	Left         ast.Type
+	Right        ast.Type
+	Context      string
+	ExpectedSpan token.Span
+	ActualSpan   token.Span
+}
+
+func (e *UnificationError) Error() string {
+	if e.Context != "" {
+		return fmt.Sprintf("%d:%d: type mismatch in %s: expected %s, got %s: %s is incompatible with %s", e.Pos.Line, e.Pos.Column, e.Context, e.Expected, e.Actual, e.Left, e.Right)
+	}
+	return fmt.Sprintf("%d:%d: type mismatch: expected %s, got %s: %s is incompatible with %s", e.Pos.Line, e.Pos.Column, e.Expected, e.Actual, e.Left, e.Right)
+}
+
+func (e *UnificationError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// InfiniteTypeError occurs when unification would make a type variable contain itself.
+type InfiniteTypeError struct {
+	Pos     token.Position
+	End     token.Position
+	Var     *ast.MetaVar
+	Type    ast.Type
+	Context string
+}
+
+func (e *InfiniteTypeError) Error() string {
+	if e.Context != "" {
+		return fmt.Sprintf("%d:%d: infinite type in %s: %s occurs in %s", e.Pos.Line, e.Pos.Column, e.Context, e.Var, e.Type)
+	}
+	return fmt.Sprintf("%d:%d: infinite type: %s occurs in %s", e.Pos.Line, e.Pos.Column, e.Var, e.Type)
+}
+
+func (e *InfiniteTypeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
 // NotAFunctionError occurs when trying to apply a non-function value.
 type NotAFunctionError struct {
 	Pos  token.Position
+	End  token.Position
 	Type ast.Type
 }
 
@@ -42,9 +104,14 @@ func (e *NotAFunctionError) Error() string {
 	return fmt.Sprintf("%d:%d: cannot apply non-function type: %s", e.Pos.Line, e.Pos.Column, e.Type)
 }
 
+func (e *NotAFunctionError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
 // InvalidConditionTypeError occurs when if-expression condition is not boolean.
 type InvalidConditionTypeError struct {
 	Pos  token.Position
+	End  token.Position
 	Type ast.Type
 }
 
@@ -52,11 +119,328 @@ func (e *InvalidConditionTypeError) Error() string {
 	return fmt.Sprintf("%d:%d: condition must be boolean, got %s", e.Pos.Line, e.Pos.Column, e.Type)
 }
 
+func (e *InvalidConditionTypeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NotATupleError occurs when projecting from a non-tuple value.
+type NotATupleError struct {
+	Pos  token.Position
+	End  token.Position
+	Type ast.Type
+}
+
+func (e *NotATupleError) Error() string {
+	return fmt.Sprintf("%d:%d: cannot project from non-tuple type: %s", e.Pos.Line, e.Pos.Column, e.Type)
+}
+
+func (e *NotATupleError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// TupleIndexOutOfRangeError occurs when a projection index exceeds the tuple size.
+type TupleIndexOutOfRangeError struct {
+	Pos   token.Position
+	End   token.Position
+	Index int
+	Type  ast.Type
+}
+
+func (e *TupleIndexOutOfRangeError) Error() string {
+	return fmt.Sprintf("%d:%d: tuple index %d out of range for type: %s", e.Pos.Line, e.Pos.Column, e.Index, e.Type)
+}
+
+func (e *TupleIndexOutOfRangeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NotASumError occurs when a sum type is required but another type is found.
+type NotASumError struct {
+	Pos  token.Position
+	End  token.Position
+	Type ast.Type
+}
+
+func (e *NotASumError) Error() string {
+	return fmt.Sprintf("%d:%d: expected sum type, got %s", e.Pos.Line, e.Pos.Column, e.Type)
+}
+
+func (e *NotASumError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NotAListError occurs when a list type is required but another type is found.
+type NotAListError struct {
+	Pos  token.Position
+	End  token.Position
+	Type ast.Type
+}
+
+func (e *NotAListError) Error() string {
+	return fmt.Sprintf("%d:%d: expected list type, got %s", e.Pos.Line, e.Pos.Column, e.Type)
+}
+
+func (e *NotAListError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NotARecordError occurs when a record type is required but another type is found.
+type NotARecordError struct {
+	Pos  token.Position
+	End  token.Position
+	Type ast.Type
+}
+
+func (e *NotARecordError) Error() string {
+	return fmt.Sprintf("%d:%d: expected record type, got %s", e.Pos.Line, e.Pos.Column, e.Type)
+}
+
+func (e *NotARecordError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NotAVariantError occurs when a variant type is required but another type is found.
+type NotAVariantError struct {
+	Pos  token.Position
+	End  token.Position
+	Type ast.Type
+}
+
+func (e *NotAVariantError) Error() string {
+	return fmt.Sprintf("%d:%d: expected variant type, got %s", e.Pos.Line, e.Pos.Column, e.Type)
+}
+
+func (e *NotAVariantError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// UnknownLabelError occurs when a label is not part of a record or variant type.
+type UnknownLabelError struct {
+	Pos   token.Position
+	End   token.Position
+	Label string
+	Type  ast.Type
+}
+
+func (e *UnknownLabelError) Error() string {
+	return fmt.Sprintf("%d:%d: label %s not found in type: %s", e.Pos.Line, e.Pos.Column, e.Label, e.Type)
+}
+
+func (e *UnknownLabelError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NonExhaustiveCaseError occurs when a case analysis does not cover every alternative.
+type NonExhaustiveCaseError struct {
+	Pos     token.Position
+	End     token.Position
+	Missing []string
+}
+
+func (e *NonExhaustiveCaseError) Error() string {
+	return fmt.Sprintf("%d:%d: non-exhaustive case analysis: missing %s", e.Pos.Line, e.Pos.Column, strings.Join(e.Missing, ", "))
+}
+
+func (e *NonExhaustiveCaseError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// IntLiteralRangeError occurs when an integer literal is out of the range of Int with 64-bit integers.
+type IntLiteralRangeError struct {
+	Pos   token.Position
+	End   token.Position
+	Value string
+}
+
+func (e *IntLiteralRangeError) Error() string {
+	return fmt.Sprintf("%d:%d: integer literal out of the range of Int: %s", e.Pos.Line, e.Pos.Column, e.Value)
+}
+
+func (e *IntLiteralRangeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// UndefinedTypeVariableError occurs when a type annotation refers to a type variable not bound by an enclosing type abstraction.
+type UndefinedTypeVariableError struct {
+	Pos  token.Position
+	End  token.Position
+	Name string
+}
+
+func (e *UndefinedTypeVariableError) Error() string {
+	return fmt.Sprintf("%d:%d: undefined type variable: %s", e.Pos.Line, e.Pos.Column, e.Name)
+}
+
+func (e *UndefinedTypeVariableError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NotAForallError occurs when a type is applied to an expression of non-polymorphic type.
+type NotAForallError struct {
+	Pos  token.Position
+	End  token.Position
+	Type ast.Type
+}
+
+func (e *NotAForallError) Error() string {
+	return fmt.Sprintf("%d:%d: cannot apply type to non-polymorphic type: %s", e.Pos.Line, e.Pos.Column, e.Type)
+}
+
+func (e *NotAForallError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NotARecTypeError occurs when a type given to fold or unfold is not a recursive type.
+type NotARecTypeError struct {
+	Pos  token.Position
+	End  token.Position
+	Type ast.Type
+}
+
+func (e *NotARecTypeError) Error() string {
+	return fmt.Sprintf("%d:%d: expected recursive type, got %s", e.Pos.Line, e.Pos.Column, e.Type)
+}
+
+func (e *NotARecTypeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// TypeVariableEscapeError occurs when a type variable bound by a type abstraction
+// becomes part of the type of a variable outside of it.
+type TypeVariableEscapeError struct {
+	Pos  token.Position
+	End  token.Position
+	Name string
+}
+
+func (e *TypeVariableEscapeError) Error() string {
+	return fmt.Sprintf("%d:%d: type variable %s escapes its scope", e.Pos.Line, e.Pos.Column, e.Name)
+}
+
+func (e *TypeVariableEscapeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// DuplicateDeclarationError occurs when a data type or constructor is declared more than once.
+// Kind is "type" or "constructor".
+type DuplicateDeclarationError struct {
+	Pos  token.Position
+	End  token.Position
+	Kind string
+	Name string
+}
+
+func (e *DuplicateDeclarationError) Error() string {
+	return fmt.Sprintf("%d:%d: %s %s is already declared", e.Pos.Line, e.Pos.Column, e.Kind, e.Name)
+}
+
+func (e *DuplicateDeclarationError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// UndefinedConstructorError occurs when a pattern refers to a constructor not declared by any data type.
+type UndefinedConstructorError struct {
+	Pos  token.Position
+	End  token.Position
+	Name string
+}
+
+func (e *UndefinedConstructorError) Error() string {
+	return fmt.Sprintf("%d:%d: undefined constructor: %s", e.Pos.Line, e.Pos.Column, e.Name)
+}
+
+func (e *UndefinedConstructorError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// ConstructorArityError occurs when a constructor pattern has a different number of arguments than the constructor.
+type ConstructorArityError struct {
+	Pos      token.Position
+	End      token.Position
+	Name     string
+	Expected int
+	Actual   int
+}
+
+func (e *ConstructorArityError) Error() string {
+	return fmt.Sprintf("%d:%d: constructor %s expects %d arguments, got %d", e.Pos.Line, e.Pos.Column, e.Name, e.Expected, e.Actual)
+}
+
+func (e *ConstructorArityError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// DuplicatePatternVariableError occurs when a pattern binds the same variable more than once.
+type DuplicatePatternVariableError struct {
+	Pos  token.Position
+	End  token.Position
+	Name string
+}
+
+func (e *DuplicatePatternVariableError) Error() string {
+	return fmt.Sprintf("%d:%d: variable %s is bound more than once in pattern", e.Pos.Line, e.Pos.Column, e.Name)
+}
+
+func (e *DuplicatePatternVariableError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// NonExhaustiveMatchError occurs when the arms of a match do not cover every value of the scrutinee.
+// Missing are patterns of values matched by no arm, such as "Rect _ _".
+type NonExhaustiveMatchError struct {
+	Pos     token.Position
+	End     token.Position
+	Missing []string
+}
+
+func (e *NonExhaustiveMatchError) Error() string {
+	return fmt.Sprintf("%d:%d: non-exhaustive match: missing %s", e.Pos.Line, e.Pos.Column, strings.Join(e.Missing, ", "))
+}
+
+func (e *NonExhaustiveMatchError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// RedundantArmWarning is reported for an arm of a match that matches no value left unmatched by the arms before it.
+// It is a warning and does not prevent the program from running.
+type RedundantArmWarning struct {
+	Pos token.Position
+	End token.Position
+}
+
+func (e *RedundantArmWarning) Error() string {
+	return fmt.Sprintf("%d:%d: redundant match arm: the arms before it match every value it matches", e.Pos.Line, e.Pos.Column)
+}
+
+func (e *RedundantArmWarning) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
 type UnknownExprTypeError struct {
 	Pos  token.Position
+	End  token.Position
 	Expr ast.Expr
 }
 
 func (e *UnknownExprTypeError) Error() string {
 	return fmt.Sprintf("%d:%d: unknown expression type: %T", e.Pos.Line, e.Pos.Column, e.Expr)
 }
+
+func (e *UnknownExprTypeError) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
+}
+
+// ErrorList is a list of type errors in the order they were found.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (l ErrorList) Unwrap() []error {
+	return l
+}
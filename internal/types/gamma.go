@@ -1,12 +1,12 @@
 package types
 
 import (
-	"github.com/shota3506/gostlc/internal/ast"
 	"github.com/shota3506/gostlc/internal/environment"
 )
 
-type Gamma = environment.Environment[ast.Type]
+// Gamma maps names to their type schemes.
+type Gamma = environment.Environment[*Scheme]
 
 func NewGamma() *Gamma {
-	return environment.NewEnvironment[ast.Type]()
+	return environment.NewEnvironment[*Scheme]()
 }
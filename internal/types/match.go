@@ -0,0 +1,226 @@
+package types
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/shota3506/gostlc/internal/ast"
+)
+
+// The coverage of the arms of a match is checked by computing the usefulness of patterns
+// as described by Maranget in "Warnings for pattern matching". A pattern is useful with respect
+// to the patterns before it if some value matched by it is matched by none of them:
+// an arm whose pattern is not useful is redundant, and the match is exhaustive
+// if a wildcard after the last arm is not useful.
+
+// shape is a pattern reduced to what matters for coverage: a wildcard if con is empty,
+// or a constructor or literal named by con applied to args.
+// Variables are wildcards, and a literal is named by its key such as 42 or "a",
+// which cannot be mistaken for a constructor name.
+type shape struct {
+	con  string
+	args []shape
+}
+
+var wildcard = shape{}
+
+func (s shape) String() string {
+	if s.con == "" {
+		return "_"
+	}
+	var b strings.Builder
+	b.WriteString(s.con)
+	for _, arg := range s.args {
+		b.WriteByte(' ')
+		if len(arg.args) > 0 {
+			b.WriteString("(" + arg.String() + ")")
+		} else {
+			b.WriteString(arg.String())
+		}
+	}
+	return b.String()
+}
+
+func shapeOf(pattern ast.Pattern) shape {
+	switch p := pattern.(type) {
+	case *ast.LiteralPattern:
+		return shape{con: p.Key()}
+	case *ast.ConstructorPattern:
+		args := make([]shape, len(p.Args))
+		for i, arg := range p.Args {
+			args[i] = shapeOf(arg)
+		}
+		return shape{con: p.Name, args: args}
+	default:
+		return wildcard
+	}
+}
+
+// checkCoverage reports the arms of expr matching no value left by the arms before them as warnings,
+// and returns an error naming the values matched by no arm if there are any.
+// The patterns of expr must have type checked.
+func (c *checker) checkCoverage(expr *ast.MatchExpr) error {
+	var rows [][]shape
+	for _, arm := range expr.Arms {
+		row := []shape{shapeOf(arm.Pattern)}
+		if !c.useful(rows, row) {
+			c.warnings = append(c.warnings, &RedundantArmWarning{Pos: arm.Pos, End: arm.End})
+		}
+		rows = append(rows, row)
+	}
+
+	witnesses := c.uncovered(rows, 1)
+	if len(witnesses) == 0 {
+		return nil
+	}
+	missing := make([]string, 0, len(witnesses))
+	for _, w := range witnesses {
+		if s := w[0].String(); !slices.Contains(missing, s) {
+			missing = append(missing, s)
+		}
+	}
+	return &NonExhaustiveMatchError{
+		Pos:     expr.Pos,
+		End:     expr.End,
+		Missing: missing,
+	}
+}
+
+// signature returns the constructors of the type of a column whose patterns start with heads,
+// and whether heads include all of them. The literals of Int, String and Char are never all included.
+func (c *checker) signature(heads []string) (all []shape, complete bool) {
+	if len(heads) == 0 {
+		return nil, false
+	}
+	switch heads[0] {
+	case "true", "false":
+		all = []shape{{con: "true"}, {con: "false"}}
+	case "()":
+		all = []shape{{con: "()"}}
+	default:
+		con, ok := c.constructors[heads[0]]
+		if !ok {
+			return nil, false
+		}
+		for _, k := range con.typ.constructors {
+			all = append(all, shape{con: k.name, args: slices.Repeat([]shape{wildcard}, len(k.params))})
+		}
+	}
+	for _, k := range all {
+		if !slices.Contains(heads, k.con) {
+			return all, false
+		}
+	}
+	return all, true
+}
+
+// heads returns the constructors and literals that the rows start with, in order of appearance.
+func heads(rows [][]shape) []string {
+	var cons []string
+	for _, row := range rows {
+		if con := row[0].con; con != "" && !slices.Contains(cons, con) {
+			cons = append(cons, con)
+		}
+	}
+	return cons
+}
+
+// specialize returns the rows matching a value built by con with n arguments,
+// with the first pattern replaced by the patterns of the arguments.
+func specialize(rows [][]shape, con string, n int) [][]shape {
+	var result [][]shape
+	for _, row := range rows {
+		switch row[0].con {
+		case con:
+			result = append(result, append(slices.Clone(row[0].args), row[1:]...))
+		case "":
+			result = append(result, append(slices.Repeat([]shape{wildcard}, n), row[1:]...))
+		}
+	}
+	return result
+}
+
+// defaults returns the rows starting with a wildcard without their first pattern,
+// which match the values built by the constructors none of the rows start with.
+func defaults(rows [][]shape) [][]shape {
+	var result [][]shape
+	for _, row := range rows {
+		if row[0].con == "" {
+			result = append(result, row[1:])
+		}
+	}
+	return result
+}
+
+// useful reports whether some values matched by q are matched by none of the rows.
+func (c *checker) useful(rows [][]shape, q []shape) bool {
+	if len(q) == 0 {
+		return len(rows) == 0
+	}
+
+	if q[0].con != "" {
+		n := len(q[0].args)
+		return c.useful(specialize(rows, q[0].con, n), append(slices.Clone(q[0].args), q[1:]...))
+	}
+
+	all, complete := c.signature(heads(rows))
+	if !complete {
+		return c.useful(defaults(rows), q[1:])
+	}
+	for _, k := range all {
+		n := len(k.args)
+		if c.useful(specialize(rows, k.con, n), append(slices.Repeat([]shape{wildcard}, n), q[1:]...)) {
+			return true
+		}
+	}
+	return false
+}
+
+// uncovered returns patterns of the values of n columns matched by none of the rows,
+// naming each constructor missing from a column.
+func (c *checker) uncovered(rows [][]shape, n int) [][]shape {
+	if n == 0 {
+		if len(rows) == 0 {
+			return [][]shape{{}}
+		}
+		return nil
+	}
+
+	cons := heads(rows)
+	all, complete := c.signature(cons)
+	if complete {
+		var witnesses [][]shape
+		for _, k := range all {
+			arity := len(k.args)
+			for _, w := range c.uncovered(specialize(rows, k.con, arity), arity+n-1) {
+				head := shape{con: k.con, args: w[:arity]}
+				witnesses = append(witnesses, append([]shape{head}, w[arity:]...))
+			}
+		}
+		return witnesses
+	}
+
+	rest := c.uncovered(defaults(rows), n-1)
+	if len(rest) == 0 {
+		return nil
+	}
+
+	// The values left are those of the constructors missing from the column,
+	// or any value if the constructors of its type are unknown or infinitely many
+	missing := []shape{wildcard}
+	if len(cons) > 0 && len(all) > 0 {
+		missing = nil
+		for _, k := range all {
+			if !slices.Contains(cons, k.con) {
+				missing = append(missing, k)
+			}
+		}
+	}
+	var witnesses [][]shape
+	for _, k := range missing {
+		for _, w := range rest {
+			witnesses = append(witnesses, append([]shape{k}, w...))
+		}
+	}
+	return witnesses
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"github.com/shota3506/gostlc/internal/ast"
+)
+
+// normalizer renames the unsolved type variables it has seen to 'a, 'b, ... in the order they were seen,
+// so that printed types do not depend on how many type variables inference created.
+type normalizer struct {
+	// offset is above the number of every type variable to rename.
+	offset int
+	// ids maps the number of each type variable seen to the number it is renamed to.
+	ids map[int]int
+}
+
+func (c *checker) newNormalizer() *normalizer {
+	return &normalizer{offset: c.nextID, ids: map[int]int{}}
+}
+
+// see records the unsolved type variables of t in order of occurrence.
+func (n *normalizer) see(t ast.Type) {
+	for _, m := range metaVars(t, nil) {
+		n.seeID(m.ID)
+	}
+}
+
+func (n *normalizer) seeID(id int) {
+	if _, ok := n.ids[id]; !ok {
+		n.ids[id] = len(n.ids)
+	}
+}
+
+// substs returns the substitutions renaming the type variables seen when applied one after the other.
+// They rename through the type variables numbered from offset, because a substitution is applied
+// repeatedly and a type variable may be renamed to the former number of another.
+func (n *normalizer) substs() (through, to Subst) {
+	through, to = Subst{}, Subst{}
+	for id, i := range n.ids {
+		through[id] = &ast.MetaVar{ID: n.offset + i}
+		to[n.offset+i] = &ast.MetaVar{ID: i}
+	}
+	return through, to
+}
+
+func (n *normalizer) apply(t ast.Type) ast.Type {
+	through, to := n.substs()
+	return to.Apply(through.Apply(t))
+}
+
+func (n *normalizer) applyExpr(expr ast.TypedExpr) ast.TypedExpr {
+	through, to := n.substs()
+	return to.ApplyExpr(through.ApplyExpr(expr))
+}
+
+// normalizeExprs renames the unsolved type variables of the typed expressions, in which the solved ones
+// have been replaced, in order of occurrence in their types and then of creation for those of subexpressions.
+func (c *checker) normalizeExprs(exprs ...ast.TypedExpr) []ast.TypedExpr {
+	n := c.newNormalizer()
+	for _, expr := range exprs {
+		n.see(expr.Type())
+	}
+	for id := range c.nextID {
+		if _, ok := c.subst[id]; !ok {
+			n.seeID(id)
+		}
+	}
+
+	normalized := make([]ast.TypedExpr, len(exprs))
+	for i, expr := range exprs {
+		normalized[i] = n.applyExpr(expr)
+	}
+	return normalized
+}
+
+// normalizeError renames the unsolved type variables of the types err mentions in order of occurrence.
+func (c *checker) normalizeError(err error) {
+	n := c.newNormalizer()
+	if e, ok := err.(*InfiniteTypeError); ok {
+		n.see(e.Var)
+		n.see(e.Type)
+		e.Var = n.apply(e.Var).(*ast.MetaVar)
+		e.Type = n.apply(e.Type)
+		return
+	}
+
+	types := errorTypes(err)
+	for _, t := range types {
+		n.see(*t)
+	}
+	for _, t := range types {
+		*t = n.apply(*t)
+	}
+}
+
+// errorTypes returns the types err mentions in the order its message shows them.
+func errorTypes(err error) []*ast.Type {
+	switch e := err.(type) {
+	case *TypeMismatchError:
+		return []*ast.Type{&e.Expected, &e.Actual}
+	case *UnificationError:
+		return []*ast.Type{&e.Expected, &e.Actual, &e.Left, &e.Right}
+	case *NotAFunctionError:
+		return []*ast.Type{&e.Type}
+	case *InvalidConditionTypeError:
+		return []*ast.Type{&e.Type}
+	case *NotATupleError:
+		return []*ast.Type{&e.Type}
+	case *TupleIndexOutOfRangeError:
+		return []*ast.Type{&e.Type}
+	case *NotASumError:
+		return []*ast.Type{&e.Type}
+	case *NotAListError:
+		return []*ast.Type{&e.Type}
+	case *NotARecordError:
+		return []*ast.Type{&e.Type}
+	case *NotAVariantError:
+		return []*ast.Type{&e.Type}
+	case *UnknownLabelError:
+		return []*ast.Type{&e.Type}
+	case *NotAForallError:
+		return []*ast.Type{&e.Type}
+	case *NotARecTypeError:
+		return []*ast.Type{&e.Type}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shota3506/gostlc/internal/ast"
+)
+
+// Scheme represents a type quantified over type variables: forall a b. T.
+// Each use of a name bound to a scheme instantiates the quantified variables with fresh type variables.
+type Scheme struct {
+	Vars []string
+	Type ast.Type
+}
+
+// Mono returns a scheme without quantified variables.
+func Mono(t ast.Type) *Scheme {
+	return &Scheme{Type: t}
+}
+
+// Generic returns a scheme quantified over all named type variables of t.
+func Generic(t ast.Type) *Scheme {
+	return &Scheme{Vars: typeVars(t, nil), Type: t}
+}
+
+func (s *Scheme) String() string {
+	if len(s.Vars) == 0 {
+		return s.Type.String()
+	}
+	return fmt.Sprintf("forall %s. %s", strings.Join(s.Vars, " "), s.Type)
+}
+
+// instantiate replaces the quantified variables of s with fresh type variables.
+func (c *checker) instantiate(s *Scheme) ast.Type {
+	if len(s.Vars) == 0 {
+		return s.Type
+	}
+	vars := make(map[string]ast.Type, len(s.Vars))
+	for _, name := range s.Vars {
+		vars[name] = c.fresh()
+	}
+	return replaceTypeVars(s.Type, vars)
+}
+
+// generalize quantifies t over the type variables introduced at a deeper level than the current one,
+// which are not referenced from the enclosing context.
+func (c *checker) generalize(t ast.Type) *Scheme {
+	t = c.subst.Apply(t)
+
+	var names []string
+	quantified := Subst{}
+	for _, m := range metaVars(t, nil) {
+		if c.levels[m.ID] <= c.level {
+			continue
+		}
+		if _, ok := quantified[m.ID]; ok {
+			continue
+		}
+		name := ast.FreshTypeVar(varName(len(names)), t)
+		names = append(names, name)
+		quantified[m.ID] = &ast.TypeVar{Name: name}
+	}
+	return &Scheme{Vars: names, Type: quantified.Apply(t)}
+}
+
+// varName returns the i-th type variable name: a, b, ..., z, a1, b1, ...
+func varName(i int) string {
+	name := string(rune('a' + i%26))
+	if i >= 26 {
+		name += fmt.Sprint(i / 26)
+	}
+	return name
+}
+
+// replaceTypeVars returns t with the free occurrences of the named type variables replaced by the given types.
+// Bound type variables are renamed where needed so that no free type variable of a replacement is captured.
+func replaceTypeVars(t ast.Type, vars map[string]ast.Type) ast.Type {
+	switch t := t.(type) {
+	case *ast.TypeVar:
+		if u, ok := vars[t.Name]; ok {
+			return u
+		}
+		return t
+	case *ast.ForallType:
+		v, body := replaceBoundTypeVars(t.Var, t.Body, vars)
+		return &ast.ForallType{Var: v, Body: body}
+	case *ast.RecType:
+		v, body := replaceBoundTypeVars(t.Var, t.Body, vars)
+		return &ast.RecType{Var: v, Body: body}
+	case *ast.FuncType:
+		return &ast.FuncType{
+			From: replaceTypeVars(t.From, vars),
+			To:   replaceTypeVars(t.To, vars),
+		}
+	case *ast.ProductType:
+		elems := make([]ast.Type, len(t.Elems))
+		for i, elem := range t.Elems {
+			elems[i] = replaceTypeVars(elem, vars)
+		}
+		return &ast.ProductType{Elems: elems}
+	case *ast.SumType:
+		return &ast.SumType{
+			Left:  replaceTypeVars(t.Left, vars),
+			Right: replaceTypeVars(t.Right, vars),
+		}
+	case *ast.ListType:
+		return &ast.ListType{Elem: replaceTypeVars(t.Elem, vars)}
+	case *ast.RecordType:
+		return &ast.RecordType{Fields: replaceFieldTypeVars(t.Fields, vars)}
+	case *ast.VariantType:
+		return &ast.VariantType{Fields: replaceFieldTypeVars(t.Fields, vars)}
+	default:
+		return t
+	}
+}
+
+// replaceBoundTypeVars replaces the named type variables in the body of a quantified or recursive type
+// binding the type variable v, and returns the bound variable and the body replaced.
+func replaceBoundTypeVars(v string, body ast.Type, vars map[string]ast.Type) (string, ast.Type) {
+	// The bound variable shadows a replacement of the same name
+	inner := make(map[string]ast.Type, len(vars))
+	avoid := []ast.Type{body}
+	for name, u := range vars {
+		if name != v {
+			inner[name] = u
+			avoid = append(avoid, u, &ast.TypeVar{Name: name})
+		}
+	}
+	if len(inner) == 0 {
+		return v, body
+	}
+
+	// Rename the bound variable if a replacement mentions it freely
+	for _, u := range inner {
+		if slices.Contains(typeVars(u, nil), v) {
+			renamed := ast.FreshTypeVar(v, avoid...)
+			body = replaceTypeVars(body, map[string]ast.Type{v: &ast.TypeVar{Name: renamed}})
+			v = renamed
+			break
+		}
+	}
+	return v, replaceTypeVars(body, inner)
+}
+
+func replaceFieldTypeVars(fields []ast.Field, vars map[string]ast.Type) []ast.Field {
+	replaced := make([]ast.Field, len(fields))
+	for i, field := range fields {
+		replaced[i] = ast.Field{Label: field.Label, Type: replaceTypeVars(field.Type, vars)}
+	}
+	return replaced
+}
+
+// typeVars appends the names of the free named type variables of t to acc in order of first occurrence.
+func typeVars(t ast.Type, acc []string) []string {
+	switch t := t.(type) {
+	case *ast.TypeVar:
+		if slices.Contains(acc, t.Name) {
+			return acc
+		}
+		return append(acc, t.Name)
+	case *ast.ForallType:
+		return appendBoundTypeVars(t.Var, t.Body, acc)
+	case *ast.RecType:
+		return appendBoundTypeVars(t.Var, t.Body, acc)
+	}
+	for _, u := range components(t) {
+		acc = typeVars(u, acc)
+	}
+	return acc
+}
+
+// appendBoundTypeVars appends the free named type variables of body other than v to acc.
+func appendBoundTypeVars(v string, body ast.Type, acc []string) []string {
+	for _, name := range typeVars(body, nil) {
+		if name != v && !slices.Contains(acc, name) {
+			acc = append(acc, name)
+		}
+	}
+	return acc
+}
+
+// metaVars appends the type variables to be solved of t to acc in order of occurrence.
+func metaVars(t ast.Type, acc []*ast.MetaVar) []*ast.MetaVar {
+	if m, ok := t.(*ast.MetaVar); ok {
+		return append(acc, m)
+	}
+	for _, u := range components(t) {
+		acc = metaVars(u, acc)
+	}
+	return acc
+}
+
+// components returns the types a compound type t is directly composed of.
+func components(t ast.Type) []ast.Type {
+	switch t := t.(type) {
+	case *ast.FuncType:
+		return []ast.Type{t.From, t.To}
+	case *ast.ProductType:
+		return t.Elems
+	case *ast.SumType:
+		return []ast.Type{t.Left, t.Right}
+	case *ast.ListType:
+		return []ast.Type{t.Elem}
+	case *ast.RecordType:
+		return fieldTypes(t.Fields)
+	case *ast.VariantType:
+		return fieldTypes(t.Fields)
+	case *ast.ForallType:
+		return []ast.Type{t.Body}
+	case *ast.RecType:
+		return []ast.Type{t.Body}
+	default:
+		return nil
+	}
+}
+
+func fieldTypes(fields []ast.Field) []ast.Type {
+	types := make([]ast.Type, len(fields))
+	for i, field := range fields {
+		types[i] = field.Type
+	}
+	return types
+}
@@ -0,0 +1,149 @@
+package types
+
+import (
+	"github.com/shota3506/gostlc/internal/ast"
+)
+
+// Subst is a substitution mapping type variables to the types they are solved to.
+type Subst map[int]ast.Type
+
+// Apply returns the type t with all solved type variables replaced.
+func (s Subst) Apply(t ast.Type) ast.Type {
+	switch t := t.(type) {
+	case *ast.MetaVar:
+		if u, ok := s[t.ID]; ok {
+			return s.Apply(u)
+		}
+		return t
+	case *ast.FuncType:
+		return &ast.FuncType{
+			From: s.Apply(t.From),
+			To:   s.Apply(t.To),
+		}
+	case *ast.ProductType:
+		return &ast.ProductType{Elems: s.applyAll(t.Elems)}
+	case *ast.SumType:
+		return &ast.SumType{
+			Left:  s.Apply(t.Left),
+			Right: s.Apply(t.Right),
+		}
+	case *ast.ListType:
+		return &ast.ListType{Elem: s.Apply(t.Elem)}
+	case *ast.RecordType:
+		return &ast.RecordType{Fields: s.applyFields(t.Fields)}
+	case *ast.VariantType:
+		return &ast.VariantType{Fields: s.applyFields(t.Fields)}
+	case *ast.ForallType:
+		return &ast.ForallType{Var: t.Var, Body: s.Apply(t.Body)}
+	case *ast.RecType:
+		return &ast.RecType{Var: t.Var, Body: s.Apply(t.Body)}
+	default:
+		return t
+	}
+}
+
+func (s Subst) applyAll(ts []ast.Type) []ast.Type {
+	applied := make([]ast.Type, len(ts))
+	for i, t := range ts {
+		applied[i] = s.Apply(t)
+	}
+	return applied
+}
+
+func (s Subst) applyFields(fields []ast.Field) []ast.Field {
+	applied := make([]ast.Field, len(fields))
+	for i, field := range fields {
+		applied[i] = ast.Field{Label: field.Label, Type: s.Apply(field.Type)}
+	}
+	return applied
+}
+
+// resolve follows solved type variables at the top level of t only.
+func (s Subst) resolve(t ast.Type) ast.Type {
+	for {
+		m, ok := t.(*ast.MetaVar)
+		if !ok {
+			return t
+		}
+		u, ok := s[m.ID]
+		if !ok {
+			return t
+		}
+		t = u
+	}
+}
+
+// ApplyExpr returns the typed expression with all solved type variables replaced.
+func (s Subst) ApplyExpr(expr ast.TypedExpr) ast.TypedExpr {
+	switch e := expr.(type) {
+	case *ast.TypedVarExpr:
+		return ast.NewTypedVarExpr(s.Apply(e.Type()), &e.VarExpr)
+	case *ast.TypedAbsExpr:
+		return ast.NewTypedAbsExpr(s.Apply(e.Type()), e.Span(), e.Param, s.Apply(e.ParamType), s.ApplyExpr(e.Body))
+	case *ast.TypedAppExpr:
+		return ast.NewTypedAppExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Func), s.ApplyExpr(e.Arg))
+	case *ast.TypedIfExpr:
+		return ast.NewTypedIfExpr(e.Span(), s.ApplyExpr(e.Cond), s.ApplyExpr(e.Then), s.ApplyExpr(e.Else))
+	case *ast.TypedLetExpr:
+		return ast.NewTypedLetExpr(e.Span(), e.Name, s.ApplyExpr(e.Value), s.ApplyExpr(e.Body))
+	case *ast.TypedFixExpr:
+		return ast.NewTypedFixExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Func))
+	case *ast.TypedTupleExpr:
+		return ast.NewTypedTupleExpr(s.Apply(e.Type()), e.Span(), s.applyExprs(e.Elems))
+	case *ast.TypedProjExpr:
+		return ast.NewTypedProjExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Tuple), e.Index)
+	case *ast.TypedInjExpr:
+		return ast.NewTypedInjExpr(s.Apply(e.Type()), e.Span(), e.Left, s.ApplyExpr(e.Value))
+	case *ast.TypedCaseExpr:
+		return ast.NewTypedCaseExpr(e.Span(), s.ApplyExpr(e.Scrutinee), e.LeftVar, s.ApplyExpr(e.Left), e.RightVar, s.ApplyExpr(e.Right))
+	case *ast.TypedNilExpr:
+		return ast.NewTypedNilExpr(s.Apply(e.Type()), e.Span())
+	case *ast.TypedListExpr:
+		return ast.NewTypedListExpr(s.Apply(e.Type()), e.Span(), s.applyExprs(e.Elems))
+	case *ast.TypedListCaseExpr:
+		return ast.NewTypedListCaseExpr(e.Span(), s.ApplyExpr(e.Scrutinee), s.ApplyExpr(e.Nil), e.HeadVar, e.TailVar, s.ApplyExpr(e.Cons))
+	case *ast.TypedRecordExpr:
+		fields := make([]ast.TypedRecordField, len(e.Fields))
+		for i, field := range e.Fields {
+			fields[i] = ast.TypedRecordField{Label: field.Label, Value: s.ApplyExpr(field.Value)}
+		}
+		return ast.NewTypedRecordExpr(s.Apply(e.Type()), e.Span(), fields)
+	case *ast.TypedRecordProjExpr:
+		return ast.NewTypedRecordProjExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Record), e.Label)
+	case *ast.TypedVariantExpr:
+		return ast.NewTypedVariantExpr(s.Apply(e.Type()), e.Span(), e.Label, s.ApplyExpr(e.Value))
+	case *ast.TypedVariantCaseExpr:
+		branches := make([]ast.TypedVariantBranch, len(e.Branches))
+		for i, branch := range e.Branches {
+			branches[i] = branch
+			branches[i].Body = s.ApplyExpr(branch.Body)
+		}
+		return ast.NewTypedVariantCaseExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Scrutinee), branches)
+	case *ast.TypedMatchExpr:
+		arms := make([]ast.TypedMatchArm, len(e.Arms))
+		for i, arm := range e.Arms {
+			arms[i] = arm
+			arms[i].Body = s.ApplyExpr(arm.Body)
+		}
+		return ast.NewTypedMatchExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Scrutinee), arms)
+	case *ast.TypedTyAbsExpr:
+		return ast.NewTypedTyAbsExpr(s.Apply(e.Type()), e.Span(), e.TypeVar, s.ApplyExpr(e.Body))
+	case *ast.TypedTyAppExpr:
+		return ast.NewTypedTyAppExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Func), s.Apply(e.TypeArg))
+	case *ast.TypedFoldExpr:
+		return ast.NewTypedFoldExpr(s.Apply(e.Type()), e.Span(), s.ApplyExpr(e.Value))
+	case *ast.TypedUnfoldExpr:
+		return ast.NewTypedUnfoldExpr(s.Apply(e.Type()), e.Span(), s.Apply(e.RecType), s.ApplyExpr(e.Value))
+	default:
+		// Literals have no type variables
+		return expr
+	}
+}
+
+func (s Subst) applyExprs(exprs []ast.TypedExpr) []ast.TypedExpr {
+	applied := make([]ast.TypedExpr, len(exprs))
+	for i, expr := range exprs {
+		applied[i] = s.ApplyExpr(expr)
+	}
+	return applied
+}
@@ -0,0 +1,254 @@
+package types
+
+import (
+	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/token"
+)
+
+// unifyError describes the innermost pair of types that failed to unify.
+type unifyError struct {
+	left  ast.Type
+	right ast.Type
+
+	// occurs reports whether left is a type variable occurring in right.
+	occurs bool
+}
+
+// unify solves type variables in the substitution so that t1 and t2 become equal.
+func (c *checker) unify(t1, t2 ast.Type) *unifyError {
+	t1, t2 = c.subst.resolve(t1), c.subst.resolve(t2)
+
+	// An error type has already been reported and is compatible with any type
+	if _, ok := t1.(*ast.ErrorType); ok {
+		return nil
+	}
+	if _, ok := t2.(*ast.ErrorType); ok {
+		return nil
+	}
+
+	if m, ok := t1.(*ast.MetaVar); ok {
+		return c.bindVar(m, t2)
+	}
+	if m, ok := t2.(*ast.MetaVar); ok {
+		return c.bindVar(m, t1)
+	}
+
+	switch a := t1.(type) {
+	case *ast.FuncType:
+		if b, ok := t2.(*ast.FuncType); ok {
+			if err := c.unify(a.From, b.From); err != nil {
+				return err
+			}
+			return c.unify(a.To, b.To)
+		}
+	case *ast.ProductType:
+		if b, ok := t2.(*ast.ProductType); ok && len(a.Elems) == len(b.Elems) {
+			for i := range a.Elems {
+				if err := c.unify(a.Elems[i], b.Elems[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case *ast.SumType:
+		if b, ok := t2.(*ast.SumType); ok {
+			if err := c.unify(a.Left, b.Left); err != nil {
+				return err
+			}
+			return c.unify(a.Right, b.Right)
+		}
+	case *ast.ListType:
+		if b, ok := t2.(*ast.ListType); ok {
+			return c.unify(a.Elem, b.Elem)
+		}
+	case *ast.RecordType:
+		if b, ok := t2.(*ast.RecordType); ok && sameLabels(a.Fields, b.Fields) {
+			return c.unifyFields(a.Fields, b.Fields)
+		}
+	case *ast.VariantType:
+		if b, ok := t2.(*ast.VariantType); ok && sameLabels(a.Fields, b.Fields) {
+			return c.unifyFields(a.Fields, b.Fields)
+		}
+	case *ast.ForallType:
+		if b, ok := t2.(*ast.ForallType); ok {
+			return c.unifyBound(a.Var, a.Body, b.Var, b.Body)
+		}
+	case *ast.RecType:
+		// Recursive types are iso-recursive: they are equal to each other but not to their unfoldings
+		if b, ok := t2.(*ast.RecType); ok {
+			return c.unifyBound(a.Var, a.Body, b.Var, b.Body)
+		}
+	default:
+		if t1.Equal(t2) {
+			return nil
+		}
+	}
+	return &unifyError{left: t1, right: t2}
+}
+
+// unifyBound unifies the bodies of two quantified or recursive types under a common name for their bound variables.
+func (c *checker) unifyBound(var1 string, body1 ast.Type, var2 string, body2 ast.Type) *unifyError {
+	if var1 == var2 {
+		return c.unify(body1, body2)
+	}
+	body1, body2 = c.subst.Apply(body1), c.subst.Apply(body2)
+	v := &ast.TypeVar{Name: ast.FreshTypeVar(var1, body1, body2)}
+	return c.unify(
+		replaceTypeVars(body1, map[string]ast.Type{var1: v}),
+		replaceTypeVars(body2, map[string]ast.Type{var2: v}),
+	)
+}
+
+func (c *checker) unifyFields(a, b []ast.Field) *unifyError {
+	for _, field := range a {
+		for _, other := range b {
+			if field.Label == other.Label {
+				if err := c.unify(field.Type, other.Type); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *checker) bindVar(m *ast.MetaVar, t ast.Type) *unifyError {
+	if u, ok := t.(*ast.MetaVar); ok && u.ID == m.ID {
+		return nil
+	}
+	if c.occurs(m.ID, t) {
+		return &unifyError{left: m, right: t, occurs: true}
+	}
+	c.adjustLevels(t, c.levels[m.ID])
+	c.subst[m.ID] = t
+	return nil
+}
+
+// adjustLevels lowers the level of the type variables in t to at most level,
+// since they become reachable wherever a type variable of that level is.
+func (c *checker) adjustLevels(t ast.Type, level int) {
+	for _, m := range metaVars(c.subst.Apply(t), nil) {
+		if c.levels[m.ID] > level {
+			c.levels[m.ID] = level
+		}
+	}
+}
+
+// occurs reports whether the type variable id occurs in t.
+func (c *checker) occurs(id int, t ast.Type) bool {
+	switch t := c.subst.resolve(t).(type) {
+	case *ast.MetaVar:
+		return t.ID == id
+	case *ast.FuncType:
+		return c.occurs(id, t.From) || c.occurs(id, t.To)
+	case *ast.ProductType:
+		for _, elem := range t.Elems {
+			if c.occurs(id, elem) {
+				return true
+			}
+		}
+		return false
+	case *ast.SumType:
+		return c.occurs(id, t.Left) || c.occurs(id, t.Right)
+	case *ast.ListType:
+		return c.occurs(id, t.Elem)
+	case *ast.ForallType:
+		return c.occurs(id, t.Body)
+	case *ast.RecType:
+		return c.occurs(id, t.Body)
+	case *ast.RecordType:
+		return c.occursInFields(id, t.Fields)
+	case *ast.VariantType:
+		return c.occursInFields(id, t.Fields)
+	default:
+		return false
+	}
+}
+
+func (c *checker) occursInFields(id int, fields []ast.Field) bool {
+	for _, field := range fields {
+		if c.occurs(id, field.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameLabels(a, b []ast.Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, field := range a {
+		found := false
+		for _, other := range b {
+			if field.Label == other.Label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// expectation describes where a type is expected, for reporting a mismatch.
+type expectation struct {
+	// context names the construct expecting the type, such as "application".
+	context string
+	// span is the construct the mismatch is reported at.
+	span token.Span
+	// origin is the part of the construct that determines the expected type, if any.
+	origin token.Span
+}
+
+// at returns the expectation reported at span instead.
+func (e expectation) at(span token.Span) expectation {
+	e.span = span
+	return e
+}
+
+// expect unifies the expected type with the actual type of the expression at span
+// and reports a failure as a structured type error.
+func (c *checker) expect(exp expectation, span token.Span, expected, actual ast.Type) error {
+	uerr := c.unify(expected, actual)
+	if uerr == nil {
+		return nil
+	}
+
+	expected, actual = c.subst.Apply(expected), c.subst.Apply(actual)
+	left, right := c.subst.Apply(uerr.left), c.subst.Apply(uerr.right)
+
+	if uerr.occurs {
+		return &InfiniteTypeError{
+			Pos:     exp.span.Start,
+			End:     exp.span.End,
+			Var:     uerr.left.(*ast.MetaVar),
+			Type:    right,
+			Context: exp.context,
+		}
+	}
+	if left.Equal(expected) && right.Equal(actual) {
+		return &TypeMismatchError{
+			Pos:          exp.span.Start,
+			End:          exp.span.End,
+			Expected:     expected,
+			Actual:       actual,
+			Context:      exp.context,
+			ExpectedSpan: exp.origin,
+			ActualSpan:   span,
+		}
+	}
+	return &UnificationError{
+		Pos:          exp.span.Start,
+		End:          exp.span.End,
+		Expected:     expected,
+		Actual:       actual,
+		Left:         left,
+		Right:        right,
+		Context:      exp.context,
+		ExpectedSpan: exp.origin,
+		ActualSpan:   span,
+	}
+}
@@ -2,8 +2,11 @@ package values
 
 import (
 	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/shota3506/gostlc/internal/ast"
+	"github.com/shota3506/gostlc/internal/token"
 )
 
 type Value interface {
@@ -21,6 +24,17 @@ func (v *IntValue) String() string {
 	return fmt.Sprintf("%d", v.Value)
 }
 
+// BigIntValue is an arbitrary-precision integer, which integers are instead of IntValue
+// when evaluated with arbitrary precision. Its Value is never modified.
+type BigIntValue struct {
+	Value *big.Int
+}
+
+func (v *BigIntValue) value() {}
+func (v *BigIntValue) String() string {
+	return v.Value.String()
+}
+
 type BoolValue struct {
 	Value bool
 }
@@ -33,6 +47,196 @@ func (v *BoolValue) String() string {
 	return "false"
 }
 
+// UnitValue is the only value of the unit type.
+type UnitValue struct{}
+
+func (v *UnitValue) value() {}
+func (v *UnitValue) String() string {
+	return "()"
+}
+
+type StringValue struct {
+	Value string
+}
+
+func (v *StringValue) value() {}
+func (v *StringValue) String() string {
+	return token.Quote(v.Value)
+}
+
+type CharValue struct {
+	Value rune
+}
+
+func (v *CharValue) value() {}
+func (v *CharValue) String() string {
+	return token.QuoteChar(v.Value)
+}
+
+type FloatValue struct {
+	Value float64
+}
+
+func (v *FloatValue) value() {}
+func (v *FloatValue) String() string {
+	return ast.FormatFloat(v.Value)
+}
+
+type TupleValue struct {
+	Elems []Value
+}
+
+func (v *TupleValue) value() {}
+func (v *TupleValue) String() string {
+	elems := make([]string, len(v.Elems))
+	for i, elem := range v.Elems {
+		elems[i] = elem.String()
+	}
+	return fmt.Sprintf("(%s)", strings.Join(elems, ", "))
+}
+
+type SumValue struct {
+	Left  bool
+	Value Value
+}
+
+func (v *SumValue) value() {}
+func (v *SumValue) String() string {
+	if v.Left {
+		return fmt.Sprintf("inl %s", v.Value)
+	}
+	return fmt.Sprintf("inr %s", v.Value)
+}
+
+// ListValue is an immutable linked list: the empty list, whose Tail is nil, or Head followed by Tail.
+// Lists share their tails, so a list is never modified once constructed.
+type ListValue struct {
+	Head Value
+	Tail *ListValue
+}
+
+// EmptyList is the empty list.
+var EmptyList = &ListValue{}
+
+// Cons returns the list of head followed by tail.
+func Cons(head Value, tail *ListValue) *ListValue {
+	return &ListValue{Head: head, Tail: tail}
+}
+
+// NewList returns the list of elems.
+func NewList(elems ...Value) *ListValue {
+	list := EmptyList
+	for i := len(elems) - 1; i >= 0; i-- {
+		list = Cons(elems[i], list)
+	}
+	return list
+}
+
+// Empty reports whether v is the empty list.
+func (v *ListValue) Empty() bool {
+	return v.Tail == nil
+}
+
+// Elems returns the elements of v in order.
+func (v *ListValue) Elems() []Value {
+	var elems []Value
+	for l := v; !l.Empty(); l = l.Tail {
+		elems = append(elems, l.Head)
+	}
+	return elems
+}
+
+func (v *ListValue) value() {}
+func (v *ListValue) String() string {
+	elems := v.Elems()
+	strs := make([]string, len(elems))
+	for i, elem := range elems {
+		strs[i] = elem.String()
+	}
+	return fmt.Sprintf("[%s]", strings.Join(strs, ", "))
+}
+
+type RecordValue struct {
+	Fields []RecordField
+}
+
+type RecordField struct {
+	Label string
+	Value Value
+}
+
+func (v *RecordValue) value() {}
+func (v *RecordValue) String() string {
+	fields := make([]string, len(v.Fields))
+	for i, field := range v.Fields {
+		fields[i] = fmt.Sprintf("%s = %s", field.Label, field.Value)
+	}
+	return fmt.Sprintf("{%s}", strings.Join(fields, ", "))
+}
+
+// Field returns the value of the field with the given label.
+func (v *RecordValue) Field(label string) (Value, bool) {
+	for _, field := range v.Fields {
+		if field.Label == label {
+			return field.Value, true
+		}
+	}
+	return nil, false
+}
+
+type VariantValue struct {
+	Label string
+	Value Value
+}
+
+func (v *VariantValue) value() {}
+func (v *VariantValue) String() string {
+	return fmt.Sprintf("<%s = %s>", v.Label, v.Value)
+}
+
+// DataValue is a value of an algebraic data type built by the constructor named Constructor from Args.
+type DataValue struct {
+	Constructor string
+	Args        []Value
+}
+
+func (v *DataValue) value() {}
+func (v *DataValue) String() string {
+	var b strings.Builder
+	b.WriteString(v.Constructor)
+	for _, arg := range v.Args {
+		if data, ok := arg.(*DataValue); ok && len(data.Args) > 0 {
+			fmt.Fprintf(&b, " (%s)", arg)
+		} else {
+			fmt.Fprintf(&b, " %s", arg)
+		}
+	}
+	return b.String()
+}
+
+// ConstructorFunc is a constructor of a data type taking Arity arguments, applied to Args so far.
+// It builds a DataValue once applied to all of its arguments.
+type ConstructorFunc struct {
+	Name  string
+	Arity int
+	Args  []Value
+}
+
+func (f *ConstructorFunc) value() {}
+func (f *ConstructorFunc) String() string {
+	return fmt.Sprintf("<constructor:%s>", f.Name)
+}
+
+// FoldValue is a value of a recursive type, wrapping the value of its unfolding that it was folded from.
+type FoldValue struct {
+	Value Value
+}
+
+func (v *FoldValue) value() {}
+func (v *FoldValue) String() string {
+	return fmt.Sprintf("fold %s", v.Value)
+}
+
 type Closure struct {
 	Param     string
 	ParamType ast.Type
@@ -50,6 +254,8 @@ type BuiltinFunc struct {
 	ParamType  ast.Type
 	ReturnType ast.Type
 	Fn         func(args Value) (Value, error)
+	// Lazy reports that the argument is passed to Fn unevaluated as a *Thunk.
+	Lazy bool
 }
 
 func (b *BuiltinFunc) value() {}
@@ -62,9 +268,55 @@ type PartialBuiltinFunc struct {
 	ParamType  ast.Type
 	ReturnType ast.Type
 	Fn         func(args Value) (Value, error)
+	// Lazy reports that the argument is passed to Fn unevaluated as a *Thunk.
+	Lazy bool
 }
 
 func (p *PartialBuiltinFunc) value() {}
 func (p *PartialBuiltinFunc) String() string {
 	return fmt.Sprintf("<builtin:%s[partial]:%s->%s>", p.Name, p.ParamType, p.ReturnType)
 }
+
+// FixValue represents the fixed point of a function.
+// It is unfolded lazily each time the recursive reference is used.
+type FixValue struct {
+	Fn Value
+}
+
+func (f *FixValue) value() {}
+func (f *FixValue) String() string {
+	return fmt.Sprintf("<fix:%s>", f.Fn)
+}
+
+// Call is the application of a function to arguments, which a builtin function taking functions
+// returns for the evaluator to perform. Args are applied in order, and an argument that is itself
+// a Call is performed first.
+type Call struct {
+	Fn   Value
+	Args []Value
+}
+
+func (c *Call) value() {}
+func (c *Call) String() string {
+	return fmt.Sprintf("<call:%s>", c.Fn)
+}
+
+// Thunk is an argument passed unevaluated to the lazy parameter of a builtin function,
+// which evaluates it only if its value is needed.
+type Thunk struct {
+	Eval func() (Value, error)
+}
+
+func (t *Thunk) value() {}
+func (t *Thunk) String() string {
+	return "<thunk>"
+}
+
+// Force returns the value of v, evaluating it if it is a thunk.
+// A lazy parameter may also be passed an evaluated value, which is returned as is.
+func Force(v Value) (Value, error) {
+	if t, ok := v.(*Thunk); ok {
+		return t.Eval()
+	}
+	return v, nil
+}